@@ -39,13 +39,22 @@ var (
 			{"Register", Register},
 			{"Login", Login},
 			{"ResetPassword", ResetPassword},
+			{"Logout", Logout},
+			{"LogoutAllSessions", LogoutAllSessions},
+			{"ListMySessions", ListMySessions},
 			{"FindUser", FindUser},
 			{"FindUserByID", FindUserByID},
 
 			// workspace
+			{"CreateWorkspace", CreateWorkspace},
+			{"MyWorkspaceList", MyWorkspaceList},
+			{"UpdateWorkspace", UpdateWorkspace},
+			{"WorkspaceSettings", WorkspaceSettings},
+			{"UpdateWorkspaceSettings", UpdateWorkspaceSettings},
 			{"WorkspaceBaseInfo", WorkspaceBaseInfo},
 			{"WorkspaceJoinApply", WorkspaceJoinApply},
 			{"WorkspaceApproval", WorkspaceApproval},
+			{"WorkspaceBatchApproval", WorkspaceBatchApproval},
 			{"WorkspaceMemberInvite", WorkspaceMemberInvite},
 			{"WorkspaceMemberRemove", WorkspaceMemberRemove},
 			{"WorkspaceMemberList", WorkspaceMemberList},
@@ -56,6 +65,28 @@ var (
 			{"CollectTableList", CollectTableList},
 			{"CollectTable", CollectTable},
 
+			// search
+			{"Search", Search},
+			{"RebuildSearchIndex", RebuildSearchIndex},
+
+			// outbox
+			{"OutboxStats", OutboxStats},
+			{"OutboxRequeue", OutboxRequeue},
+
+			// notify
+			{"GetNotifySetting", GetNotifySetting},
+			{"UpdateNotifySetting", UpdateNotifySetting},
+			{"NotificationList", NotificationList},
+			{"NotificationMarkRead", NotificationMarkRead},
+			{"NotificationUnreadCount", NotificationUnreadCount},
+
+			// approval
+			{"MyPendingApprovals", MyPendingApprovals},
+
+			// audit
+			{"AuditLogList", AuditLogList},
+			{"EntityHistory", EntityHistory},
+
 			// product
 			{"AddProduct", AddProduct},
 			{"UpdateProduct", UpdateProduct},
@@ -66,6 +97,7 @@ var (
 			{"ProductMemberList", ProductMemberList},
 			{"ProductJoinApply", ProductJoinApply},
 			{"ProductApproval", ProductApproval},
+			{"ProductBatchApproval", ProductBatchApproval},
 			{"ProductChangeRoleApply", ProductChangeRoleApply},
 			{"ProductChangeRoleApproval", ProductChangeRoleApproval},
 			{"ProductMemberRemove", ProductMemberRemove},
@@ -92,6 +124,8 @@ var (
 			{"UpdateTablePlugin", UpdateTablePlugin},
 			{"DelTablePlugin", DelTablePlugin},
 			{"TablePlugins", TablePlugins},
+			{"ReorderTablePlugins", ReorderTablePlugins},
+			{"RepairPluginChain", RepairPluginChain},
 
 			// plugin
 			{"AddPlugin", AddPlugin},
@@ -100,6 +134,11 @@ var (
 			{"DelPluginConfig", DelPluginConfig},
 			{"PluginList", PluginList},
 			{"PluginConfigs", PluginConfigs},
+			{"PublishPluginVersion", PublishPluginVersion},
+			{"DeprecatePluginVersion", DeprecatePluginVersion},
+			{"PluginVersionList", PluginVersionList},
+			{"PluginUsage", PluginUsage},
+			{"UpgradePluginVersion", UpgradePluginVersion},
 
 			// app
 			{"AddApp", AddApp},
@@ -115,6 +154,7 @@ var (
 			{"AppCanAccessDB", AppCanAccessDB},
 			{"AppApplyAccessDB", AppApplyAccessDB},
 			{"AppAccessDBApproval", AppAccessDBApproval},
+			{"AppAccessDBBatchApproval", AppAccessDBBatchApproval},
 			{"AppAccessDBWithdraw", AppAccessDBWithdraw},
 			{"AppAccessDBUpdate", AppAccessDBUpdate},
 			{"AppAccessDBOnOff", AppAccessDBOnOff},
@@ -126,6 +166,7 @@ var (
 			{"AppCanAccessTable", AppCanAccessTable},
 			{"AppApplyAccessTable", AppApplyAccessTable},
 			{"AppAccessTableApproval", AppAccessTableApproval},
+			{"AppAccessTableBatchApproval", AppAccessTableBatchApproval},
 			{"AppAccessTableWithdraw", AppAccessTableWithdraw},
 			{"AppAccessTableUpdate", AppAccessTableUpdate},
 			{"AppAccessTableOnOff", AppAccessTableOnOff},
@@ -136,32 +177,11 @@ var (
 )
 
 func DecodeAndAuth(ctx context.Context, header *head.WebReqHeader, reqBuf []byte, req interface{}) (err error) {
-	if req != nil {
-		err = json.Api.Unmarshal(reqBuf, req)
-		if err != nil {
-			return errs.Newf(errs.ErrServerDecode,
-				"decode request error: %v, request:[%s]", err, types.QuickReplaceLFCR2Space(reqBuf))
-		}
-	}
-
-	if header.Userid == 0 {
-		return errs.New(errs.RetWebNotLogin, "please login first")
-	}
-
-	notFind, user, err := table.GetUserByID(ctx, header.Userid)
+	_, err = DecodeAndVerifySession(ctx, header, reqBuf, req)
 	if err != nil {
 		return err
 	}
 
-	if notFind {
-		return errs.New(errs.RetWebNotFindUser, "not find user")
-
-	}
-
-	if !auth.SignSuccess(header, user.Token) {
-		//return errs.Newf(errs.ErrAuthFail, "signature failed")
-	}
-
 	if header.WorkspaceId == 0 {
 		return errs.Newf(errs.RetWebParamEmpty, "workspace_id can`t be empty")
 	}
@@ -173,3 +193,17 @@ func DecodeAndAuth(ctx context.Context, header *head.WebReqHeader, reqBuf []byte
 
 	return nil
 }
+
+// DecodeAndVerifySession 解析请求并校验登录会话，不校验空间成员身份
+func DecodeAndVerifySession(ctx context.Context, header *head.WebReqHeader,
+	reqBuf []byte, req interface{}) (*table.TblUserSession, error) {
+	if req != nil {
+		err := json.Api.Unmarshal(reqBuf, req)
+		if err != nil {
+			return nil, errs.Newf(errs.ErrServerDecode,
+				"decode request error: %v, request:[%s]", err, types.QuickReplaceLFCR2Space(reqBuf))
+		}
+	}
+
+	return auth.VerifySession(ctx, header)
+}
@@ -36,7 +36,7 @@ func AddApp(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interf
 		return nil, errs.Newf(errs.RetWebParamEmpty, "app name can`t be empty")
 	}
 
-	return logic.AddApp(ctx, head.Userid, &req)
+	return logic.AddApp(ctx, head.Userid, int(head.WorkspaceId), &req)
 }
 
 // UpdateApp 应用基础信息更新
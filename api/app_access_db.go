@@ -71,6 +71,10 @@ func AppApplyAccessDB(ctx context.Context, head *head.WebReqHeader, reqBuf []byt
 		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [root] is invalid")
 	}
 
+	if req.ExpireType > consts.ExpireTypeYear || req.ExpireType < consts.ExpireTypePermanent {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [expire_type] is invalid")
+	}
+
 	return logic.AppApplyAccessDB(ctx, head.Userid, &req)
 }
 
@@ -93,6 +97,35 @@ func AppAccessDBApproval(ctx context.Context, head *head.WebReqHeader, reqBuf []
 	return nil, logic.AppAccessDBApproval(ctx, head.Userid, &req)
 }
 
+// AppAccessDBBatchApproval 应用接入仓库批量审批
+func AppAccessDBBatchApproval(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.AppAccessDBBatchApprovalRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Items) == 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "items can`t be empty")
+	}
+
+	if len(req.Items) > consts.BatchApprovalMaxItems {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "items can`t exceed %d", consts.BatchApprovalMaxItems)
+	}
+
+	for _, item := range req.Items {
+		if item == nil || item.Appid == 0 || item.DbID == 0 {
+			return nil, errs.Newf(errs.RetWebParamEmpty, "appid/db_id can`t be empty")
+		}
+	}
+
+	if req.Status != consts.ApprovalAccess && req.Status != consts.ApprovalReject {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [status] is invalid")
+	}
+
+	return logic.AppAccessDBBatchApproval(ctx, head.Userid, &req), nil
+}
+
 // AppAccessDBWithdraw 应用接入仓库撤销申请
 func AppAccessDBWithdraw(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
 	req := pb.AppAccessDBWithdrawRequest{}
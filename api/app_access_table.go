@@ -71,6 +71,10 @@ func AppApplyAccessTable(ctx context.Context, head *head.WebReqHeader, reqBuf []
 		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [query_all] is invalid")
 	}
 
+	if req.ExpireType > consts.ExpireTypeYear || req.ExpireType < consts.ExpireTypePermanent {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [expire_type] is invalid")
+	}
+
 	return logic.AppApplyAccessTable(ctx, head.Userid, &req)
 }
 
@@ -93,6 +97,35 @@ func AppAccessTableApproval(ctx context.Context, head *head.WebReqHeader, reqBuf
 	return nil, logic.AppAccessTableApproval(ctx, head.Userid, &req)
 }
 
+// AppAccessTableBatchApproval 应用接入表数据批量审批
+func AppAccessTableBatchApproval(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.AppAccessTableBatchApprovalRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Items) == 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "items can`t be empty")
+	}
+
+	if len(req.Items) > consts.BatchApprovalMaxItems {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "items can`t exceed %d", consts.BatchApprovalMaxItems)
+	}
+
+	for _, item := range req.Items {
+		if item == nil || item.Appid == 0 || item.TableID == 0 {
+			return nil, errs.Newf(errs.RetWebParamEmpty, "appid/table_id can`t be empty")
+		}
+	}
+
+	if req.Status != consts.ApprovalAccess && req.Status != consts.ApprovalReject {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [status] is invalid")
+	}
+
+	return logic.AppAccessTableBatchApproval(ctx, head.Userid, &req), nil
+}
+
 // AppAccessTableWithdraw 应用接入表数据撤销申请
 func AppAccessTableWithdraw(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
 	req := pb.AppAccessTableWithdrawRequest{}
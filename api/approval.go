@@ -0,0 +1,61 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"math"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/logic"
+	"github.com/horm-database/manage/srv/transport/web/head"
+)
+
+// MyPendingApprovals 我可以审批的待审批申请
+func MyPendingApprovals(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.MyPendingApprovalsRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Sort == "" {
+		req.Sort = consts.ApprovalSortOldest
+	}
+
+	if req.Sort != consts.ApprovalSortOldest && req.Sort != consts.ApprovalSortNewest {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [sort] is invalid")
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Size <= 0 {
+		req.Size = 20
+	}
+
+	if req.Size > 100 {
+		req.Size = 100
+	}
+
+	if req.Page > math.MaxInt32/req.Size {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [page] is too large")
+	}
+
+	return logic.MyPendingApprovals(ctx, head.Userid, int(head.WorkspaceId), &req)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/logic"
+	"github.com/horm-database/manage/srv/transport/web/head"
+)
+
+// AuditLogList 审计日志列表（空间管理员），可按操作人、接口、对象、时间过滤
+func AuditLogList(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.AuditLogListRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Size == 0 {
+		req.Size = 20
+	}
+
+	return logic.AuditLogList(ctx, head.Userid, int(head.WorkspaceId), &req)
+}
+
+// EntityHistory 对象变更历史（空间管理员）
+func EntityHistory(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.EntityHistoryRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.EntityType == "" {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "entity type can`t be empty")
+	}
+
+	if req.EntityID == "" {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "entity id can`t be empty")
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Size == 0 {
+		req.Size = 20
+	}
+
+	return logic.EntityHistory(ctx, head.Userid, int(head.WorkspaceId), &req)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/logic"
+	"github.com/horm-database/manage/srv/transport/web/head"
+)
+
+// NotificationList 我的站内信列表
+func NotificationList(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.NotificationListRequest{}
+	_, err := DecodeAndVerifySession(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Size == 0 {
+		req.Size = 20
+	}
+
+	return logic.NotificationList(ctx, head.Userid, &req)
+}
+
+// NotificationMarkRead 站内信标为已读
+func NotificationMarkRead(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.NotificationMarkReadRequest{}
+	_, err := DecodeAndVerifySession(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.All && len(req.Ids) == 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "ids can`t be empty")
+	}
+
+	return nil, logic.NotificationMarkRead(ctx, head.Userid, &req)
+}
+
+// NotificationUnreadCount 未读站内信数
+func NotificationUnreadCount(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	_, err := DecodeAndVerifySession(ctx, head, reqBuf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return logic.NotificationUnreadCount(ctx, head.Userid)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/logic"
+	"github.com/horm-database/manage/model/mail"
+	"github.com/horm-database/manage/srv/transport/web/head"
+	"github.com/horm-database/manage/util"
+)
+
+// GetNotifySetting 获取我的通知设置
+func GetNotifySetting(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	_, err := DecodeAndVerifySession(ctx, head, reqBuf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return logic.GetNotifySetting(ctx, head.Userid)
+}
+
+// UpdateNotifySetting 修改我的通知设置
+func UpdateNotifySetting(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.NotifySetting{}
+	_, err := DecodeAndVerifySession(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.WebhookURL != "" {
+		err = util.CheckPublicURL(ctx, req.WebhookURL)
+		if err != nil {
+			return nil, errs.Newf(errs.RetWebParamEmpty, "webhook_url is invalid: %v", err)
+		}
+	}
+
+	if req.Locale != "" && !mail.ValidLocale(req.Locale) {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [locale] is invalid")
+	}
+
+	for _, event := range req.MuteEvents {
+		switch event {
+		case consts.NotifyEventApply, consts.NotifyEventWithdraw, consts.NotifyEventResult,
+			consts.NotifyEventExpiring, consts.NotifyEventExpired:
+		default:
+			return nil, errs.Newf(errs.RetWebParamEmpty, "unknown notify event %s", event)
+		}
+	}
+
+	return nil, logic.UpdateNotifySetting(ctx, head.Userid, &req)
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/logic"
+	"github.com/horm-database/manage/srv/transport/web/head"
+)
+
+// OutboxStats 异步任务队列统计（空间管理员）
+func OutboxStats(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	err := DecodeAndAuth(ctx, head, reqBuf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return logic.OutboxStats(ctx, head.Userid, int(head.WorkspaceId))
+}
+
+// OutboxRequeue 失败任务重新入队（空间管理员）
+func OutboxRequeue(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.OutboxRequeueRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	return logic.OutboxRequeue(ctx, head.Userid, int(head.WorkspaceId), &req)
+}
@@ -47,11 +47,12 @@ type DBAccessInfo struct {
 
 // AppApplyAccessDBRequest 应用申请接入仓库
 type AppApplyAccessDBRequest struct {
-	Appid  uint64   `json:"appid"`  // 应用appid
-	DbID   int      `json:"db_id"`  // 数据库
-	Root   int8     `json:"root"`   // 权限 1-超级权限（所有权限，包含DDL）  2-表数据权限（库下表的所有增删改查权限，不包含 DDL）  3-无
-	Op     []string `json:"op"`     // 支持的操作
-	Reason string   `json:"reason"` // 接入原因
+	Appid      uint64   `json:"appid"`       // 应用appid
+	DbID       int      `json:"db_id"`       // 数据库
+	Root       int8     `json:"root"`        // 权限 1-超级权限（所有权限，包含DDL）  2-表数据权限（库下表的所有增删改查权限，不包含 DDL）  3-无
+	Op         []string `json:"op"`          // 支持的操作
+	Reason     string   `json:"reason"`      // 接入原因
+	ExpireType int8     `json:"expire_type"` // 权限有效期 0-永久 1-一个月 2-三个月 3-半年 4-一年（已接入且 7 天内过期时再次申请为续期）
 }
 
 type AppApplyAccessResponse struct {
@@ -66,6 +67,18 @@ type AppAccessDBApprovalRequest struct {
 	Reason string `json:"reason"` // 拒绝理由（ status=2 时输入）
 }
 
+// AppAccessDBBatchApprovalRequest 应用接入仓库批量审批
+type AppAccessDBBatchApprovalRequest struct {
+	Items  []*AppAccessDBItem `json:"items"`  // 待审批的申请
+	Status int8               `json:"status"` // 1-审批通过 2-审批拒绝
+	Reason string             `json:"reason"` // 拒绝理由（ status=2 时输入）
+}
+
+type AppAccessDBItem struct {
+	Appid uint64 `json:"appid"` // 应用appid
+	DbID  int    `json:"db_id"` // 数据库
+}
+
 // AppAccessDBWithdrawRequest 应用接入仓库撤销申请
 type AppAccessDBWithdrawRequest struct {
 	Appid  uint64 `json:"appid"`  // 应用appid
@@ -122,14 +135,17 @@ type AppsAllDBAccessListResponse struct {
 }
 
 type AppAccessDB struct {
-	Id        int        `json:"id"`
-	App       *AppBase   `json:"app,omitempty"` // 应用信息
-	DB        *DBBase    `json:"db,omitempty"`  // 库信息
-	Root      int8       `json:"root"`          // 超级权限 1-超级权限（所有权限，包含DDL）  2-表数据权限（库下表的所有增删改查权限，不包含 DDL）  3-无
-	Op        []string   `json:"op"`            // 支持的操作
-	Status    int8       `json:"status"`        // 状态：1-正常 2-下线 3-审核中 4-审核撤回 5-拒绝
-	ApplyUser *UsersBase `json:"apply_user"`    // 申请者
-	Reason    string     `json:"reason"`        // 接入原因
-	CreatedAt int64      `json:"create_time"`   // 记录创建时间
-	UpdatedAt int64      `json:"update_time"`   // 最后更新时间
+	Id          int        `json:"id"`
+	App         *AppBase   `json:"app,omitempty"` // 应用信息
+	DB          *DBBase    `json:"db,omitempty"`  // 库信息
+	Root        int8       `json:"root"`          // 超级权限 1-超级权限（所有权限，包含DDL）  2-表数据权限（库下表的所有增删改查权限，不包含 DDL）  3-无
+	Op          []string   `json:"op"`            // 支持的操作
+	Status      int8       `json:"status"`        // 状态：1-正常 2-下线 3-审核中 4-审核撤回 5-拒绝
+	ApplyUser   *UsersBase `json:"apply_user"`    // 申请者
+	Reason      string     `json:"reason"`        // 接入原因
+	ExpireType  int8       `json:"expire_type"`   // 权限有效期 0-永久 1-一个月 2-三个月 3-半年 4-一年
+	ExpireTime  int64      `json:"expire_time"`   // 过期时间，0 为永久
+	RenewStatus int8       `json:"renew_status"`  // 续期状态 0-无 1-续期审批中
+	CreatedAt   int64      `json:"create_time"`   // 记录创建时间
+	UpdatedAt   int64      `json:"update_time"`   // 最后更新时间
 }
@@ -41,11 +41,12 @@ type TableAccessInfo struct {
 
 // AppApplyAccessTableRequest 应用申请接入表数据
 type AppApplyAccessTableRequest struct {
-	Appid    uint64   `json:"appid"`     // 应用appid
-	TableID  int      `json:"table_id"`  // 表ID
-	QueryAll int8     `json:"query_all"` // 是否支持所有的 query 语句，1-true 2-false
-	Op       []string `json:"op"`        // 支持的操作
-	Reason   string   `json:"reason"`    // 接入原因
+	Appid      uint64   `json:"appid"`       // 应用appid
+	TableID    int      `json:"table_id"`    // 表ID
+	QueryAll   int8     `json:"query_all"`   // 是否支持所有的 query 语句，1-true 2-false
+	Op         []string `json:"op"`          // 支持的操作
+	Reason     string   `json:"reason"`      // 接入原因
+	ExpireType int8     `json:"expire_type"` // 权限有效期 0-永久 1-一个月 2-三个月 3-半年 4-一年（已接入且 7 天内过期时再次申请为续期）
 }
 
 // AppAccessTableApprovalRequest 应用接入表数据审批
@@ -56,6 +57,18 @@ type AppAccessTableApprovalRequest struct {
 	Reason  string `json:"reason"`   // 拒绝理由（ status=2 时输入）
 }
 
+// AppAccessTableBatchApprovalRequest 应用接入表数据批量审批
+type AppAccessTableBatchApprovalRequest struct {
+	Items  []*AppAccessTableItem `json:"items"`  // 待审批的申请
+	Status int8                  `json:"status"` // 1-审批通过 2-审批拒绝
+	Reason string                `json:"reason"` // 拒绝理由（ status=2 时输入）
+}
+
+type AppAccessTableItem struct {
+	Appid   uint64 `json:"appid"`    // 应用appid
+	TableID int    `json:"table_id"` // 表ID
+}
+
 // AppAccessTableWithdrawRequest 应用接入表数据撤销申请
 type AppAccessTableWithdrawRequest struct {
 	Appid   uint64 `json:"appid"`    // 应用appid
@@ -112,14 +125,17 @@ type AppsAllTableAccessListResponse struct {
 }
 
 type AppAccessTable struct {
-	Id        int        `json:"id"`
-	App       *AppBase   `json:"app,omitempty"`   // 应用信息
-	Table     *TableBase `json:"table,omitempty"` // 表信息
-	QueryAll  int8       `json:"query_all"`       // 是否支持所有的 query 语句，1-true 2-false
-	Op        []string   `json:"op"`              // 支持的操作
-	Status    int8       `json:"status"`          // 状态：1-正常 2-下线 3-审核中 4-审核撤回 5-拒绝
-	ApplyUser *UsersBase `json:"apply_user"`      // 申请者
-	Reason    string     `json:"reason"`          // 接入原因
-	CreatedAt int64      `json:"create_time"`     // 记录创建时间
-	UpdatedAt int64      `json:"update_time"`     // 最后更新时间
+	Id          int        `json:"id"`
+	App         *AppBase   `json:"app,omitempty"`   // 应用信息
+	Table       *TableBase `json:"table,omitempty"` // 表信息
+	QueryAll    int8       `json:"query_all"`       // 是否支持所有的 query 语句，1-true 2-false
+	Op          []string   `json:"op"`              // 支持的操作
+	Status      int8       `json:"status"`          // 状态：1-正常 2-下线 3-审核中 4-审核撤回 5-拒绝
+	ApplyUser   *UsersBase `json:"apply_user"`      // 申请者
+	Reason      string     `json:"reason"`          // 接入原因
+	ExpireType  int8       `json:"expire_type"`     // 权限有效期 0-永久 1-一个月 2-三个月 3-半年 4-一年
+	ExpireTime  int64      `json:"expire_time"`     // 过期时间，0 为永久
+	RenewStatus int8       `json:"renew_status"`    // 续期状态 0-无 1-续期审批中
+	CreatedAt   int64      `json:"create_time"`     // 记录创建时间
+	UpdatedAt   int64      `json:"update_time"`     // 最后更新时间
 }
@@ -0,0 +1,66 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pb
+
+type MyPendingApprovalsRequest struct {
+	Kind string `json:"kind"` // 申请类型 workspace_join、workspace_renewal、product_join、product_renewal、product_change_role、access_db、access_table、access_db_renewal、access_table_renewal，为空不过滤
+	Sort string `json:"sort"` // 排序 oldest-最早申请的在前（默认） newest-最新申请的在前
+	Page int    `json:"page"` // 分页
+	Size int    `json:"size"` // 每页大小
+}
+
+type MyPendingApprovalsResponse struct {
+	Total     uint64             `json:"total"`      // 总数
+	TotalPage uint32             `json:"total_page"` // 总页数
+	Page      int                `json:"page"`       // 分页
+	Size      int                `json:"size"`       // 每页大小
+	Approvals []*PendingApproval `json:"approvals"`  // 待审批列表
+}
+
+// PendingApproval 待审批申请
+type PendingApproval struct {
+	Kind        string       `json:"kind"`         // 申请类型
+	Id          int          `json:"id"`           // 空间/产品成员记录id，或应用接入仓库/表数据的权限记录id
+	Applicant   *UsersBase   `json:"applicant"`    // 申请人
+	ApplyTime   int64        `json:"apply_time"`   // 申请时间
+	Reason      string       `json:"reason"`       // 申请理由
+	WorkspaceID int          `json:"workspace_id"` // 空间id（空间申请）
+	Product     *ProductBase `json:"product"`      // 产品（产品申请）
+	Role        int8         `json:"role"`         // 申请的产品角色 2-开发者 3-运营者（产品申请）
+	ExpireType  int8         `json:"expire_type"`  // 申请的有效期 0: 永久 1: 一个月 2: 三个月 3: 半年 4: 一年
+	App         *AppBase     `json:"app"`          // 申请接入的应用（接入申请）
+	DB          *DBBase      `json:"db"`           // 仓库（接入申请）
+	Table       *TableBase   `json:"table"`        // 表（接入表数据申请）
+	Root        int8         `json:"root"`         // 仓库权限 1-超级权限 2-表数据权限 3-无（接入仓库申请）
+	QueryAll    int8         `json:"query_all"`    // 是否支持所有的 query 语句 1-true 2-false（接入表数据申请）
+	Op          []string     `json:"op"`           // 申请的操作（接入申请）
+}
+
+// BatchApprovalResponse 批量审批结果，单条审批失败不影响其他申请
+type BatchApprovalResponse struct {
+	Success int                    `json:"success"` // 成功条数
+	Failed  int                    `json:"failed"`  // 失败条数
+	Results []*BatchApprovalResult `json:"results"` // 每条申请的审批结果，与请求顺序一致
+}
+
+type BatchApprovalResult struct {
+	Userid  uint64 `json:"userid,omitempty"`   // 空间/产品成员申请的 userid
+	Appid   uint64 `json:"appid,omitempty"`    // 应用接入申请的 appid
+	DbID    int    `json:"db_id,omitempty"`    // 应用接入仓库申请的数据库
+	TableID int    `json:"table_id,omitempty"` // 应用接入表数据申请的表ID
+	Success bool   `json:"success"`            // 是否审批成功
+	Code    int    `json:"code,omitempty"`     // 失败错误码
+	Msg     string `json:"msg,omitempty"`      // 失败原因
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pb
+
+type AuditLogListRequest struct {
+	Userid     uint64 `json:"userid"`      // 操作人
+	Api        string `json:"api"`         // 接口名
+	EntityType string `json:"entity_type"` // 对象类型，如 product、db、table、app_info、access_table
+	EntityID   string `json:"entity_id"`   // 对象id
+	StartTime  int64  `json:"start_time"`  // 开始时间（秒）
+	EndTime    int64  `json:"end_time"`    // 结束时间（秒）
+	Page       int    `json:"page"`        // 分页
+	Size       int    `json:"size"`        // 每页大小
+}
+
+type EntityHistoryRequest struct {
+	EntityType string `json:"entity_type"` // 对象类型
+	EntityID   string `json:"entity_id"`   // 对象id
+	Page       int    `json:"page"`        // 分页
+	Size       int    `json:"size"`        // 每页大小
+}
+
+type AuditLogListResponse struct {
+	Total     uint64      `json:"total"`      // 总数
+	TotalPage uint32      `json:"total_page"` // 总页数
+	Page      int         `json:"page"`       // 分页
+	Size      int         `json:"size"`       // 每页大小
+	Logs      []*AuditLog `json:"logs"`       // 审计日志
+}
+
+// AuditLog 审计日志
+type AuditLog struct {
+	Id         int                     `json:"id"`          // id
+	Operator   *UsersBase              `json:"operator"`    // 操作人，为空表示系统任务
+	IP         string                  `json:"ip"`          // 操作人 ip
+	RequestID  uint64                  `json:"request_id"`  // 请求id
+	Api        string                  `json:"api"`         // 接口名
+	EntityType string                  `json:"entity_type"` // 对象类型
+	EntityID   string                  `json:"entity_id"`   // 对象id
+	Action     int8                    `json:"action"`      // 1-新增 2-修改 3-删除
+	Diff       map[string]*AuditChange `json:"diff"`        // 变更内容
+	CreatedAt  int64                   `json:"created_at"`  // 操作时间
+}
+
+// AuditChange 字段变更
+type AuditChange struct {
+	Before interface{} `json:"before"` // 修改前
+	After  interface{} `json:"after"`  // 修改后
+}
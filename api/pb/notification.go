@@ -0,0 +1,67 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pb
+
+type NotificationListRequest struct {
+	Status int8   `json:"status"` // 0-全部 1-未读 2-已读
+	Event  string `json:"event"`  // 事件 apply-新的申请 withdraw-申请已撤销 result-审批结果 expiring-权限即将过期 expired-权限已过期，为空不过滤
+	Page   int    `json:"page"`   // 分页
+	Size   int    `json:"size"`   // 每页大小
+}
+
+type NotificationListResponse struct {
+	Total         uint64          `json:"total"`         // 总数
+	TotalPage     uint32          `json:"total_page"`    // 总页数
+	Page          int             `json:"page"`          // 分页
+	Size          int             `json:"size"`          // 每页大小
+	Notifications []*Notification `json:"notifications"` // 通知列表
+}
+
+// Notification 站内信
+type Notification struct {
+	Id        int               `json:"id"`         // 通知id
+	Event     string            `json:"event"`      // 事件 apply-新的申请 withdraw-申请已撤销 result-审批结果 expiring-权限即将过期 expired-权限已过期
+	Kind      string            `json:"kind"`       // 申请类型，如 workspace_join、product_join、access_db、access_table
+	Title     string            `json:"title"`      // 标题
+	Applicant *UsersBase        `json:"applicant"`  // 申请人
+	Operator  *UsersBase        `json:"operator"`   // 审批人（event=result 时）
+	Approved  bool              `json:"approved"`   // 是否审批通过（event=result 时）
+	Reason    string            `json:"reason"`     // 申请、撤销或拒绝理由
+	Link      *NotificationLink `json:"link"`       // 关联的申请
+	Status    int8              `json:"status"`     // 1-未读 2-已读
+	ReadTime  int64             `json:"read_time"`  // 阅读时间
+	CreatedAt int64             `json:"created_at"` // 通知时间
+}
+
+// NotificationLink 通知关联的申请，用于跳转到审批页面
+type NotificationLink struct {
+	WorkspaceID int    `json:"workspace_id,omitempty"` // 空间id
+	ProductID   int    `json:"product_id,omitempty"`   // 产品id
+	Appid       uint64 `json:"appid,omitempty"`        // 应用id
+	DbID        int    `json:"db_id,omitempty"`        // 仓库id
+	TableID     int    `json:"table_id,omitempty"`     // 表id
+	Role        int8   `json:"role,omitempty"`         // 申请的产品角色 2-开发者 3-运营者
+	MemberID    int    `json:"member_id,omitempty"`    // 空间/产品成员记录id（空间、产品申请）
+	AccessID    int    `json:"access_id,omitempty"`    // 权限记录id（应用接入仓库、表数据申请）
+}
+
+type NotificationMarkReadRequest struct {
+	Ids []int `json:"ids"` // 通知id
+	All bool  `json:"all"` // 全部标为已读
+}
+
+type NotificationUnreadCountResponse struct {
+	Count uint64 `json:"count"` // 未读通知数
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pb
+
+// NotifySetting 通知设置
+type NotifySetting struct {
+	Email      bool     `json:"email"`       // 是否接收邮件通知
+	WebhookURL string   `json:"webhook_url"` // 通知推送的 webhook 地址（如企业微信、飞书机器人），为空不推送
+	Locale     string   `json:"locale"`      // 通知语言，如 zh-CN、en-US，为空使用默认语言
+	MuteEvents []string `json:"mute_events"` // 不接收通知的事件 apply-新的申请 withdraw-申请已撤销 result-审批结果 expiring-权限即将过期 expired-权限已过期
+}
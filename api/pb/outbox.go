@@ -0,0 +1,41 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pb
+
+// OutboxStatsResponse 异步任务队列统计
+type OutboxStatsResponse struct {
+	Pending    uint64              `json:"pending"`    // 待处理任务数（包括等待重试的任务）
+	Processing uint64              `json:"processing"` // 处理中任务数
+	Failed     uint64              `json:"failed"`     // 失败任务数（超过最大重试次数）
+	Topics     []*OutboxTopicStats `json:"topics"`     // 当前实例启动以来各主题的处理计数
+}
+
+// OutboxTopicStats 主题处理计数
+type OutboxTopicStats struct {
+	Topic   string `json:"topic"`   // 任务主题
+	Succeed int64  `json:"succeed"` // 处理成功次数
+	Failed  int64  `json:"failed"`  // 处理失败次数
+	Dead    int64  `json:"dead"`    // 超过最大重试次数的任务数
+}
+
+// OutboxRequeueRequest 失败任务重新入队
+type OutboxRequeueRequest struct {
+	Topic string `json:"topic"` // 任务主题，为空时重新入队全部主题的失败任务
+}
+
+// OutboxRequeueResponse 失败任务重新入队
+type OutboxRequeueResponse struct {
+	Requeued int64 `json:"requeued"` // 重新入队的任务数
+}
@@ -144,3 +144,107 @@ type MultiConfMoreInfo struct {
 	Default  string `json:"default,omitempty"`   // 默认值，仅用于预填充配置值。
 	Desc     string `json:"desc,omitempty"`      // 配置描述
 }
+
+// PublishPluginVersionRequest 发布插件版本
+type PublishPluginVersionRequest struct {
+	PluginID    int    `json:"plugin_id"`    // 插件 id
+	Changelog   string `json:"changelog"`    // 版本变更说明
+	CopyConfigs bool   `json:"copy_configs"` // 是否复制上一版本的插件配置定义
+}
+
+type PublishPluginVersionResponse struct {
+	Version int `json:"version"` // 新发布的版本
+}
+
+// DeprecatePluginVersionRequest 废弃插件版本
+type DeprecatePluginVersionRequest struct {
+	PluginID int    `json:"plugin_id"` // 插件 id
+	Version  int    `json:"version"`   // 插件版本
+	Reason   string `json:"reason"`    // 废弃原因
+}
+
+type PluginVersionListRequest struct {
+	PluginID int `json:"plugin_id"` // 插件 id
+}
+
+type PluginVersionListResponse struct {
+	Versions []*PluginVersion `json:"versions"` // 插件版本，按版本倒序
+}
+
+// PluginVersion 插件版本
+type PluginVersion struct {
+	Version         int        `json:"version"`          // 插件版本
+	Changelog       string     `json:"changelog"`        // 版本变更说明
+	Status          int8       `json:"status"`           // 状态 1-正常 2-已废弃
+	DeprecateReason string     `json:"deprecate_reason"` // 废弃原因
+	Publisher       *UsersBase `json:"publisher"`        // 发布人
+	DeprecatedBy    *UsersBase `json:"deprecated_by"`    // 废弃人
+	PublishedAt     int64      `json:"publish_time"`     // 发布时间
+	DeprecatedAt    int64      `json:"deprecate_time"`   // 废弃时间
+}
+
+type PluginUsageRequest struct {
+	PluginID int `json:"plugin_id"` // 插件 id
+}
+
+type PluginUsageResponse struct {
+	Total    int                   `json:"total"`    // 使用插件的表插件总数
+	Versions []*PluginVersionUsage `json:"versions"` // 各版本使用数量
+	Usages   []*PluginUsage        `json:"usages"`   // 使用插件的表插件
+}
+
+type PluginVersionUsage struct {
+	Version    int  `json:"version"`    // 插件版本
+	Count      int  `json:"count"`      // 使用数量
+	Deprecated bool `json:"deprecated"` // 是否已废弃
+}
+
+type PluginUsage struct {
+	TablePluginID int    `json:"table_plugin_id"` // 表插件 id
+	TableID       int    `json:"table_id"`        // 表 id
+	TableName     string `json:"table_name"`      // 表名
+	DB            int    `json:"db"`              // 所属数据库
+	Type          int8   `json:"type"`            // 插件类型 1-前置插件 2-后置插件 3-defer 插件
+	PluginVersion int    `json:"plugin_version"`  // 插件版本
+	Status        int8   `json:"status"`          // 状态 1-启用 2-停用
+	Deprecated    bool   `json:"deprecated"`      // 插件版本是否已废弃
+}
+
+// UpgradePluginVersionRequest 批量升级表插件版本
+type UpgradePluginVersionRequest struct {
+	PluginID    int               `json:"plugin_id"`    // 插件 id
+	FromVersion int               `json:"from_version"` // 仅升级该版本的表插件，0 为除目标版本外的所有版本
+	ToVersion   int               `json:"to_version"`   // 目标版本
+	TableIds    []int             `json:"table_ids"`    // 待升级的表，按灰度顺序排列
+	BatchSize   int               `json:"batch_size"`   // 本次最多升级的表数量，0 为不限制，可多次调用分批灰度
+	KeyMapping  map[string]string `json:"key_mapping"`  // 旧版本配置 key => 新版本配置 key，值为空表示删除，未指定的按同名 key 迁移
+	DryRun      bool              `json:"dry_run"`      // 试运行，仅返回配置差异，不做修改
+}
+
+type UpgradePluginVersionResponse struct {
+	ToVersion int                   `json:"to_version"` // 目标版本
+	DryRun    bool                  `json:"dry_run"`    // 是否试运行
+	Upgraded  int                   `json:"upgraded"`   // 升级成功的表数量
+	Failed    int                   `json:"failed"`     // 升级失败的表数量
+	Pending   int                   `json:"pending"`    // 未处理的表数量
+	Results   []*TablePluginUpgrade `json:"results"`    // 各表插件升级结果，按灰度顺序排列
+}
+
+type TablePluginUpgrade struct {
+	TablePluginID int           `json:"table_plugin_id"` // 表插件 id
+	TableID       int           `json:"table_id"`        // 表 id
+	TableName     string        `json:"table_name"`      // 表名
+	Type          int8          `json:"type"`            // 插件类型 1-前置插件 2-后置插件 3-defer 插件
+	FromVersion   int           `json:"from_version"`    // 升级前版本
+	Status        string        `json:"status"`          // 升级状态 ready、upgraded、up_to_date、failed、pending
+	Error         string        `json:"error"`           // 失败原因
+	ConfigDiff    []*ConfigDiff `json:"config_diff"`     // 配置差异
+}
+
+type ConfigDiff struct {
+	Key      string      `json:"key"`       // 新版本配置 key，删除时为旧版本配置 key
+	OldKey   string      `json:"old_key"`   // 旧版本配置 key
+	Action   string      `json:"action"`    // keep、rename、default、remove
+	OldValue interface{} `json:"old_value"` // 原值
+	NewValue interface{} `json:"new_value"` // 新值
+}
@@ -124,6 +124,13 @@ type ProductApprovalRequest struct {
 	Reason    string `json:"reason"`     // 拒绝理由（ status=2 时输入）
 }
 
+type ProductBatchApprovalRequest struct {
+	ProductID int      `json:"product_id"` // 产品 id
+	Userids   []uint64 `json:"userids"`    // 待审批 userid 列表
+	Status    int8     `json:"status"`     // 1-审批通过 2-审批拒绝
+	Reason    string   `json:"reason"`     // 拒绝理由（ status=2 时输入）
+}
+
 type ProductChangeRoleApplyRequest struct {
 	ProductID int    `json:"product_id"` // 产品 id
 	Role      int8   `json:"role"`       // 2-开发者 3-运营者
@@ -13,3 +13,52 @@
 // limitations under the License.
 
 package pb
+
+// SearchRequest 全局检索
+type SearchRequest struct {
+	Keyword string `json:"keyword"` // 关键词
+	Type    int8   `json:"type"`    // 检索类型 0-全部 1-product 2-db 3-table
+	Page    int    `json:"page"`    // 分页，各类型分别分页
+	Size    int    `json:"size"`    // 每页大小
+}
+
+// SearchResponse 全局检索，按类型分组返回
+type SearchResponse struct {
+	Groups    []*SearchGroup `json:"groups"`
+	Truncated bool           `json:"truncated"` // 命中记录超过单次检索上限，结果与总数只基于最新的部分记录，可细化关键词后重新检索
+}
+
+// SearchGroup 同一类型的检索结果
+type SearchGroup struct {
+	Type      int8          `json:"type"`       // 检索类型 1-product 2-db 3-table
+	Total     uint64        `json:"total"`      // 总数，truncated 时为已读取记录中的命中数
+	TotalPage uint32        `json:"total_page"` // 总页数
+	Page      int           `json:"page"`       // 分页
+	Size      int           `json:"size"`       // 每页大小
+	Items     []*SearchItem `json:"items"`      // 检索结果，按相关度倒序
+}
+
+// SearchItem 检索结果
+type SearchItem struct {
+	Sid        int                `json:"sid"`               // 检索id，即 product/db/table id
+	Name       string             `json:"name"`              // 检索名
+	Score      int                `json:"score"`             // 相关度
+	Highlights []*SearchHighlight `json:"highlights"`        // 命中的字段，关键词以 <em></em> 标记
+	Product    *ProductBase       `json:"product,omitempty"` // type 为 product 时返回
+	DB         *DBBase            `json:"db,omitempty"`      // type 为 db 时返回
+	Table      *TableBase         `json:"table,omitempty"`   // type 为 table 时返回
+}
+
+// RebuildSearchIndexResponse 重建检索信息
+type RebuildSearchIndexResponse struct {
+	Products int `json:"products"` // 重建的 product 数
+	DBs      int `json:"dbs"`      // 重建的 db 数
+	Tables   int `json:"tables"`   // 重建的 table 数
+	Removed  int `json:"removed"`  // 删除的失效检索信息数
+}
+
+// SearchHighlight 命中字段高亮
+type SearchHighlight struct {
+	Field   string `json:"field"`   // 字段 name、intro、creator、manager
+	Content string `json:"content"` // 高亮内容，已做 html 转义
+}
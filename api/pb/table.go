@@ -98,6 +98,6 @@ type TableIndex struct {
 
 // LangStruct 各语言对应结构体
 type LangStruct struct {
-	Language string `json:"language"` // 语言：go、java、c++、node、php
+	Language string `json:"language"` // 语言：go、java、typescript、python、c++、php
 	Struct   string `json:"struct"`
 }
@@ -22,6 +22,7 @@ type TablePluginsResponse struct {
 	PrePlugins   []*TablePlugin `json:"pre_plugins"`   // 前置插件
 	PostPlugins  []*TablePlugin `json:"post_plugins"`  // 后置插件
 	DeferPlugins []*TablePlugin `json:"defer_plugins"` // 延迟插件
+	ChainVersion int            `json:"chain_version"` // 插件链版本，调整插件顺序时带上
 }
 
 type AddTablePluginRequest struct {
@@ -67,6 +68,7 @@ type TablePlugin struct {
 	ScheduleConfig *conf.ScheduleConfig `json:"schedule_config"` // 插件调度配置
 	PluginInfo     *PluginBase          `json:"plugin_info"`     // 插件信息
 	PluginConfigs  []*TablePluginConfig `json:"plugin_configs"`  // 插件配置
+	Warning        string               `json:"warning"`         // 告警信息，如使用了已废弃的插件版本
 }
 
 type TablePluginConfig struct {
@@ -74,3 +76,26 @@ type TablePluginConfig struct {
 	IsSet  bool          `json:"is_set"` // 是否已经设置
 	Value  interface{}   `json:"value"`  // 设置值
 }
+
+type ReorderTablePluginsRequest struct {
+	TableId      int   `json:"table_id"`      // 表id
+	ChainVersion int   `json:"chain_version"` // 插件链版本，与当前版本不一致时拒绝修改
+	PrePlugins   []int `json:"pre_plugins"`   // 前置插件 id 的完整顺序，不传则不调整
+	PostPlugins  []int `json:"post_plugins"`  // 后置插件 id 的完整顺序，不传则不调整
+	DeferPlugins []int `json:"defer_plugins"` // 延迟插件 id 的完整顺序，不传则不调整
+}
+
+type ReorderTablePluginsResponse struct {
+	ChainVersion int `json:"chain_version"` // 修改后的插件链版本
+}
+
+type RepairPluginChainResponse struct {
+	ChainVersion int                 `json:"chain_version"` // 修复后的插件链版本
+	Issues       []*PluginChainIssue `json:"issues"`        // 发现并修复的问题
+}
+
+type PluginChainIssue struct {
+	Type      int8   `json:"type"`       // 插件类型 1-前置插件 2-后置插件 3-defer 插件
+	Issue     string `json:"issue"`      // 问题 multi_head、orphan、fork、cycle
+	PluginIDs []int  `json:"plugin_ids"` // 相关表插件 id
+}
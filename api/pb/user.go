@@ -27,6 +27,7 @@ type UsersBase struct {
 type SendEmailCodeRequest struct {
 	Account string `json:"account"` // 账号
 	Type    int    `json:"type"`    // 邮件类型 0-账号验证
+	Locale  string `json:"locale"`  // 邮件语言，如 zh-CN、en-US，为空使用默认语言
 }
 
 // RegisterRequest 注册
@@ -65,6 +66,27 @@ type ResetPasswordRequest struct {
 	Password string `json:"password"` // 密码
 }
 
+// LogoutRequest 退出登录
+type LogoutRequest struct {
+	SessionID int `json:"session_id"` // 要退出的会话 id，为 0 时退出当前会话
+}
+
+// ListMySessionsResponse 我的登录会话
+type ListMySessionsResponse struct {
+	Sessions []*UserSession `json:"sessions"`
+}
+
+// UserSession 登录会话
+type UserSession struct {
+	SessionID      int    `json:"session_id"`       // 会话 id
+	IP             string `json:"ip"`               // 最近访问 ip
+	UserAgent      string `json:"user_agent"`       // 登录设备 User-Agent
+	LoginTime      int64  `json:"login_time"`       // 登录时间
+	LastActiveTime int64  `json:"last_active_time"` // 最近访问时间
+	ExpireTime     int64  `json:"expire_time"`      // 失效时间
+	IsCurrent      bool   `json:"is_current"`       // 是否当前会话
+}
+
 // FindUserRequest 检索用户
 type FindUserRequest struct {
 	Keyword string `json:"keyword"` // 关键词
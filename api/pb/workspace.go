@@ -14,6 +14,83 @@
 
 package pb
 
+import (
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/server/plugin/conf"
+)
+
+// CreateWorkspaceRequest 创建空间
+type CreateWorkspaceRequest struct {
+	Workspace  string `json:"workspace"`  // workspace，全局唯一
+	Name       string `json:"name"`       // 空间名
+	Intro      string `json:"intro"`      // 简介
+	Company    string `json:"company"`    // 公司
+	Department string `json:"department"` // 部门
+
+	Settings *WorkspaceSettings `json:"settings"` // 空间设置，不传使用默认设置
+}
+
+// CreateWorkspaceResponse 创建空间
+type CreateWorkspaceResponse struct {
+	Id int `json:"workspace_id"` // 空间 id
+}
+
+// MyWorkspaceListResponse 我加入/申请的空间列表
+type MyWorkspaceListResponse struct {
+	Workspaces []*MyWorkspace `json:"workspaces"` // 空间列表
+}
+
+type MyWorkspace struct {
+	Id         int    `json:"workspace_id"` // 空间 id
+	Workspace  string `json:"workspace"`    // workspace
+	Name       string `json:"name"`         // 空间名
+	Intro      string `json:"intro"`        // 简介
+	Role       int8   `json:"role"`         // 角色 0:- 1:普通成员 2:管理员
+	Status     int8   `json:"status"`       // 状态 1-待审批 2-续期审批 3-未加入 4-正常 5-审批拒绝  6-已退出 9-已过期
+	ExpireTime int    `json:"expire_time"`  // 过期时间
+}
+
+// UpdateWorkspaceRequest 修改空间基础信息
+type UpdateWorkspaceRequest struct {
+	Name       string `json:"name"`       // 空间名
+	Intro      string `json:"intro"`      // 简介
+	Company    string `json:"company"`    // 公司
+	Department string `json:"department"` // 部门
+}
+
+// WorkspaceSettings 空间设置
+type WorkspaceSettings struct {
+	DefaultExpireType int8                    `json:"default_expire_type"` // 成员默认有效期，申请加入未指定有效期时使用 0: 永久 1: 一个月 2: 三个月 3: 半年 4: 一年
+	AutoApprove       bool                    `json:"auto_approve"`        // 加入/续期申请是否自动通过
+	EmailDomains      []string                `json:"email_domains"`       // 允许注册/加入空间的邮箱域名，如 example.com，为空不限制
+	DefaultPlugins    []*WorkspaceTablePlugin `json:"default_plugins"`     // 新建表默认插件链，同类型插件按顺序执行
+}
+
+// WorkspaceTablePlugin 新建表默认插件
+type WorkspaceTablePlugin struct {
+	PluginID       int                    `json:"plugin_id"`       // 插件id
+	PluginVersion  int                    `json:"plugin_version"`  // plugin 版本
+	Type           int8                   `json:"type"`            // 过滤器类型 1-前置过滤器 2-后置过滤器 3-defer 过滤器
+	Desc           string                 `json:"desc"`            // 描述
+	ScheduleConfig *conf.ScheduleConfig   `json:"schedule_config"` // 插件调度配置
+	PluginConfigs  map[string]interface{} `json:"plugin_configs"`  // 插件配置
+}
+
+// UnmarshalJSON 解码前预填默认灰度比例，区分未设置 gray_scale 与设置为 0
+func (p *WorkspaceTablePlugin) UnmarshalJSON(data []byte) error {
+	type plugin WorkspaceTablePlugin
+
+	v := plugin{ScheduleConfig: &conf.ScheduleConfig{GrayScale: consts.ScheduleDefaultGrayScale}}
+	err := json.Api.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+
+	*p = WorkspaceTablePlugin(v)
+	return nil
+}
+
 // WorkspaceBaseInfoRequest 空间基础信息
 type WorkspaceBaseInfoRequest struct {
 	Workspace string `json:"workspace"` // workspace
@@ -72,7 +149,7 @@ type WorkspaceMemberInviteRequest struct {
 }
 
 type WorkspaceJoinApplyRequest struct {
-	ExpireType int8   `json:"expire_type"` // 0: 永久 1: 一个月 2: 三个月 3: 半年 4: 一年
+	ExpireType *int8  `json:"expire_type"` // 0: 永久 1: 一个月 2: 三个月 3: 半年 4: 一年，不传使用空间默认有效期
 	Reason     string `json:"reason"`      // 申请理由
 }
 
@@ -82,6 +159,12 @@ type WorkspaceApprovalRequest struct {
 	Reason string `json:"reason"` // 拒绝理由（ status=2 时输入）
 }
 
+type WorkspaceBatchApprovalRequest struct {
+	Userids []uint64 `json:"userids"` // 待审批 userid 列表
+	Status  int8     `json:"status"`  // 1-审批通过 2-审批拒绝
+	Reason  string   `json:"reason"`  // 拒绝理由（ status=2 时输入）
+}
+
 type WorkspaceMemberRemoveRequest struct {
 	Userid uint64 `json:"userid"` // userid
 	Reason string `json:"reason"` // 移除理由
@@ -35,7 +35,7 @@ func AddPlugin(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (int
 		return nil, errs.Newf(errs.RetWebParamEmpty, "name/support_types can`t be empty")
 	}
 
-	return logic.AddPlugin(ctx, head.Userid, &req)
+	return logic.AddPlugin(ctx, head.Userid, int(head.WorkspaceId), &req)
 }
 
 // UpdatePlugin 更新插件
@@ -116,3 +116,82 @@ func PluginConfigs(ctx context.Context, head *head.WebReqHeader, reqBuf []byte)
 
 	return logic.PluginConfigs(ctx, &req)
 }
+
+// PublishPluginVersion 发布插件版本
+func PublishPluginVersion(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.PublishPluginVersionRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PluginID == 0 || req.Changelog == "" {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "plugin_id/changelog can`t be empty")
+	}
+
+	return logic.PublishPluginVersion(ctx, head.Userid, &req)
+}
+
+// DeprecatePluginVersion 废弃插件版本
+func DeprecatePluginVersion(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.DeprecatePluginVersionRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PluginID == 0 || req.Version == 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "plugin_id/version can`t be empty")
+	}
+
+	return nil, logic.DeprecatePluginVersion(ctx, head.Userid, &req)
+}
+
+// PluginVersionList 插件版本列表
+func PluginVersionList(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.PluginVersionListRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PluginID == 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "plugin id can`t be empty")
+	}
+
+	return logic.PluginVersionList(ctx, req.PluginID)
+}
+
+// PluginUsage 插件使用情况
+func PluginUsage(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.PluginUsageRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PluginID == 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "plugin id can`t be empty")
+	}
+
+	return logic.PluginUsage(ctx, req.PluginID)
+}
+
+// UpgradePluginVersion 批量升级表插件版本
+func UpgradePluginVersion(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.UpgradePluginVersionRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.PluginID == 0 || req.ToVersion == 0 || len(req.TableIds) == 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "plugin_id/to_version/table_ids can`t be empty")
+	}
+
+	if req.BatchSize < 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [batch_size] is invalid")
+	}
+
+	return logic.UpgradePluginVersion(ctx, head.Userid, &req)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/horm-database/manage/consts"
 	"github.com/horm-database/manage/logic"
 	"github.com/horm-database/manage/srv/transport/web/head"
+	"github.com/samber/lo"
 )
 
 // AddProduct 新增产品
@@ -36,7 +37,7 @@ func AddProduct(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (in
 		return nil, errs.Newf(errs.RetWebParamEmpty, "product name can`t be empty")
 	}
 
-	return logic.AddProduct(ctx, head.Userid, &req)
+	return logic.AddProduct(ctx, head.Userid, int(head.WorkspaceId), &req)
 }
 
 // UpdateProduct 产品基础信息更新
@@ -191,6 +192,29 @@ func ProductApproval(ctx context.Context, head *head.WebReqHeader, reqBuf []byte
 	return nil, logic.ProductApproval(ctx, head.Userid, &req)
 }
 
+// ProductBatchApproval 产品权限批量审批
+func ProductBatchApproval(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.ProductBatchApprovalRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ProductID == 0 || len(req.Userids) == 0 || lo.Contains(req.Userids, 0) {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "userids/product_id can`t be empty")
+	}
+
+	if len(req.Userids) > consts.BatchApprovalMaxItems {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "userids can`t exceed %d", consts.BatchApprovalMaxItems)
+	}
+
+	if req.Status != consts.ApprovalAccess && req.Status != consts.ApprovalReject {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [status] is invalid")
+	}
+
+	return logic.ProductBatchApproval(ctx, head.Userid, &req), nil
+}
+
 // ProductChangeRoleApply 申请变更角色
 func ProductChangeRoleApply(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
 	req := pb.ProductChangeRoleApplyRequest{}
@@ -13,3 +13,61 @@
 // limitations under the License.
 
 package api
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/logic"
+	"github.com/horm-database/manage/srv/transport/web/head"
+)
+
+// Search 全局检索 product、db、table
+func Search(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.SearchRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Keyword = strings.TrimSpace(req.Keyword)
+	if req.Keyword == "" {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "keyword can`t be empty")
+	}
+
+	if req.Type < 0 || req.Type > consts.SearchTypeTable {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [type] is invalid")
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Size <= 0 {
+		req.Size = 20
+	}
+
+	if req.Size > 100 {
+		req.Size = 100
+	}
+
+	if req.Page > math.MaxInt32/req.Size {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [page] is too large")
+	}
+
+	return logic.Search(ctx, &req)
+}
+
+// RebuildSearchIndex 根据源表重建全部检索信息（空间管理员）
+func RebuildSearchIndex(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	err := DecodeAndAuth(ctx, head, reqBuf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return logic.RebuildSearchIndex(ctx, head.Userid, int(head.WorkspaceId))
+}
@@ -36,7 +36,7 @@ func AddTable(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (inte
 		return nil, errs.Newf(errs.RetWebParamEmpty, "name/db_id can`t be empty")
 	}
 
-	return logic.AddTable(ctx, head.Userid, &req)
+	return logic.AddTable(ctx, head.Userid, int(head.WorkspaceId), &req)
 }
 
 // UpdateTableBase 表基础信息更新
@@ -26,7 +26,8 @@ import (
 
 // AddTablePlugin 新增表插件
 func AddTablePlugin(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
-	req := pb.AddTablePluginRequest{}
+	// 请求中未设置的调度配置项保留默认值
+	req := pb.AddTablePluginRequest{ScheduleConfig: logic.GetDefaultScheduleConfig()}
 	err := DecodeAndAuth(ctx, head, reqBuf, &req)
 	if err != nil {
 		return nil, err
@@ -45,7 +46,7 @@ func AddTablePlugin(ctx context.Context, head *head.WebReqHeader, reqBuf []byte)
 
 // UpdateTablePlugin 修改表插件
 func UpdateTablePlugin(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
-	req := pb.UpdateTablePluginRequest{}
+	req := pb.UpdateTablePluginRequest{ScheduleConfig: logic.GetDefaultScheduleConfig()}
 	err := DecodeAndAuth(ctx, head, reqBuf, &req)
 	if err != nil {
 		return nil, err
@@ -83,3 +84,33 @@ func TablePlugins(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (
 
 	return logic.TablePlugins(ctx, head.Userid, req.TableID)
 }
+
+// ReorderTablePlugins 调整表插件顺序
+func ReorderTablePlugins(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.ReorderTablePluginsRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TableId == 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "table_id can`t be empty")
+	}
+
+	return logic.ReorderTablePlugins(ctx, head.Userid, &req)
+}
+
+// RepairPluginChain 修复表插件链
+func RepairPluginChain(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.TableIDRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.TableID == 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "table_id can`t be empty")
+	}
+
+	return logic.RepairPluginChain(ctx, head.Userid, req.TableID)
+}
@@ -54,7 +54,7 @@ func Register(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (inte
 		return nil, errs.Newf(errs.RetWebParamEmpty, "account/code/password/nickname can`t be empty")
 	}
 
-	return nil, logic.Register(ctx, &req)
+	return nil, logic.Register(ctx, int(head.WorkspaceId), &req)
 }
 
 func Login(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
@@ -87,6 +87,37 @@ func ResetPassword(ctx context.Context, head *head.WebReqHeader, reqBuf []byte)
 	return nil, logic.ResetPassword(ctx, &req)
 }
 
+// Logout 退出登录
+func Logout(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.LogoutRequest{}
+	session, err := DecodeAndVerifySession(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, logic.Logout(ctx, session, req.SessionID)
+}
+
+// LogoutAllSessions 退出所有设备上的登录
+func LogoutAllSessions(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	_, err := DecodeAndVerifySession(ctx, head, reqBuf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, logic.LogoutAllSessions(ctx, head.Userid)
+}
+
+// ListMySessions 我的登录会话列表
+func ListMySessions(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	session, err := DecodeAndVerifySession(ctx, head, reqBuf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return logic.ListMySessions(ctx, session)
+}
+
 func FindUser(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
 	req := pb.FindUserRequest{}
 	err := DecodeAndAuth(ctx, head, reqBuf, &req)
@@ -21,61 +21,126 @@ import (
 	"github.com/horm-database/common/json"
 	"github.com/horm-database/common/types"
 	"github.com/horm-database/manage/api/pb"
-	"github.com/horm-database/manage/auth"
 	"github.com/horm-database/manage/consts"
 	"github.com/horm-database/manage/logic"
-	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/manage/srv"
 	"github.com/horm-database/manage/srv/transport/web/head"
+	sc "github.com/horm-database/server/consts"
+	"github.com/samber/lo"
 )
 
-// WorkspaceBaseInfo 工作空间基础信息
-func WorkspaceBaseInfo(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
-	req := pb.WorkspaceBaseInfoRequest{}
-	err := json.Api.Unmarshal(reqBuf, &req)
+// CreateWorkspace 创建空间
+func CreateWorkspace(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.CreateWorkspaceRequest{}
+	_, err := DecodeAndVerifySession(ctx, head, reqBuf, &req)
 	if err != nil {
-		return nil, errs.Newf(errs.ErrServerDecode,
-			"decode request error: %v, request:[%s]", err, types.QuickReplaceLFCR2Space(reqBuf))
+		return nil, err
+	}
+
+	cfg := srv.Config().Workspace
+	if !cfg.AllowCreate && lo.IndexOf(cfg.Creators, head.Userid) == -1 {
+		return nil, errs.Newf(errs.RetWebAccessPermissionDeny, "user is not allowed to create workspace")
 	}
 
 	if req.Workspace == "" {
 		return nil, errs.Newf(errs.RetWebParamEmpty, "workspace can`t be empty")
 	}
 
-	return logic.WorkspaceBaseInfo(ctx, head.Userid, &req)
+	if req.Name == "" {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "name can`t be empty")
+	}
+
+	if req.Settings != nil {
+		err = checkWorkspaceSettings(req.Settings)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return logic.CreateWorkspace(ctx, head.Userid, &req)
 }
 
-// WorkspaceJoinApply 申请加入工作空间/续期
-func WorkspaceJoinApply(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
-	req := pb.WorkspaceJoinApplyRequest{}
-	err := json.Api.Unmarshal(reqBuf, &req)
+// UpdateWorkspace 修改空间基础信息
+func UpdateWorkspace(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.UpdateWorkspaceRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
 	if err != nil {
-		return nil, errs.Newf(errs.ErrServerDecode,
-			"decode request error: %v, request:[%s]", err, types.QuickReplaceLFCR2Space(reqBuf))
+		return nil, err
 	}
 
-	if head.Userid == 0 {
-		return nil, errs.New(errs.RetWebNotLogin, "please login first")
+	if req.Name == "" {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "name can`t be empty")
 	}
 
-	notFind, user, err := table.GetUserByID(ctx, head.Userid)
+	return nil, logic.UpdateWorkspace(ctx, head.Userid, int(head.WorkspaceId), &req)
+}
+
+// WorkspaceSettings 空间设置
+func WorkspaceSettings(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	err := DecodeAndAuth(ctx, head, reqBuf, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if notFind {
-		return nil, errs.New(errs.RetWebNotFindUser, "not find user")
+	return logic.WorkspaceSettings(ctx, int(head.WorkspaceId))
+}
 
+// UpdateWorkspaceSettings 修改空间设置
+func UpdateWorkspaceSettings(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.WorkspaceSettings{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
 	}
 
-	if !auth.SignSuccess(head, user.Token) {
-		//return errs.Newf(errs.ErrAuthFail, "signature failed")
+	err = checkWorkspaceSettings(&req)
+	if err != nil {
+		return nil, err
 	}
 
-	if head.Userid == 0 {
-		return nil, errs.New(errs.RetWebNotLogin, "please login first")
+	return nil, logic.UpdateWorkspaceSettings(ctx, head.Userid, int(head.WorkspaceId), &req)
+}
+
+// MyWorkspaceList 我加入/申请的空间列表
+func MyWorkspaceList(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	_, err := DecodeAndVerifySession(ctx, head, reqBuf, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if req.ExpireType > consts.ExpireTypeYear || req.ExpireType < consts.ExpireTypePermanent {
+	return logic.MyWorkspaceList(ctx, head.Userid)
+}
+
+// WorkspaceBaseInfo 工作空间基础信息
+func WorkspaceBaseInfo(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.WorkspaceBaseInfoRequest{}
+	err := json.Api.Unmarshal(reqBuf, &req)
+	if err != nil {
+		return nil, errs.Newf(errs.ErrServerDecode,
+			"decode request error: %v, request:[%s]", err, types.QuickReplaceLFCR2Space(reqBuf))
+	}
+
+	if req.Workspace == "" {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "workspace can`t be empty")
+	}
+
+	return logic.WorkspaceBaseInfo(ctx, head.Userid, &req)
+}
+
+// WorkspaceJoinApply 申请加入工作空间/续期
+func WorkspaceJoinApply(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.WorkspaceJoinApplyRequest{}
+	_, err := DecodeAndVerifySession(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if head.WorkspaceId == 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "workspace_id can`t be empty")
+	}
+
+	if req.ExpireType != nil &&
+		(*req.ExpireType > consts.ExpireTypeYear || *req.ExpireType < consts.ExpireTypePermanent) {
 		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [expire_type] is invalid")
 	}
 
@@ -101,6 +166,29 @@ func WorkspaceApproval(ctx context.Context, head *head.WebReqHeader, reqBuf []by
 	return nil, logic.WorkspaceApproval(ctx, head.Userid, int(head.WorkspaceId), &req)
 }
 
+// WorkspaceBatchApproval 空间权限批量审批
+func WorkspaceBatchApproval(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
+	req := pb.WorkspaceBatchApprovalRequest{}
+	err := DecodeAndAuth(ctx, head, reqBuf, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Userids) == 0 || lo.Contains(req.Userids, 0) {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "userids can`t be empty")
+	}
+
+	if len(req.Userids) > consts.BatchApprovalMaxItems {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "userids can`t exceed %d", consts.BatchApprovalMaxItems)
+	}
+
+	if req.Status != consts.ApprovalAccess && req.Status != consts.ApprovalReject {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [status] is invalid")
+	}
+
+	return logic.WorkspaceBatchApproval(ctx, head.Userid, int(head.WorkspaceId), &req), nil
+}
+
 // WorkspaceMemberInvite 管理员邀请用户加入空间
 func WorkspaceMemberInvite(ctx context.Context, head *head.WebReqHeader, reqBuf []byte) (interface{}, error) {
 	req := pb.WorkspaceMemberInviteRequest{}
@@ -168,3 +256,22 @@ func MaintainWorkspaceManager(ctx context.Context, head *head.WebReqHeader, reqB
 
 	return nil, logic.MaintainWorkspaceManager(ctx, head.Userid, int(head.WorkspaceId), req.Manager)
 }
+
+// checkWorkspaceSettings 校验空间设置参数
+func checkWorkspaceSettings(settings *pb.WorkspaceSettings) error {
+	if settings.DefaultExpireType > consts.ExpireTypeYear || settings.DefaultExpireType < consts.ExpireTypePermanent {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [default_expire_type] is invalid")
+	}
+
+	for _, v := range settings.DefaultPlugins {
+		if v.PluginID == 0 {
+			return errs.Newf(errs.RetWebParamEmpty, "input param [default_plugins.plugin_id] is invalid")
+		}
+
+		if v.Type != sc.PrePlugin && v.Type != sc.PostPlugin && v.Type != sc.DeferPlugin {
+			return errs.Newf(errs.RetWebParamEmpty, "input param [default_plugins.type] is invalid")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/horm-database/common/crypto"
+	"github.com/horm-database/common/errs"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 密码存储格式为 {算法版本前缀}{hash}，未带前缀的 32 位 hash 为历史 md5 密码。
+// tbl_user.password 为 varchar(64)，bcrypt hash 固定 60 位，前缀不能超过 4 位。
+const (
+	PasswordPrefixBcryptV1 = "bc1$" // bcrypt，cost 12
+
+	bcryptV1Cost = 12
+)
+
+// PasswordPrefixCurrent 当前使用的密码算法版本，新密码及重新 hash 都使用该版本
+const PasswordPrefixCurrent = PasswordPrefixBcryptV1
+
+// HashPassword 使用当前算法版本生成密码 hash
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptV1Cost)
+	if err != nil {
+		return "", errs.Newf(errs.ErrSystem, "hash password error: %v", err)
+	}
+
+	return PasswordPrefixCurrent + string(hash), nil
+}
+
+// VerifyPassword 校验密码，返回是否正确，以及是否需要以当前算法版本重新 hash（历史 md5 密码或旧版本算法）
+func VerifyPassword(password, stored string) (ok bool, needRehash bool) {
+	switch {
+	case strings.HasPrefix(stored, PasswordPrefixBcryptV1):
+		hash := strings.TrimPrefix(stored, PasswordPrefixBcryptV1)
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+			return false, false
+		}
+		return true, PasswordPrefixCurrent != PasswordPrefixBcryptV1
+	case len(stored) == 32: // 历史 md5 密码
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(crypto.MD5Str(password))) != 1 {
+			return false, false
+		}
+		return true, true
+	default:
+		return false, false
+	}
+}
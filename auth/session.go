@@ -0,0 +1,155 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/manage/srv"
+	"github.com/horm-database/manage/srv/transport/web/head"
+)
+
+// 会话最近访问时间的刷新间隔（单位 s），避免每个请求都写库
+const sessionTouchInterval = 60
+
+// NewSession 为登录设备创建会话，在线设备数超过上限时最久未访问的会话被挤下线
+func NewSession(ctx context.Context, header *head.WebReqHeader, userid uint64) (*table.TblUserSession, error) {
+	sessions, err := table.GetUserOnlineSessions(ctx, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	maxNum := srv.Config().Auth.SessionMaxNum
+	if len(sessions) >= maxNum {
+		evictIds := []int{}
+		for _, v := range sessions[maxNum-1:] {
+			evictIds = append(evictIds, v.Id)
+		}
+
+		err = table.LogoutUserSessions(ctx, userid, evictIds...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := genSessionToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+
+	session := table.TblUserSession{
+		UserID:         userid,
+		Token:          token,
+		IP:             header.Ip,
+		UserAgent:      header.UserAgent,
+		Status:         consts.SessionStatusOnline,
+		LoginTime:      now,
+		LastActiveTime: now,
+		ExpireTime:     now + int64(srv.Config().Auth.SessionMaxAge),
+	}
+
+	session.Id, err = table.InsertUserSession(ctx, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// VerifySession 校验请求签名，返回签名所用 token 对应的在线会话
+func VerifySession(ctx context.Context, header *head.WebReqHeader) (*table.TblUserSession, error) {
+	if header.Userid == 0 {
+		return nil, errs.New(errs.RetWebNotLogin, "please login first")
+	}
+
+	err := checkTimestamp(header)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := table.GetUserOnlineSessions(ctx, header.Userid)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	idleTimeout := int64(srv.Config().Auth.SessionIdleTimeout)
+
+	var session *table.TblUserSession
+	for _, v := range sessions {
+		if v.LastActiveTime+idleTimeout < now { // 空闲超时
+			continue
+		}
+
+		if genSign(header, v.Token) == header.Sign {
+			session = v
+			break
+		}
+	}
+
+	if session == nil {
+		return nil, errs.New(errs.ErrAuthFail, "signature failed or session expired")
+	}
+
+	err = checkReplay(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if now-session.LastActiveTime >= sessionTouchInterval || session.IP != header.Ip {
+		update := horm.Map{}
+		update["last_active_time"] = now
+		update["ip"] = header.Ip
+
+		err = table.UpdateUserSessionByID(ctx, session.Id, update)
+		if err != nil {
+			return nil, err
+		}
+
+		session.LastActiveTime = now
+		session.IP = header.Ip
+	}
+
+	return session, nil
+}
+
+// SessionExpireTime 会话实际失效时间，取空闲超时与绝对过期时间中较早者
+func SessionExpireTime(session *table.TblUserSession) int64 {
+	expireTime := session.LastActiveTime + int64(srv.Config().Auth.SessionIdleTimeout)
+	if expireTime > session.ExpireTime {
+		return session.ExpireTime
+	}
+	return expireTime
+}
+
+func genSessionToken() (string, error) {
+	b := make([]byte, 16)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", errs.Newf(errs.ErrSystem, "generate session token error: %v", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -15,59 +15,57 @@
 package auth
 
 import (
+	"context"
 	"fmt"
-	"math/rand"
-	"sync"
 	"time"
 
 	"github.com/horm-database/common/crypto"
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/cache"
+	"github.com/horm-database/manage/srv"
 	"github.com/horm-database/manage/srv/transport/web/head"
 )
 
-var SameRequestLock = new(sync.RWMutex)
-var SameRequest = map[string]bool{}
+// checkTimestamp 请求时间戳需在允许误差内
+func checkTimestamp(header *head.WebReqHeader) error {
+	skew := int64(srv.Config().Auth.SignSkew)
+	now := time.Now().UnixMilli()
+	timestamp := int64(header.Timestamp)
 
-func init() {
-	go func() {
-		for {
-			SameRequestLock.Lock()
-			SameRequest = map[string]bool{}
-			SameRequestLock.Unlock()
-			time.Sleep(time.Duration(30+rand.Intn(30)) * time.Second) // 0.5~1 分钟清空一次
-		}
-	}()
-}
-
-// SignSuccess 签名是否正确
-func SignSuccess(header *head.WebReqHeader, token string) bool {
-	if header.Userid == 0 {
-		return false
+	if timestamp < now-skew || timestamp > now+skew {
+		return errs.Newf(errs.ErrAuthFail, "signature expired, request timestamp [%d] server timestamp [%d]", timestamp, now)
 	}
 
-	if token == "" {
-		return false
-	}
+	return nil
+}
 
-	sign := crypto.MD5Str(fmt.Sprintf("%d%d%s%s%d%d%d%s%d", header.WorkspaceId,
+// genSign 根据 token 生成请求签名
+func genSign(header *head.WebReqHeader, token string) string {
+	return crypto.MD5Str(fmt.Sprintf("%d%d%s%s%d%d%d%s%d", header.WorkspaceId,
 		header.Userid, token, header.Version, header.RequestId, header.Timestamp,
 		header.Timeout, header.Caller, header.AuthRand))
+}
 
-	if sign != header.Sign {
-		return false
-	}
+// checkReplay 同一请求不允许重放（多实例间通过 redis 共享）
+func checkReplay(ctx context.Context, header *head.WebReqHeader) error {
+	// 超过误差窗口的请求已被拒绝，防重放记录只需保留 2 倍误差时间
+	expire := 2*srv.Config().Auth.SignSkew/1000 + 1
 
 	requestUniq := fmt.Sprintf("%d%s%d", header.Timestamp, header.Ip, header.AuthRand)
 
-	SameRequestLock.Lock()
-	isSame := SameRequest[requestUniq]
-	isSame2 := SameRequest[sign]
-	SameRequest[requestUniq] = true
-	SameRequest[sign] = true
-	SameRequestLock.Unlock()
+	for _, uniq := range []string{header.Sign, requestUniq} {
+		key := fmt.Sprintf("%s%s", consts.CachePreSignReplay, uniq)
 
-	if isSame || isSame2 {
-		return false
+		ok, err := cache.SetNXCacheByKey(ctx, key, 1, expire)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return errs.New(errs.ErrAuthFail, "repeated request")
+		}
 	}
 
-	return true
+	return nil
 }
@@ -0,0 +1,184 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/logic"
+	"github.com/horm-database/manage/model/migration"
+)
+
+const commandUsage = `usage: manage [command] [flags]
+
+commands:
+  init          执行表结构迁移，并初始化空间与管理员账号
+  migrate       执行未执行的表结构迁移
+  rollback      回滚最近执行的表结构迁移
+  create-admin  创建管理员账号，并设为指定空间的管理员
+
+不带 command 时启动管理端服务，数据库配置读取自 orm.yaml，
+表结构版本与程序不一致时拒绝启动，需先执行 manage migrate。
+管理员密码不通过命令行参数传入，读取自环境变量 MANAGE_ADMIN_PASSWORD，或指定 -password-stdin 从标准输入读取
+`
+
+// adminPasswordEnv 管理员密码环境变量，避免密码出现在进程参数与 shell 历史中
+const adminPasswordEnv = "MANAGE_ADMIN_PASSWORD"
+
+// runCommand 执行子命令
+func runCommand(name string, args []string) error {
+	ctx := context.Background()
+
+	switch name {
+	case "init":
+		return initCommand(ctx, args)
+	case "migrate":
+		return migrateCommand(ctx, args)
+	case "rollback":
+		return rollbackCommand(ctx, args)
+	case "create-admin":
+		return createAdminCommand(ctx, args)
+	case "help", "-h", "-help", "--help":
+		fmt.Print(commandUsage)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, commandUsage)
+		return errs.Newf(errs.RetWebParamEmpty, "unknown command [%s]", name)
+	}
+}
+
+// initCommand 新部署初始化：建表、创建空间和管理员账号，可重复执行
+func initCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	workspace := fs.String("workspace", "default", "空间 workspace")
+	name := fs.String("name", "默认空间", "空间名")
+	account := fs.String("account", "admin", "管理员账号")
+	passwordStdin := fs.Bool("password-stdin", false, "从标准输入读取管理员密码，否则读取环境变量 "+
+		adminPasswordEnv+"，账号不存在时必填")
+	nickname := fs.String("nickname", "", "管理员昵称，默认同账号")
+	_ = fs.Parse(args)
+
+	password, err := readPassword(*passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	err = migrate(ctx)
+	if err != nil {
+		return err
+	}
+
+	workspaceID, userid, err := logic.InitWorkspace(ctx, *workspace, *name, *account, password, *nickname)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("workspace [%s] id=%d, admin [%s] userid=%d\n", *workspace, workspaceID, *account, userid)
+	return nil
+}
+
+// migrateCommand 执行表结构迁移
+func migrateCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	return migrate(ctx)
+}
+
+// rollbackCommand 回滚表结构迁移
+func rollbackCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	steps := fs.Int("steps", 1, "回滚的迁移个数")
+	_ = fs.Parse(args)
+
+	if *steps <= 0 {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [steps] is invalid")
+	}
+
+	rollback, err := migration.Rollback(ctx, *steps)
+	for _, m := range rollback {
+		fmt.Printf("rollback migration %s\n", m)
+	}
+
+	return err
+}
+
+// createAdminCommand 创建管理员账号
+func createAdminCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	workspace := fs.String("workspace", "default", "空间 workspace")
+	account := fs.String("account", "", "管理员账号")
+	passwordStdin := fs.Bool("password-stdin", false, "从标准输入读取管理员密码，否则读取环境变量 "+
+		adminPasswordEnv+"，账号不存在时必填")
+	nickname := fs.String("nickname", "", "管理员昵称，默认同账号")
+	_ = fs.Parse(args)
+
+	if *account == "" {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [account] is empty")
+	}
+
+	password, err := readPassword(*passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	userid, err := logic.CreateAdmin(ctx, *workspace, *account, password, *nickname)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("admin [%s] userid=%d is manager of workspace [%s]\n", *account, userid, *workspace)
+	return nil
+}
+
+func migrate(ctx context.Context) error {
+	applied, err := migration.Migrate(ctx)
+	for _, m := range applied {
+		fmt.Printf("applied migration %s\n", m)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	version, err := migration.Version()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("schema is up to date, version %d\n", version)
+	return nil
+}
+
+// readPassword 读取管理员密码，fromStdin 为 true 时读取标准输入的第一行，否则读取环境变量
+func readPassword(fromStdin bool) (string, error) {
+	if !fromStdin {
+		return os.Getenv(adminPasswordEnv), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", errs.Newf(errs.ErrSystem, "read password from stdin error: %v", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -15,7 +15,10 @@
 package consts
 
 const (
-	CachePreEmailCode = "PreEmailCode_"
+	CachePreEmailCode  = "PreEmailCode_"
+	CachePreSignReplay = "PreSignReplay_"
+
+	CacheEmailCodeIndex = "EmailCodeIndex" // 邮箱验证码索引，有序集合，成员为邮箱，分数为验证码过期时间
 )
 
 const (
@@ -53,6 +56,78 @@ const (
 	StatusOffline = 2 // 下线
 )
 
+const (
+	SessionStatusOnline = 1 // 正常
+	SessionStatusLogout = 2 // 已退出
+)
+
+const (
+	AuditActionCreate = 1 // 新增
+	AuditActionUpdate = 2 // 修改
+	AuditActionDelete = 3 // 删除
+)
+
+const (
+	OutboxStatusPending    = 1 // 待处理
+	OutboxStatusProcessing = 2 // 处理中
+	OutboxStatusFailed     = 3 // 失败（超过最大重试次数）
+)
+
+const (
+	NotifyEventApply    = "apply"    // 新的申请，通知审批人
+	NotifyEventWithdraw = "withdraw" // 申请已撤销，通知审批人
+	NotifyEventResult   = "result"   // 审批结果，通知申请人
+	NotifyEventExpiring = "expiring" // 权限即将过期，通知应用管理员或成员本人
+	NotifyEventExpired  = "expired"  // 权限已过期，通知应用管理员或成员本人
+)
+
+// 通知渠道，每个渠道一个异步任务，重试时不会重复发送已成功的渠道
+const (
+	NotifyChannelMail    = "mail"    // 邮件，同时更新站内信标题
+	NotifyChannelWebhook = "webhook" // webhook
+)
+
+const (
+	NotifyKindWorkspaceJoin      = "workspace_join"       // 申请加入空间
+	NotifyKindWorkspaceRenewal   = "workspace_renewal"    // 空间权限续期
+	NotifyKindProductJoin        = "product_join"         // 申请加入产品
+	NotifyKindProductRenewal     = "product_renewal"      // 产品权限续期
+	NotifyKindProductChangeRole  = "product_change_role"  // 产品角色变更
+	NotifyKindAccessDB           = "access_db"            // 应用接入仓库
+	NotifyKindAccessTable        = "access_table"         // 应用接入表数据
+	NotifyKindAccessDBRenewal    = "access_db_renewal"    // 应用接入仓库权限续期
+	NotifyKindAccessTableRenewal = "access_table_renewal" // 应用接入表数据权限续期
+)
+
+const (
+	AccessTypeDB    = 1 // 应用接入仓库
+	AccessTypeTable = 2 // 应用接入表数据
+)
+
+const (
+	AccessRenewStatusNone     = 0 // 无续期申请
+	AccessRenewStatusApproval = 1 // 续期审批中
+)
+
+const AccessRenewDays = 7 // 权限过期前多少天内允许申请续期
+
+const MemberRenewDays = 7 // 空间、产品成员权限过期前多少天内允许申请续期，并在此时提醒成员续期
+
+const (
+	ApprovalSortOldest = "oldest" // 最早申请的在前
+	ApprovalSortNewest = "newest" // 最新申请的在前
+)
+
+const (
+	NotificationStatusUnread = 1 // 未读
+	NotificationStatusRead   = 2 // 已读
+)
+
+const (
+	NotifyEmailOn  = 1 // 开启邮件通知
+	NotifyEmailOff = 2 // 关闭邮件通知
+)
+
 const (
 	SearchTypeProduct = 1 // product
 	SearchTypeDB      = 2 // db
@@ -78,6 +153,106 @@ const (
 	ProductMemberStatusExpired    = 7 // 已过期
 )
 
+const (
+	WorkspaceResourceApp    = 1 // 应用
+	WorkspaceResourcePlugin = 2 // 插件
+)
+
+const (
+	WorkspaceAutoApproveOn  = 1 // 加入申请自动通过
+	WorkspaceAutoApproveOff = 2 // 加入申请需管理员审批
+)
+
+const (
+	PluginConfigTypeBool      = 1  // bool
+	PluginConfigTypeString    = 2  // string
+	PluginConfigTypeInt       = 3  // int
+	PluginConfigTypeUint      = 4  // uint
+	PluginConfigTypeFloat     = 5  // float
+	PluginConfigTypeEnum      = 6  // 枚举
+	PluginConfigTypeTime      = 7  // 时间
+	PluginConfigTypeArray     = 8  // array
+	PluginConfigTypeMap       = 9  // map
+	PluginConfigTypeMultiConf = 10 // multi-conf
+)
+
+const (
+	PluginConfigNotNull  = 1 // 必输
+	PluginConfigNullable = 2 // 非必输
+)
+
+const (
+	PluginConfigTimeTypeTime         = "time"          // 时间
+	PluginConfigTimeTypeDate         = "date"          // 日期
+	PluginConfigTimeTypeTimeInterval = "time_interval" // 时间区间
+	PluginConfigTimeTypeDateInterval = "date_interval" // 日期区间
+)
+
+const (
+	ScheduleRequestSourceAPI = "api" // API 接口
+	ScheduleRequestSourceWeb = "web" // WEB 管理
+)
+
+// ScheduleDefaultGrayScale 插件调度配置默认灰度比例，未设置 gray_scale 时全量生效
+const ScheduleDefaultGrayScale = 100
+
+const (
+	ScheduleOpTypeRead = "read" // 查询
+	ScheduleOpTypeMod  = "mod"  // 新增、修改
+	ScheduleOpTypeDel  = "del"  // 删除
+)
+
+const (
+	ConditionOpEq         = 1  // 等于
+	ConditionOpNe         = 2  // 不等于
+	ConditionOpGt         = 3  // 大于
+	ConditionOpGte        = 4  // 大于等于
+	ConditionOpLt         = 5  // 小于
+	ConditionOpLte        = 6  // 小于等于
+	ConditionOpLike       = 7  // 类似于
+	ConditionOpNotLike    = 8  // 不类似于
+	ConditionOpPrefixLike = 9  // 开头类似于
+	ConditionOpSuffixLike = 10 // 结尾类似于
+	ConditionOpIn         = 11 // 存在于集合
+	ConditionOpNotIn      = 12 // 不存在于集合
+)
+
+const (
+	PluginVersionStatusNormal     = 1 // 正常
+	PluginVersionStatusDeprecated = 2 // 已废弃
+)
+
+const (
+	PluginUpgradeStatusReady    = "ready"      // 试运行通过，可以升级
+	PluginUpgradeStatusUpgraded = "upgraded"   // 已升级
+	PluginUpgradeStatusUpToDate = "up_to_date" // 已是目标版本
+	PluginUpgradeStatusFailed   = "failed"     // 升级失败
+	PluginUpgradeStatusPending  = "pending"    // 超出本批次数量或前序表升级失败，未处理
+)
+
+const (
+	ConfigDiffKeep    = "keep"    // 同名 key 保留原值
+	ConfigDiffRename  = "rename"  // 按 key 映射迁移原值
+	ConfigDiffDefault = "default" // 新增必输配置，使用默认值
+	ConfigDiffRemove  = "remove"  // 新版本不再使用，删除
+)
+
+// 不在任何插件链中的表插件类型，仅作为新增、删除表插件时的中间状态，此时插件为下线状态，对服务端不可见
+const TablePluginTypeDetached = 0
+
+// 插件链错误码，接在 common/errs 表插件错误码（1101）之后
+const (
+	RetWebPluginChainConflict = 1111 // 插件链已被其他请求修改，需刷新后重试
+	RetWebPluginChainBroken   = 1112 // 插件链损坏，需先修复
+)
+
+const (
+	PluginChainIssueMultiHead = "multi_head" // 存在多个头插件
+	PluginChainIssueOrphan    = "orphan"     // front 指向的插件不存在
+	PluginChainIssueFork      = "fork"       // 多个插件的 front 相同
+	PluginChainIssueCycle     = "cycle"      // 插件链成环，从头插件不可达
+)
+
 const (
 	PluginSourceOfficial = 1
 	PluginSourceThird    = 2
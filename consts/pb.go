@@ -23,3 +23,5 @@ const (
 	ApprovalAccess = 1 // 审批通过
 	ApprovalReject = 2 // 审批拒绝
 )
+
+const BatchApprovalMaxItems = 100 // 批量审批单次最多审批条数
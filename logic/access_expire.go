@@ -0,0 +1,344 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/manage/srv"
+	sc "github.com/horm-database/server/consts"
+)
+
+// 应用接入仓库、表数据的权限记录属于 server 的 tbl_access_db、tbl_access_table，有效期及续期申请保存在 tbl_access_expire。
+// 过期的权限由后台定时任务 access_expire 置为下线（server 只允许状态正常的权限访问），并在过期前提醒应用管理员。
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// resetAccessExpire 新申请或重新申请接入时记录申请的有效期，审批通过后才开始计算过期时间
+func resetAccessExpire(ctx context.Context, accessType int8, accessID int, expireType int8) error {
+	isNil, expire, err := table.GetAccessExpire(ctx, accessType, accessID)
+	if err != nil {
+		return err
+	}
+
+	if isNil {
+		_, err = table.InsertAccessExpire(ctx, &table.TblAccessExpire{
+			AccessType: accessType,
+			AccessID:   accessID,
+			ExpireType: expireType,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		})
+		return err
+	}
+
+	update := horm.Map{
+		"expire_type":       expireType,
+		"expire_time":       0,
+		"remind_time":       0,
+		"offline_time":      0,
+		"renew_status":      consts.AccessRenewStatusNone,
+		"renew_expire_type": 0,
+		"renew_user":        0,
+		"renew_reason":      "",
+	}
+
+	return table.UpdateAccessExpireByID(ctx, expire.Id, update)
+}
+
+// activateAccessExpire 接入申请审批通过，从现在开始计算过期时间，没有有效期记录的为永久权限
+func activateAccessExpire(ctx context.Context, accessType int8, accessID int) error {
+	isNil, expire, err := table.GetAccessExpire(ctx, accessType, accessID)
+	if err != nil || isNil {
+		return err
+	}
+
+	update := horm.Map{
+		"expire_time":  GetExpireTime(0, expire.ExpireType),
+		"remind_time":  0,
+		"offline_time": 0,
+	}
+
+	return table.UpdateAccessExpireByID(ctx, expire.Id, update)
+}
+
+// applyAccessRenewal 已接入的应用申请续期，只有 AccessRenewDays 天内过期的权限才允许续期
+func applyAccessRenewal(ctx context.Context, userid uint64,
+	accessType int8, accessID int, expireType int8, reason string) error {
+	isNil, expire, err := table.GetAccessExpire(ctx, accessType, accessID)
+	if err != nil {
+		return err
+	}
+
+	if isNil || expire.ExpireTime == 0 || expire.ExpireTime-time.Now().Unix() > consts.AccessRenewDays*86400 {
+		return errs.New(errs.RetWebAccessStatusNormal, "app already has access permission")
+	}
+
+	if expire.RenewStatus == consts.AccessRenewStatusApproval {
+		return errs.New(errs.RetWebAccessStatusChecking, "renewal is under review, please do not apply repeatedly")
+	}
+
+	update := horm.Map{
+		"renew_status":      consts.AccessRenewStatusApproval,
+		"renew_expire_type": expireType,
+		"renew_user":        userid,
+		"renew_reason":      reason,
+	}
+
+	return table.UpdateAccessExpireByID(ctx, expire.Id, update)
+}
+
+// approveAccessRenewal 续期审批，通过后从原过期时间开始延长，返回续期申请人
+func approveAccessRenewal(ctx context.Context, accessType int8, accessID int, approved bool) (uint64, error) {
+	isNil, expire, err := table.GetAccessExpire(ctx, accessType, accessID)
+	if err != nil {
+		return 0, err
+	}
+
+	if isNil || expire.RenewStatus != consts.AccessRenewStatusApproval {
+		return 0, errs.New(errs.RetWebAccessStatusNotChecking, "the status of application access is not under review")
+	}
+
+	update := horm.Map{
+		"renew_status":      consts.AccessRenewStatusNone,
+		"renew_expire_type": 0,
+		"renew_user":        0,
+		"renew_reason":      "",
+	}
+
+	if approved {
+		update["expire_type"] = expire.RenewExpireType
+		update["expire_time"] = GetExpireTime(expire.ExpireTime, expire.RenewExpireType)
+		update["remind_time"] = 0
+	}
+
+	return expire.RenewUser, table.UpdateAccessExpireByID(ctx, expire.Id, update)
+}
+
+// withdrawAccessRenewal 撤销续期申请
+func withdrawAccessRenewal(ctx context.Context, userid uint64, accessType int8, accessID int) error {
+	isNil, expire, err := table.GetAccessExpire(ctx, accessType, accessID)
+	if err != nil {
+		return err
+	}
+
+	if isNil || expire.RenewStatus != consts.AccessRenewStatusApproval {
+		return errs.New(errs.RetWebAccessStatusNotChecking, "the status of application access is not under review")
+	}
+
+	if expire.RenewUser != userid {
+		return errs.New(errs.RetWebAccessPermissionDeny, "is not my access, can`t withdraw")
+	}
+
+	update := horm.Map{
+		"renew_status":      consts.AccessRenewStatusNone,
+		"renew_expire_type": 0,
+		"renew_user":        0,
+		"renew_reason":      "",
+	}
+
+	return table.UpdateAccessExpireByID(ctx, expire.Id, update)
+}
+
+// checkAccessNotExpired 上线权限前检查是否已过期，过期的权限需要重新申请
+func checkAccessNotExpired(ctx context.Context, accessType int8, accessID int) error {
+	isNil, expire, err := table.GetAccessExpire(ctx, accessType, accessID)
+	if err != nil || isNil {
+		return err
+	}
+
+	if expire.ExpireTime != 0 && expire.ExpireTime <= time.Now().Unix() {
+		return errs.New(errs.RetWebAccessPermissionDeny, "access permission has expired, please apply again")
+	}
+
+	return nil
+}
+
+// getAccessExpireMap 权限记录的有效期，key 为权限记录id
+func getAccessExpireMap(ctx context.Context, accessType int8, accessIDs []int) (map[int]*table.TblAccessExpire, error) {
+	expires, err := table.GetAccessExpires(ctx, accessType, accessIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[int]*table.TblAccessExpire, len(expires))
+	for _, expire := range expires {
+		ret[expire.AccessID] = expire
+	}
+
+	return ret, nil
+}
+
+// sweepAccessExpire 下线已过期的权限，并提醒即将过期的权限，多实例通过抢占保证每条权限只处理一次
+func sweepAccessExpire(ctx context.Context) error {
+	cfg := srv.Config().AccessExpire
+	now := time.Now().Unix()
+
+	expired, err := table.GetExpiredAccessExpires(ctx, now, cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, expire := range expired {
+		ok, err := table.ClaimAccessExpire(ctx, expire, "offline_time", now)
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "claim expired access [%d] error: %v", expire.Id, err)
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		err = offlineExpiredAccess(ctx, expire)
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "offline expired access [%d:%d] error: %v",
+				expire.AccessType, expire.AccessID, err)
+
+			// 权限未能下线，释放抢占等待下次扫描重试，避免过期权限一直有效
+			err = table.ReleaseAccessExpire(ctx, expire, "offline_time", now)
+			if err != nil {
+				log.Errorf(ctx, errs.ErrSystem, "release expired access [%d] error: %v", expire.Id, err)
+			}
+		}
+	}
+
+	expiring, err := table.GetExpiringAccessExpires(ctx, now, now+int64(cfg.RemindDays)*86400, cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, expire := range expiring {
+		err := table.Transaction(ctx, func(ctx context.Context) error {
+			ok, err := table.ClaimAccessExpire(ctx, expire, "remind_time", now)
+			if err != nil || !ok {
+				return err
+			}
+
+			status, n, managers, err := accessExpireNotification(ctx, consts.NotifyEventExpiring, expire)
+			if err != nil {
+				return err
+			}
+
+			if status != sc.AuthStatusNormal {
+				return nil
+			}
+
+			return notify(ctx, n, managers)
+		})
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "remind expiring access [%d:%d] error: %v",
+				expire.AccessType, expire.AccessID, err)
+		}
+	}
+
+	return nil
+}
+
+// offlineExpiredAccess 在同一个事务中将过期的权限置为下线，取消未审批的续期申请，并通知应用管理员
+func offlineExpiredAccess(ctx context.Context, expire *table.TblAccessExpire) error {
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		status, n, managers, err := accessExpireNotification(ctx, consts.NotifyEventExpired, expire)
+		if err != nil {
+			return err
+		}
+
+		if expire.RenewStatus == consts.AccessRenewStatusApproval {
+			err = table.UpdateAccessExpireByID(ctx, expire.Id, horm.Map{
+				"renew_status":      consts.AccessRenewStatusNone,
+				"renew_expire_type": 0,
+				"renew_user":        0,
+				"renew_reason":      "",
+			})
+			if err != nil {
+				log.Errorf(ctx, errs.ErrSystem, "cancel renewal of expired access [%d:%d] error: %v",
+					expire.AccessType, expire.AccessID, err)
+			}
+		}
+
+		if status != sc.AuthStatusNormal {
+			return nil
+		}
+
+		update := horm.Map{"status": sc.AuthStatusOffline}
+		if expire.AccessType == consts.AccessTypeDB {
+			err = table.UpdateAccessDBByID(ctx, expire.AccessID, update)
+		} else {
+			err = table.UpdateAccessTableByID(ctx, expire.AccessID, update)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return notify(ctx, n, managers)
+	})
+}
+
+// accessExpireNotification 权限记录的当前状态，及发给应用管理员的到期通知
+func accessExpireNotification(ctx context.Context, event string,
+	expire *table.TblAccessExpire) (int8, *Notification, []uint64, error) {
+	n := Notification{
+		Event:      event,
+		AccessID:   expire.AccessID,
+		ExpireTime: expire.ExpireTime,
+	}
+
+	var status int8
+
+	if expire.AccessType == consts.AccessTypeDB {
+		accessDBs, err := table.GetAccessDBsByIds(ctx, []int{expire.AccessID})
+		if err != nil || len(accessDBs) == 0 {
+			return 0, nil, nil, err
+		}
+
+		status = accessDBs[0].Status
+		n.Kind = consts.NotifyKindAccessDB
+		n.Appid = accessDBs[0].Appid
+		n.DbID = accessDBs[0].DB
+	} else {
+		accessTables, err := table.GetAccessTablesByIds(ctx, []int{expire.AccessID})
+		if err != nil || len(accessTables) == 0 {
+			return 0, nil, nil, err
+		}
+
+		status = accessTables[0].Status
+		n.Kind = consts.NotifyKindAccessTable
+		n.Appid = accessTables[0].Appid
+		n.TableID = accessTables[0].TableId
+	}
+
+	_, app, err := table.GetAppDetail(ctx, n.Appid)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	return status, &n, GetUserIds(app.Manager), nil
+}
+
+// getAccessExpire 权限记录的有效期，没有有效期记录的为永久权限
+func getAccessExpire(expires map[int]*table.TblAccessExpire, accessID int) *table.TblAccessExpire {
+	if expire, ok := expires[accessID]; ok {
+		return expire
+	}
+
+	return &table.TblAccessExpire{AccessID: accessID}
+}
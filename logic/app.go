@@ -32,7 +32,7 @@ import (
 	"github.com/samber/lo"
 )
 
-func AddApp(ctx context.Context, userid uint64, req *pb.AddAppRequest) (*pb.AddAppResponse, error) {
+func AddApp(ctx context.Context, userid uint64, workspaceID int, req *pb.AddAppRequest) (*pb.AddAppResponse, error) {
 	if lo.IndexOf(req.Manager, userid) == -1 {
 		req.Manager = append(req.Manager, userid)
 	}
@@ -57,6 +57,11 @@ func AddApp(ctx context.Context, userid uint64, req *pb.AddAppRequest) (*pb.AddA
 		return nil, err
 	}
 
+	err = table.AddWorkspaceResource(ctx, workspaceID, consts.WorkspaceResourceApp, appid)
+	if err != nil {
+		return nil, err
+	}
+
 	return &pb.AddAppResponse{Appid: appid}, nil
 }
 
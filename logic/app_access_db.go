@@ -26,6 +26,7 @@ import (
 	"github.com/horm-database/manage/model/table"
 	sc "github.com/horm-database/server/consts"
 	st "github.com/horm-database/server/model/table"
+	"github.com/samber/lo"
 )
 
 func DBSupportOps(ctx context.Context, userid uint64, dbID int) (*pb.SupportOpsResponse, error) {
@@ -145,62 +146,97 @@ func AppCanAccessDB(ctx context.Context, userid uint64,
 
 func AppApplyAccessDB(ctx context.Context, userid uint64,
 	req *pb.AppApplyAccessDBRequest) (*pb.AppApplyAccessResponse, error) {
-	isNil, _, err := table.GetDBByID(ctx, req.DbID)
-	if err != nil {
-		return nil, err
-	}
-
-	if isNil {
-		return nil, errs.New(errs.RetWebNotFindDB, "not find db")
-	}
-
-	_, err = IsAppManager(ctx, userid, req.Appid)
-	if err != nil {
-		return nil, err
-	}
-
-	isNil, accessDB, err := table.GetAppAccessDB(ctx, req.Appid, req.DbID)
-	if err != nil {
-		return nil, err
-	}
-
 	var ret = pb.AppApplyAccessResponse{}
 
-	if isNil {
-		data := st.TblAccessDB{
-			Appid:     req.Appid,
-			DB:        req.DbID,
-			Root:      req.Root,
-			Op:        strings.Join(req.Op, ","),
-			ApplyUser: userid,
-			Status:    sc.AuthStatusChecking,
-			Reason:    req.Reason,
+	err := table.Transaction(ctx, func(ctx context.Context) error {
+		isNil, _, err := table.GetDBByID(ctx, req.DbID)
+		if err != nil {
+			return err
 		}
-		ret.AccessID, err = table.InsertAccessDB(ctx, &data)
+
+		if isNil {
+			return errs.New(errs.RetWebNotFindDB, "not find db")
+		}
+
+		_, err = IsAppManager(ctx, userid, req.Appid)
 		if err != nil {
-			return nil, err
+			return err
 		}
-	} else {
-		ret.AccessID = accessDB.Id
-		if accessDB.Status == sc.AuthStatusNormal {
-			return nil, errs.New(errs.RetWebAccessStatusNormal, "app already has access permission of db")
-		} else if accessDB.Status == sc.AuthStatusChecking {
-			return nil, errs.New(errs.RetWebAccessStatusChecking,
-				"the application's access to the database is under review")
+
+		isNil, accessDB, err := table.GetAppAccessDB(ctx, req.Appid, req.DbID)
+		if err != nil {
+			return err
 		}
 
-		update := horm.Map{
-			"root":       req.Root,
-			"op":         strings.Join(req.Op, ","),
-			"status":     sc.AuthStatusChecking,
-			"apply_user": userid,
-			"reason":     req.Reason,
+		if isNil {
+			data := st.TblAccessDB{
+				Appid:     req.Appid,
+				DB:        req.DbID,
+				Root:      req.Root,
+				Op:        strings.Join(req.Op, ","),
+				ApplyUser: userid,
+				Status:    sc.AuthStatusChecking,
+				Reason:    req.Reason,
+			}
+			ret.AccessID, err = table.InsertAccessDB(ctx, &data)
+			if err != nil {
+				return err
+			}
+		} else if accessDB.Status == sc.AuthStatusNormal { // 申请续期
+			ret.AccessID = accessDB.Id
+
+			err = applyAccessRenewal(ctx, userid, mc.AccessTypeDB, accessDB.Id, req.ExpireType, req.Reason)
+			if err != nil {
+				return err
+			}
+
+			return notify(ctx, &Notification{
+				Event:     mc.NotifyEventApply,
+				Kind:      mc.NotifyKindAccessDBRenewal,
+				Applicant: userid,
+				Appid:     req.Appid,
+				AccessID:  accessDB.Id,
+				DbID:      req.DbID,
+				Reason:    req.Reason,
+			}, dbApprovers(ctx, req.DbID))
+		} else {
+			ret.AccessID = accessDB.Id
+			if accessDB.Status == sc.AuthStatusChecking {
+				return errs.New(errs.RetWebAccessStatusChecking,
+					"the application's access to the database is under review")
+			}
+
+			update := horm.Map{
+				"root":       req.Root,
+				"op":         strings.Join(req.Op, ","),
+				"status":     sc.AuthStatusChecking,
+				"apply_user": userid,
+				"reason":     req.Reason,
+			}
+
+			err = table.UpdateAccessDBByID(ctx, accessDB.Id, update)
+			if err != nil {
+				return err
+			}
 		}
 
-		err = table.UpdateAccessDBByID(ctx, accessDB.Id, update)
+		err = resetAccessExpire(ctx, mc.AccessTypeDB, ret.AccessID, req.ExpireType)
 		if err != nil {
-			return nil, err
+			return err
 		}
+
+		return notify(ctx, &Notification{
+			Event:     mc.NotifyEventApply,
+			Kind:      mc.NotifyKindAccessDB,
+			Applicant: userid,
+			Appid:     req.Appid,
+			AccessID:  ret.AccessID,
+			DbID:      req.DbID,
+			Reason:    req.Reason,
+		}, dbApprovers(ctx, req.DbID))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &ret, nil
@@ -208,65 +244,161 @@ func AppApplyAccessDB(ctx context.Context, userid uint64,
 
 // AppAccessDBApproval 申请权限审批
 func AppAccessDBApproval(ctx context.Context, userid uint64, req *pb.AppAccessDBApprovalRequest) error {
-	_, err := IsDBManager(ctx, userid, req.DbID)
-	if err != nil {
-		return err
-	}
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		_, err := IsDBManager(ctx, userid, req.DbID)
+		if err != nil {
+			return err
+		}
 
-	isNil, accessDB, err := table.GetAppAccessDB(ctx, req.Appid, req.DbID)
-	if err != nil {
-		return err
-	}
+		isNil, accessDB, err := table.GetAppAccessDB(ctx, req.Appid, req.DbID)
+		if err != nil {
+			return err
+		}
 
-	if isNil {
-		return errs.New(errs.RetWebNotFindAccessInfo, "not find access apply")
-	}
+		if isNil {
+			return errs.New(errs.RetWebNotFindAccessInfo, "not find access apply")
+		}
 
-	if accessDB.Status != sc.AuthStatusChecking {
-		return errs.New(errs.RetWebAccessStatusNotChecking,
-			"the status of application access to database is not under review")
-	}
+		if accessDB.Status == sc.AuthStatusNormal { // 续期审批
+			applicant, err := approveAccessRenewal(ctx, mc.AccessTypeDB, accessDB.Id, req.Status == mc.ApprovalAccess)
+			if err != nil {
+				return err
+			}
 
-	var update horm.Map
+			return notify(ctx, &Notification{
+				Event:     mc.NotifyEventResult,
+				Kind:      mc.NotifyKindAccessDBRenewal,
+				Applicant: applicant,
+				Operator:  userid,
+				Appid:     req.Appid,
+				AccessID:  accessDB.Id,
+				DbID:      req.DbID,
+				Approved:  req.Status == mc.ApprovalAccess,
+				Reason:    req.Reason,
+			}, []uint64{applicant})
+		}
 
-	if req.Status == mc.ApprovalAccess {
-		update = horm.Map{
-			"status": sc.AuthStatusNormal,
+		if accessDB.Status != sc.AuthStatusChecking {
+			return errs.New(errs.RetWebAccessStatusNotChecking,
+				"the status of application access to database is not under review")
 		}
-	} else {
-		update = horm.Map{
-			"status": sc.AuthStatusReject,
+
+		var update horm.Map
+
+		if req.Status == mc.ApprovalAccess {
+			err = activateAccessExpire(ctx, mc.AccessTypeDB, accessDB.Id)
+			if err != nil {
+				return err
+			}
+
+			update = horm.Map{
+				"status": sc.AuthStatusNormal,
+			}
+		} else {
+			update = horm.Map{
+				"status": sc.AuthStatusReject,
+			}
+		}
+
+		err = table.UpdateAccessDBByID(ctx, accessDB.Id, update)
+		if err != nil {
+			return err
 		}
+
+		return notify(ctx, &Notification{
+			Event:     mc.NotifyEventResult,
+			Kind:      mc.NotifyKindAccessDB,
+			Applicant: accessDB.ApplyUser,
+			Operator:  userid,
+			Appid:     req.Appid,
+			AccessID:  accessDB.Id,
+			DbID:      req.DbID,
+			Approved:  req.Status == mc.ApprovalAccess,
+			Reason:    req.Reason,
+		}, []uint64{accessDB.ApplyUser})
+	})
+}
+
+// AppAccessDBBatchApproval 申请权限批量审批，逐条校验权限并返回每条的审批结果
+func AppAccessDBBatchApproval(ctx context.Context,
+	userid uint64, req *pb.AppAccessDBBatchApprovalRequest) *pb.BatchApprovalResponse {
+	ret := pb.BatchApprovalResponse{Results: []*pb.BatchApprovalResult{}}
+
+	items := lo.UniqBy(req.Items, func(item *pb.AppAccessDBItem) pb.AppAccessDBItem {
+		return *item
+	})
+
+	for _, item := range items {
+		err := AppAccessDBApproval(ctx, userid, &pb.AppAccessDBApprovalRequest{
+			Appid:  item.Appid,
+			DbID:   item.DbID,
+			Status: req.Status,
+			Reason: req.Reason,
+		})
+
+		addBatchApprovalResult(&ret, &pb.BatchApprovalResult{Appid: item.Appid, DbID: item.DbID}, err)
 	}
 
-	return table.UpdateAccessDBByID(ctx, accessDB.Id, update)
+	return &ret
 }
 
 // AppAccessDBWithdraw 应用接入仓库撤销申请
 func AppAccessDBWithdraw(ctx context.Context, userid uint64, req *pb.AppAccessDBWithdrawRequest) error {
-	isNil, accessDB, err := table.GetAppAccessDB(ctx, req.Appid, req.DbID)
-	if err != nil {
-		return err
-	}
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		isNil, accessDB, err := table.GetAppAccessDB(ctx, req.Appid, req.DbID)
+		if err != nil {
+			return err
+		}
 
-	if isNil {
-		return errs.New(errs.RetWebNotFindAccessInfo, "not find access apply")
-	}
+		if isNil {
+			return errs.New(errs.RetWebNotFindAccessInfo, "not find access apply")
+		}
 
-	if accessDB.ApplyUser != userid {
-		return errs.New(errs.RetWebAccessPermissionDeny, "is not my access, can`t withdraw")
-	}
+		if accessDB.Status == sc.AuthStatusNormal { // 撤销续期申请
+			err = withdrawAccessRenewal(ctx, userid, mc.AccessTypeDB, accessDB.Id)
+			if err != nil {
+				return err
+			}
 
-	if accessDB.Status != sc.AuthStatusChecking {
-		return errs.New(errs.RetWebAccessStatusNotChecking,
-			"the status of application access to database is not under review")
-	}
+			return notify(ctx, &Notification{
+				Event:     mc.NotifyEventWithdraw,
+				Kind:      mc.NotifyKindAccessDBRenewal,
+				Applicant: userid,
+				Appid:     req.Appid,
+				AccessID:  accessDB.Id,
+				DbID:      req.DbID,
+				Reason:    req.Reason,
+			}, dbApprovers(ctx, req.DbID))
+		}
 
-	update := horm.Map{
-		"status": sc.AuthStatusCancel,
-	}
+		if accessDB.ApplyUser != userid {
+			return errs.New(errs.RetWebAccessPermissionDeny, "is not my access, can`t withdraw")
+		}
 
-	return table.UpdateAccessDBByID(ctx, accessDB.Id, update)
+		if accessDB.Status != sc.AuthStatusChecking {
+			return errs.New(errs.RetWebAccessStatusNotChecking,
+				"the status of application access to database is not under review")
+		}
+
+		update := horm.Map{
+			"status": sc.AuthStatusCancel,
+		}
+
+		err = table.UpdateAccessDBByID(ctx, accessDB.Id, update)
+		if err != nil {
+			return err
+		}
+
+		return notify(ctx, &Notification{
+			Event:     mc.NotifyEventWithdraw,
+			Kind:      mc.NotifyKindAccessDB,
+			Applicant: userid,
+			Appid:     req.Appid,
+			AccessID:  accessDB.Id,
+			DbID:      req.DbID,
+			Reason:    req.Reason,
+		}, dbApprovers(ctx, req.DbID))
+	})
 }
 
 // AppAccessDBUpdate 编辑仓库访问权限
@@ -309,6 +441,13 @@ func AppAccessDBOnOff(ctx context.Context, userid uint64, req *pb.AppAccessDBOnO
 		return errs.New(errs.RetWebNotFindAccessInfo, "not find access info")
 	}
 
+	if req.Status == sc.AuthStatusNormal {
+		err = checkAccessNotExpired(ctx, mc.AccessTypeDB, accessDB.Id)
+		if err != nil {
+			return err
+		}
+	}
+
 	update := horm.Map{
 		"status": req.Status,
 	}
@@ -335,6 +474,11 @@ func DBsAllAppAccessList(ctx context.Context, userid uint64,
 		}
 
 		if len(accessDBs) > 0 {
+			expires, err := getAccessExpireMap(ctx, mc.AccessTypeDB, GetAccessDBIds(accessDBs))
+			if err != nil {
+				return nil, err
+			}
+
 			var userIds, appids []uint64
 
 			for _, v := range accessDBs {
@@ -357,17 +501,21 @@ func DBsAllAppAccessList(ctx context.Context, userid uint64,
 			}
 
 			for _, v := range accessDBs {
+				expire := getAccessExpire(expires, v.Id)
 				ret.AppAccessDBs = append(ret.AppAccessDBs, &pb.AppAccessDB{
-					Id:        v.Id,
-					App:       GetAppBaseFromApp(userid, GetAppByAppid(apps, v.Appid), userMaps),
-					DB:        nil,
-					Root:      v.Root,
-					Op:        strings.Split(v.Op, ","),
-					Status:    v.Status,
-					ApplyUser: userMaps[v.ApplyUser],
-					Reason:    v.Reason,
-					CreatedAt: v.CreatedAt.Unix(),
-					UpdatedAt: v.UpdatedAt.Unix(),
+					Id:          v.Id,
+					App:         GetAppBaseFromApp(userid, GetAppByAppid(apps, v.Appid), userMaps),
+					DB:          nil,
+					Root:        v.Root,
+					Op:          strings.Split(v.Op, ","),
+					Status:      v.Status,
+					ApplyUser:   userMaps[v.ApplyUser],
+					Reason:      v.Reason,
+					ExpireType:  expire.ExpireType,
+					ExpireTime:  expire.ExpireTime,
+					RenewStatus: expire.RenewStatus,
+					CreatedAt:   v.CreatedAt.Unix(),
+					UpdatedAt:   v.UpdatedAt.Unix(),
 				})
 			}
 		}
@@ -402,6 +550,11 @@ func DBsAllAppAccessList(ctx context.Context, userid uint64,
 		}
 
 		if len(accessDBs) > 0 {
+			expires, err := getAccessExpireMap(ctx, mc.AccessTypeDB, GetAccessDBIds(accessDBs))
+			if err != nil {
+				return nil, err
+			}
+
 			var userIds, appids []uint64
 
 			for _, v := range accessDBs {
@@ -419,17 +572,21 @@ func DBsAllAppAccessList(ctx context.Context, userid uint64,
 			}
 
 			for _, v := range accessDBs {
+				expire := getAccessExpire(expires, v.Id)
 				ret.AppAccessDBs = append(ret.AppAccessDBs, &pb.AppAccessDB{
-					Id:        v.Id,
-					App:       GetAppBaseFromApp(userid, GetAppByAppid(apps, v.Appid), userMaps),
-					DB:        nil,
-					Root:      v.Root,
-					Op:        strings.Split(v.Op, ","),
-					Status:    v.Status,
-					ApplyUser: userMaps[v.ApplyUser],
-					Reason:    v.Reason,
-					CreatedAt: v.CreatedAt.Unix(),
-					UpdatedAt: v.UpdatedAt.Unix(),
+					Id:          v.Id,
+					App:         GetAppBaseFromApp(userid, GetAppByAppid(apps, v.Appid), userMaps),
+					DB:          nil,
+					Root:        v.Root,
+					Op:          strings.Split(v.Op, ","),
+					Status:      v.Status,
+					ApplyUser:   userMaps[v.ApplyUser],
+					Reason:      v.Reason,
+					ExpireType:  expire.ExpireType,
+					ExpireTime:  expire.ExpireTime,
+					RenewStatus: expire.RenewStatus,
+					CreatedAt:   v.CreatedAt.Unix(),
+					UpdatedAt:   v.UpdatedAt.Unix(),
 				})
 			}
 		}
@@ -459,6 +616,11 @@ func AppsAllDBAccessList(ctx context.Context, userid uint64,
 	}
 
 	if len(accessDBs) > 0 {
+		expires, err := getAccessExpireMap(ctx, mc.AccessTypeDB, GetAccessDBIds(accessDBs))
+		if err != nil {
+			return nil, err
+		}
+
 		var userIds []uint64
 		var dbids []int
 
@@ -478,18 +640,21 @@ func AppsAllDBAccessList(ctx context.Context, userid uint64,
 		}
 
 		for _, v := range accessDBs {
-
+			expire := getAccessExpire(expires, v.Id)
 			ret.AppAccessDBs = append(ret.AppAccessDBs, &pb.AppAccessDB{
-				Id:        v.Id,
-				App:       nil,
-				DB:        GetDBBase(GetDBByID(dbs, v.DB)),
-				Root:      v.Root,
-				Op:        strings.Split(v.Op, ","),
-				Status:    v.Status,
-				ApplyUser: userMaps[v.ApplyUser],
-				Reason:    v.Reason,
-				CreatedAt: v.CreatedAt.Unix(),
-				UpdatedAt: v.UpdatedAt.Unix(),
+				Id:          v.Id,
+				App:         nil,
+				DB:          GetDBBase(GetDBByID(dbs, v.DB)),
+				Root:        v.Root,
+				Op:          strings.Split(v.Op, ","),
+				Status:      v.Status,
+				ApplyUser:   userMaps[v.ApplyUser],
+				Reason:      v.Reason,
+				ExpireType:  expire.ExpireType,
+				ExpireTime:  expire.ExpireTime,
+				RenewStatus: expire.RenewStatus,
+				CreatedAt:   v.CreatedAt.Unix(),
+				UpdatedAt:   v.UpdatedAt.Unix(),
 			})
 		}
 	}
@@ -512,3 +677,12 @@ func GetAccessDBByAppidDBId(accessDBs []*st.TblAccessDB, appid uint64, dbId int)
 
 	return nil
 }
+
+func GetAccessDBIds(accessDBs []*st.TblAccessDB) []int {
+	ret := make([]int, len(accessDBs))
+	for k, v := range accessDBs {
+		ret[k] = v.Id
+	}
+
+	return ret
+}
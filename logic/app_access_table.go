@@ -26,6 +26,7 @@ import (
 	"github.com/horm-database/manage/model/table"
 	sc "github.com/horm-database/server/consts"
 	st "github.com/horm-database/server/model/table"
+	"github.com/samber/lo"
 )
 
 func TableSupportOps(ctx context.Context, userid uint64, tableID int) (*pb.SupportOpsResponse, error) {
@@ -176,63 +177,98 @@ func AppCanAccessTable(ctx context.Context, userid uint64,
 
 func AppApplyAccessTable(ctx context.Context, userid uint64,
 	req *pb.AppApplyAccessTableRequest) (*pb.AppApplyAccessResponse, error) {
-	isNil, _, err := table.GetTableByID(ctx, req.TableID)
-	if err != nil {
-		return nil, err
-	}
-
-	if isNil {
-		return nil, errs.New(errs.RetWebNotFindTable, "not find table")
-	}
-
-	_, err = IsAppManager(ctx, userid, req.Appid)
-	if err != nil {
-		return nil, err
-	}
-
-	isNil, accessTable, err := table.GetAppAccessTable(ctx, req.Appid, req.TableID)
-	if err != nil {
-		return nil, err
-	}
-
 	var ret = pb.AppApplyAccessResponse{}
 
-	if isNil {
-		data := st.TblAccessTable{
-			Appid:     req.Appid,
-			TableId:   req.TableID,
-			QueryAll:  req.QueryAll,
-			Op:        strings.Join(req.Op, ","),
-			Status:    sc.AuthStatusChecking,
-			ApplyUser: userid,
-			Reason:    req.Reason,
+	err := table.Transaction(ctx, func(ctx context.Context) error {
+		isNil, _, err := table.GetTableByID(ctx, req.TableID)
+		if err != nil {
+			return err
 		}
 
-		ret.AccessID, err = table.InsertAccessTable(ctx, &data)
+		if isNil {
+			return errs.New(errs.RetWebNotFindTable, "not find table")
+		}
+
+		_, err = IsAppManager(ctx, userid, req.Appid)
 		if err != nil {
-			return nil, err
+			return err
 		}
-	} else {
-		ret.AccessID = accessTable.Id
-		if accessTable.Status == sc.AuthStatusNormal {
-			return nil, errs.New(errs.RetWebAccessStatusNormal, "app already has access permission of table")
-		} else if accessTable.Status == sc.AuthStatusChecking {
-			return nil, errs.New(errs.RetWebAccessStatusChecking,
-				"the application's access to the database is under review")
+
+		isNil, accessTable, err := table.GetAppAccessTable(ctx, req.Appid, req.TableID)
+		if err != nil {
+			return err
 		}
 
-		update := horm.Map{
-			"query_all":  req.QueryAll,
-			"op":         strings.Join(req.Op, ","),
-			"status":     sc.AuthStatusChecking,
-			"apply_user": userid,
-			"reason":     req.Reason,
+		if isNil {
+			data := st.TblAccessTable{
+				Appid:     req.Appid,
+				TableId:   req.TableID,
+				QueryAll:  req.QueryAll,
+				Op:        strings.Join(req.Op, ","),
+				Status:    sc.AuthStatusChecking,
+				ApplyUser: userid,
+				Reason:    req.Reason,
+			}
+
+			ret.AccessID, err = table.InsertAccessTable(ctx, &data)
+			if err != nil {
+				return err
+			}
+		} else if accessTable.Status == sc.AuthStatusNormal { // 申请续期
+			ret.AccessID = accessTable.Id
+
+			err = applyAccessRenewal(ctx, userid, mc.AccessTypeTable, accessTable.Id, req.ExpireType, req.Reason)
+			if err != nil {
+				return err
+			}
+
+			return notify(ctx, &Notification{
+				Event:     mc.NotifyEventApply,
+				Kind:      mc.NotifyKindAccessTableRenewal,
+				Applicant: userid,
+				Appid:     req.Appid,
+				AccessID:  accessTable.Id,
+				TableID:   req.TableID,
+				Reason:    req.Reason,
+			}, tableApprovers(ctx, req.TableID))
+		} else {
+			ret.AccessID = accessTable.Id
+			if accessTable.Status == sc.AuthStatusChecking {
+				return errs.New(errs.RetWebAccessStatusChecking,
+					"the application's access to the database is under review")
+			}
+
+			update := horm.Map{
+				"query_all":  req.QueryAll,
+				"op":         strings.Join(req.Op, ","),
+				"status":     sc.AuthStatusChecking,
+				"apply_user": userid,
+				"reason":     req.Reason,
+			}
+
+			err = table.UpdateAccessTableByID(ctx, accessTable.Id, update)
+			if err != nil {
+				return err
+			}
 		}
 
-		err = table.UpdateAccessTableByID(ctx, accessTable.Id, update)
+		err = resetAccessExpire(ctx, mc.AccessTypeTable, ret.AccessID, req.ExpireType)
 		if err != nil {
-			return nil, err
+			return err
 		}
+
+		return notify(ctx, &Notification{
+			Event:     mc.NotifyEventApply,
+			Kind:      mc.NotifyKindAccessTable,
+			Applicant: userid,
+			Appid:     req.Appid,
+			AccessID:  ret.AccessID,
+			TableID:   req.TableID,
+			Reason:    req.Reason,
+		}, tableApprovers(ctx, req.TableID))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &ret, nil
@@ -240,65 +276,161 @@ func AppApplyAccessTable(ctx context.Context, userid uint64,
 
 // AppAccessTableApproval 申请权限审批
 func AppAccessTableApproval(ctx context.Context, userid uint64, req *pb.AppAccessTableApprovalRequest) error {
-	_, _, err := IsTableManager(ctx, userid, req.TableID)
-	if err != nil {
-		return err
-	}
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		_, _, err := IsTableManager(ctx, userid, req.TableID)
+		if err != nil {
+			return err
+		}
 
-	isNil, accessTable, err := table.GetAppAccessTable(ctx, req.Appid, req.TableID)
-	if err != nil {
-		return err
-	}
+		isNil, accessTable, err := table.GetAppAccessTable(ctx, req.Appid, req.TableID)
+		if err != nil {
+			return err
+		}
 
-	if isNil {
-		return errs.New(errs.RetWebNotFindAccessInfo, "not find access apply")
-	}
+		if isNil {
+			return errs.New(errs.RetWebNotFindAccessInfo, "not find access apply")
+		}
 
-	if accessTable.Status != sc.AuthStatusChecking {
-		return errs.New(errs.RetWebAccessStatusNotChecking,
-			"the status of application access to database is not under review")
-	}
+		if accessTable.Status == sc.AuthStatusNormal { // 续期审批
+			applicant, err := approveAccessRenewal(ctx, mc.AccessTypeTable, accessTable.Id, req.Status == mc.ApprovalAccess)
+			if err != nil {
+				return err
+			}
 
-	var update horm.Map
+			return notify(ctx, &Notification{
+				Event:     mc.NotifyEventResult,
+				Kind:      mc.NotifyKindAccessTableRenewal,
+				Applicant: applicant,
+				Operator:  userid,
+				Appid:     req.Appid,
+				AccessID:  accessTable.Id,
+				TableID:   req.TableID,
+				Approved:  req.Status == mc.ApprovalAccess,
+				Reason:    req.Reason,
+			}, []uint64{applicant})
+		}
 
-	if req.Status == mc.ApprovalAccess {
-		update = horm.Map{
-			"status": sc.AuthStatusNormal,
+		if accessTable.Status != sc.AuthStatusChecking {
+			return errs.New(errs.RetWebAccessStatusNotChecking,
+				"the status of application access to database is not under review")
 		}
-	} else {
-		update = horm.Map{
-			"status": sc.AuthStatusReject,
+
+		var update horm.Map
+
+		if req.Status == mc.ApprovalAccess {
+			err = activateAccessExpire(ctx, mc.AccessTypeTable, accessTable.Id)
+			if err != nil {
+				return err
+			}
+
+			update = horm.Map{
+				"status": sc.AuthStatusNormal,
+			}
+		} else {
+			update = horm.Map{
+				"status": sc.AuthStatusReject,
+			}
+		}
+
+		err = table.UpdateAccessTableByID(ctx, accessTable.Id, update)
+		if err != nil {
+			return err
 		}
+
+		return notify(ctx, &Notification{
+			Event:     mc.NotifyEventResult,
+			Kind:      mc.NotifyKindAccessTable,
+			Applicant: accessTable.ApplyUser,
+			Operator:  userid,
+			Appid:     req.Appid,
+			AccessID:  accessTable.Id,
+			TableID:   req.TableID,
+			Approved:  req.Status == mc.ApprovalAccess,
+			Reason:    req.Reason,
+		}, []uint64{accessTable.ApplyUser})
+	})
+}
+
+// AppAccessTableBatchApproval 申请权限批量审批，逐条校验权限并返回每条的审批结果
+func AppAccessTableBatchApproval(ctx context.Context,
+	userid uint64, req *pb.AppAccessTableBatchApprovalRequest) *pb.BatchApprovalResponse {
+	ret := pb.BatchApprovalResponse{Results: []*pb.BatchApprovalResult{}}
+
+	items := lo.UniqBy(req.Items, func(item *pb.AppAccessTableItem) pb.AppAccessTableItem {
+		return *item
+	})
+
+	for _, item := range items {
+		err := AppAccessTableApproval(ctx, userid, &pb.AppAccessTableApprovalRequest{
+			Appid:   item.Appid,
+			TableID: item.TableID,
+			Status:  req.Status,
+			Reason:  req.Reason,
+		})
+
+		addBatchApprovalResult(&ret, &pb.BatchApprovalResult{Appid: item.Appid, TableID: item.TableID}, err)
 	}
 
-	return table.UpdateAccessTableByID(ctx, accessTable.Id, update)
+	return &ret
 }
 
 // AppAccessTableWithdraw 应用接入表数据撤销申请
 func AppAccessTableWithdraw(ctx context.Context, userid uint64, req *pb.AppAccessTableWithdrawRequest) error {
-	isNil, accessTable, err := table.GetAppAccessTable(ctx, req.Appid, req.TableID)
-	if err != nil {
-		return err
-	}
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		isNil, accessTable, err := table.GetAppAccessTable(ctx, req.Appid, req.TableID)
+		if err != nil {
+			return err
+		}
 
-	if isNil {
-		return errs.New(errs.RetWebNotFindAccessInfo, "not find access apply")
-	}
+		if isNil {
+			return errs.New(errs.RetWebNotFindAccessInfo, "not find access apply")
+		}
 
-	if accessTable.ApplyUser != userid {
-		return errs.New(errs.RetWebAccessPermissionDeny, "is not my access, can`t withdraw")
-	}
+		if accessTable.Status == sc.AuthStatusNormal { // 撤销续期申请
+			err = withdrawAccessRenewal(ctx, userid, mc.AccessTypeTable, accessTable.Id)
+			if err != nil {
+				return err
+			}
 
-	if accessTable.Status != sc.AuthStatusChecking {
-		return errs.New(errs.RetWebAccessStatusNotChecking,
-			"the status of application access to table is not under review")
-	}
+			return notify(ctx, &Notification{
+				Event:     mc.NotifyEventWithdraw,
+				Kind:      mc.NotifyKindAccessTableRenewal,
+				Applicant: userid,
+				Appid:     req.Appid,
+				AccessID:  accessTable.Id,
+				TableID:   req.TableID,
+				Reason:    req.Reason,
+			}, tableApprovers(ctx, req.TableID))
+		}
 
-	update := horm.Map{
-		"status": sc.AuthStatusCancel,
-	}
+		if accessTable.ApplyUser != userid {
+			return errs.New(errs.RetWebAccessPermissionDeny, "is not my access, can`t withdraw")
+		}
 
-	return table.UpdateAccessTableByID(ctx, accessTable.Id, update)
+		if accessTable.Status != sc.AuthStatusChecking {
+			return errs.New(errs.RetWebAccessStatusNotChecking,
+				"the status of application access to table is not under review")
+		}
+
+		update := horm.Map{
+			"status": sc.AuthStatusCancel,
+		}
+
+		err = table.UpdateAccessTableByID(ctx, accessTable.Id, update)
+		if err != nil {
+			return err
+		}
+
+		return notify(ctx, &Notification{
+			Event:     mc.NotifyEventWithdraw,
+			Kind:      mc.NotifyKindAccessTable,
+			Applicant: userid,
+			Appid:     req.Appid,
+			AccessID:  accessTable.Id,
+			TableID:   req.TableID,
+			Reason:    req.Reason,
+		}, tableApprovers(ctx, req.TableID))
+	})
 }
 
 // AppAccessTableUpdate 编辑表数据访问权限
@@ -341,6 +473,13 @@ func AppAccessTableOnOff(ctx context.Context, userid uint64, req *pb.AppAccessTa
 		return errs.New(errs.RetWebNotFindAccessInfo, "not find access info")
 	}
 
+	if req.Status == sc.AuthStatusNormal {
+		err = checkAccessNotExpired(ctx, mc.AccessTypeTable, accessTable.Id)
+		if err != nil {
+			return err
+		}
+	}
+
 	update := horm.Map{
 		"status": req.Status,
 	}
@@ -367,6 +506,11 @@ func TablesAllAppAccessList(ctx context.Context, userid uint64,
 		}
 
 		if len(accessTables) > 0 {
+			expires, err := getAccessExpireMap(ctx, mc.AccessTypeTable, GetAccessTableIds(accessTables))
+			if err != nil {
+				return nil, err
+			}
+
 			var userIds, appids []uint64
 
 			for _, v := range accessTables {
@@ -389,17 +533,21 @@ func TablesAllAppAccessList(ctx context.Context, userid uint64,
 			}
 
 			for _, v := range accessTables {
+				expire := getAccessExpire(expires, v.Id)
 				ret.AppAccessTables = append(ret.AppAccessTables, &pb.AppAccessTable{
-					Id:        v.Id,
-					App:       GetAppBaseFromApp(userid, GetAppByAppid(apps, v.Appid), userMaps),
-					Table:     nil,
-					QueryAll:  v.QueryAll,
-					Op:        strings.Split(v.Op, ","),
-					Status:    v.Status,
-					ApplyUser: userMaps[v.ApplyUser],
-					Reason:    v.Reason,
-					CreatedAt: v.CreatedAt.Unix(),
-					UpdatedAt: v.UpdatedAt.Unix(),
+					Id:          v.Id,
+					App:         GetAppBaseFromApp(userid, GetAppByAppid(apps, v.Appid), userMaps),
+					Table:       nil,
+					QueryAll:    v.QueryAll,
+					Op:          strings.Split(v.Op, ","),
+					Status:      v.Status,
+					ApplyUser:   userMaps[v.ApplyUser],
+					Reason:      v.Reason,
+					ExpireType:  expire.ExpireType,
+					ExpireTime:  expire.ExpireTime,
+					RenewStatus: expire.RenewStatus,
+					CreatedAt:   v.CreatedAt.Unix(),
+					UpdatedAt:   v.UpdatedAt.Unix(),
 				})
 			}
 		}
@@ -434,6 +582,11 @@ func TablesAllAppAccessList(ctx context.Context, userid uint64,
 		}
 
 		if len(accessTables) > 0 {
+			expires, err := getAccessExpireMap(ctx, mc.AccessTypeTable, GetAccessTableIds(accessTables))
+			if err != nil {
+				return nil, err
+			}
+
 			var userIds, appids []uint64
 
 			for _, v := range accessTables {
@@ -451,17 +604,21 @@ func TablesAllAppAccessList(ctx context.Context, userid uint64,
 			}
 
 			for _, v := range accessTables {
+				expire := getAccessExpire(expires, v.Id)
 				ret.AppAccessTables = append(ret.AppAccessTables, &pb.AppAccessTable{
-					Id:        v.Id,
-					App:       GetAppBaseFromApp(userid, GetAppByAppid(apps, v.Appid), userMaps),
-					Table:     nil,
-					QueryAll:  v.QueryAll,
-					Op:        strings.Split(v.Op, ","),
-					Status:    v.Status,
-					ApplyUser: userMaps[v.ApplyUser],
-					Reason:    v.Reason,
-					CreatedAt: v.CreatedAt.Unix(),
-					UpdatedAt: v.UpdatedAt.Unix(),
+					Id:          v.Id,
+					App:         GetAppBaseFromApp(userid, GetAppByAppid(apps, v.Appid), userMaps),
+					Table:       nil,
+					QueryAll:    v.QueryAll,
+					Op:          strings.Split(v.Op, ","),
+					Status:      v.Status,
+					ApplyUser:   userMaps[v.ApplyUser],
+					Reason:      v.Reason,
+					ExpireType:  expire.ExpireType,
+					ExpireTime:  expire.ExpireTime,
+					RenewStatus: expire.RenewStatus,
+					CreatedAt:   v.CreatedAt.Unix(),
+					UpdatedAt:   v.UpdatedAt.Unix(),
 				})
 			}
 		}
@@ -491,6 +648,11 @@ func AppsAllTableAccessList(ctx context.Context, userid uint64,
 	}
 
 	if len(accessTables) > 0 {
+		expires, err := getAccessExpireMap(ctx, mc.AccessTypeTable, GetAccessTableIds(accessTables))
+		if err != nil {
+			return nil, err
+		}
+
 		var userIds []uint64
 		var tableIds []int
 
@@ -510,18 +672,21 @@ func AppsAllTableAccessList(ctx context.Context, userid uint64,
 		}
 
 		for _, v := range accessTables {
-
+			expire := getAccessExpire(expires, v.Id)
 			ret.AppAccessTables = append(ret.AppAccessTables, &pb.AppAccessTable{
-				Id:        v.Id,
-				App:       nil,
-				Table:     GetTableBase(GetTableByID(tables, v.TableId)),
-				QueryAll:  v.QueryAll,
-				Op:        strings.Split(v.Op, ","),
-				Status:    v.Status,
-				ApplyUser: userMaps[v.ApplyUser],
-				Reason:    v.Reason,
-				CreatedAt: v.CreatedAt.Unix(),
-				UpdatedAt: v.UpdatedAt.Unix(),
+				Id:          v.Id,
+				App:         nil,
+				Table:       GetTableBase(GetTableByID(tables, v.TableId)),
+				QueryAll:    v.QueryAll,
+				Op:          strings.Split(v.Op, ","),
+				Status:      v.Status,
+				ApplyUser:   userMaps[v.ApplyUser],
+				Reason:      v.Reason,
+				ExpireType:  expire.ExpireType,
+				ExpireTime:  expire.ExpireTime,
+				RenewStatus: expire.RenewStatus,
+				CreatedAt:   v.CreatedAt.Unix(),
+				UpdatedAt:   v.UpdatedAt.Unix(),
 			})
 		}
 	}
@@ -544,3 +709,12 @@ func GetAccessTableByAppidTableId(accessTables []*st.TblAccessTable, appid uint6
 
 	return nil
 }
+
+func GetAccessTableIds(accessTables []*st.TblAccessTable) []int {
+	ret := make([]int, len(accessTables))
+	for k, v := range accessTables {
+		ret[k] = v.Id
+	}
+
+	return ret
+}
@@ -0,0 +1,483 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/orm/obj"
+	sc "github.com/horm-database/server/consts"
+	st "github.com/horm-database/server/model/table"
+	"github.com/samber/lo"
+)
+
+// pendingApproval 待审批申请
+type pendingApproval struct {
+	kind      string
+	id        int
+	applicant uint64
+	applyTime int64
+
+	workspaceID int
+	productID   int
+	dbID        int
+	tableID     int
+
+	member      *table.TblProductMember
+	wsMember    *table.TblWorkspaceMember
+	accessDB    *st.TblAccessDB
+	accessTable *st.TblAccessTable
+	renewal     *table.TblAccessExpire // 应用接入权限续期申请
+}
+
+// approvalScope 用户可以审批的范围
+type approvalScope struct {
+	products map[int]*table.TblProduct
+	dbs      map[int]*obj.TblDB
+	tables   map[int]*obj.TblTable
+}
+
+// MyPendingApprovals 我可以审批的所有待审批申请，包括空间、产品成员申请以及应用接入仓库、表数据申请
+func MyPendingApprovals(ctx context.Context, userid uint64,
+	workspaceID int, req *pb.MyPendingApprovalsRequest) (*pb.MyPendingApprovalsResponse, error) {
+	scope, err := getApprovalScope(ctx, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	approvals, err := getPendingApprovals(ctx, userid, workspaceID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Kind != "" {
+		approvals = lo.Filter(approvals, func(v *pendingApproval, _ int) bool {
+			return v.kind == req.Kind
+		})
+	}
+
+	sort.SliceStable(approvals, func(i, j int) bool {
+		if approvals[i].applyTime == approvals[j].applyTime {
+			return approvals[i].id < approvals[j].id
+		}
+
+		if req.Sort == consts.ApprovalSortNewest {
+			return approvals[i].applyTime > approvals[j].applyTime
+		}
+		return approvals[i].applyTime < approvals[j].applyTime
+	})
+
+	ret := pb.MyPendingApprovalsResponse{
+		Total:     uint64(len(approvals)),
+		TotalPage: uint32(math.Ceil(float64(len(approvals)) / float64(req.Size))),
+		Page:      req.Page,
+		Size:      req.Size,
+		Approvals: []*pb.PendingApproval{},
+	}
+
+	start := (req.Page - 1) * req.Size
+	if start >= len(approvals) {
+		return &ret, nil
+	}
+
+	end := start + req.Size
+	if end > len(approvals) {
+		end = len(approvals)
+	}
+
+	ret.Approvals, err = buildPendingApprovals(ctx, userid, approvals[start:end], scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// getApprovalScope 用户管理的产品、仓库、表，与 GetUserProductRole、IsDBManager、IsTableManager 的判断一致：
+// 产品管理员可以审批产品成员申请及产品下所有仓库、表的接入申请，仓库管理员可以审批仓库及仓库下所有表的接入申请。
+func getApprovalScope(ctx context.Context, userid uint64) (*approvalScope, error) {
+	scope := approvalScope{
+		products: map[int]*table.TblProduct{},
+		dbs:      map[int]*obj.TblDB{},
+		tables:   map[int]*obj.TblTable{},
+	}
+
+	members, err := table.GetProductMembersByUser(ctx, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(members) > 0 {
+		memberMap := map[int]*table.TblProductMember{}
+		for _, member := range members {
+			memberMap[member.ProductID] = member
+		}
+
+		products, err := table.GetProductByIds(ctx, lo.Keys(memberMap))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, product := range products {
+			if GetProductRole(memberMap[product.Id], product) == consts.ProductRoleManager {
+				scope.products[product.Id] = product
+			}
+		}
+	}
+
+	dbs, err := table.GetProductsDBs(ctx, lo.Keys(scope.products))
+	if err != nil {
+		return nil, err
+	}
+
+	managerDBs, err := table.GetDBsByManager(ctx, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, db := range managerDBs {
+		if lo.IndexOf(GetUserIds(db.Manager), userid) != -1 {
+			dbs = append(dbs, db)
+		}
+	}
+
+	for _, db := range dbs {
+		scope.dbs[db.Id] = db
+	}
+
+	tables, err := table.GetDBsTables(ctx, lo.Keys(scope.dbs))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tables {
+		scope.tables[t.Id] = t
+	}
+
+	return &scope, nil
+}
+
+// getPendingApprovals 审批范围内的所有待审批申请
+func getPendingApprovals(ctx context.Context, userid uint64,
+	workspaceID int, scope *approvalScope) ([]*pendingApproval, error) {
+	ret := []*pendingApproval{}
+
+	myRole, _, err := GetUserWorkspaceRole(ctx, userid, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if myRole == consts.WorkspaceMemberManager {
+		wsMembers, err := table.GetWorkspaceMembersByStatus(ctx, workspaceID,
+			[]int8{consts.WorkspaceMemberStatusApproval, consts.WorkspaceMemberStatusRenewal})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range wsMembers {
+			kind := consts.NotifyKindWorkspaceJoin
+			if member.Status == consts.WorkspaceMemberStatusRenewal {
+				kind = consts.NotifyKindWorkspaceRenewal
+			}
+
+			ret = append(ret, &pendingApproval{
+				kind:        kind,
+				id:          member.Id,
+				applicant:   member.UserID,
+				applyTime:   member.UpdatedAt.Unix(),
+				workspaceID: member.WorkspaceID,
+				wsMember:    member,
+			})
+		}
+	}
+
+	members, err := table.GetProductsMembersByStatus(ctx, lo.Keys(scope.products), []int8{
+		consts.ProductMemberStatusApproval, consts.ProductMemberStatusRenewal, consts.ProductMemberStatusChangeRole})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		var kind string
+		switch member.Status {
+		case consts.ProductMemberStatusApproval:
+			kind = consts.NotifyKindProductJoin
+		case consts.ProductMemberStatusRenewal:
+			kind = consts.NotifyKindProductRenewal
+		default:
+			kind = consts.NotifyKindProductChangeRole
+		}
+
+		ret = append(ret, &pendingApproval{
+			kind:      kind,
+			id:        member.Id,
+			applicant: member.UserID,
+			applyTime: member.UpdatedAt.Unix(),
+			productID: member.ProductID,
+			member:    member,
+		})
+	}
+
+	accessDBs, err := table.GetAccessDBsByStatus(ctx, lo.Keys(scope.dbs), sc.AuthStatusChecking)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, accessDB := range accessDBs {
+		ret = append(ret, &pendingApproval{
+			kind:      consts.NotifyKindAccessDB,
+			id:        accessDB.Id,
+			applicant: accessDB.ApplyUser,
+			applyTime: accessDB.UpdatedAt.Unix(),
+			dbID:      accessDB.DB,
+			accessDB:  accessDB,
+		})
+	}
+
+	accessTables, err := table.GetAccessTablesByStatus(ctx, lo.Keys(scope.tables), sc.AuthStatusChecking)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, accessTable := range accessTables {
+		ret = append(ret, &pendingApproval{
+			kind:        consts.NotifyKindAccessTable,
+			id:          accessTable.Id,
+			applicant:   accessTable.ApplyUser,
+			applyTime:   accessTable.UpdatedAt.Unix(),
+			tableID:     accessTable.TableId,
+			dbID:        scope.tables[accessTable.TableId].DB,
+			accessTable: accessTable,
+		})
+	}
+
+	renewals, err := getPendingAccessRenewals(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ret, renewals...), nil
+}
+
+// getPendingAccessRenewals 审批范围内续期审批中的应用接入仓库、表数据权限
+func getPendingAccessRenewals(ctx context.Context, scope *approvalScope) ([]*pendingApproval, error) {
+	ret := []*pendingApproval{}
+
+	dbRenewals, err := getRenewingAccessExpireMap(ctx, consts.AccessTypeDB)
+	if err != nil {
+		return nil, err
+	}
+
+	accessDBs, err := table.GetAccessDBsByIds(ctx, lo.Keys(dbRenewals))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, accessDB := range accessDBs {
+		if accessDB.Status != sc.AuthStatusNormal || scope.dbs[accessDB.DB] == nil {
+			continue
+		}
+
+		renewal := dbRenewals[accessDB.Id]
+		ret = append(ret, &pendingApproval{
+			kind:      consts.NotifyKindAccessDBRenewal,
+			id:        accessDB.Id,
+			applicant: renewal.RenewUser,
+			applyTime: renewal.UpdatedAt.Unix(),
+			dbID:      accessDB.DB,
+			accessDB:  accessDB,
+			renewal:   renewal,
+		})
+	}
+
+	tableRenewals, err := getRenewingAccessExpireMap(ctx, consts.AccessTypeTable)
+	if err != nil {
+		return nil, err
+	}
+
+	accessTables, err := table.GetAccessTablesByIds(ctx, lo.Keys(tableRenewals))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, accessTable := range accessTables {
+		tableInfo := scope.tables[accessTable.TableId]
+		if accessTable.Status != sc.AuthStatusNormal || tableInfo == nil {
+			continue
+		}
+
+		renewal := tableRenewals[accessTable.Id]
+		ret = append(ret, &pendingApproval{
+			kind:        consts.NotifyKindAccessTableRenewal,
+			id:          accessTable.Id,
+			applicant:   renewal.RenewUser,
+			applyTime:   renewal.UpdatedAt.Unix(),
+			tableID:     accessTable.TableId,
+			dbID:        tableInfo.DB,
+			accessTable: accessTable,
+			renewal:     renewal,
+		})
+	}
+
+	return ret, nil
+}
+
+// getRenewingAccessExpireMap 续期审批中的权限记录，key 为权限记录id
+func getRenewingAccessExpireMap(ctx context.Context, accessType int8) (map[int]*table.TblAccessExpire, error) {
+	expires, err := table.GetRenewingAccessExpires(ctx, accessType)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[int]*table.TblAccessExpire, len(expires))
+	for _, expire := range expires {
+		ret[expire.AccessID] = expire
+	}
+
+	return ret, nil
+}
+
+// buildPendingApprovals 补充待审批申请的申请人、产品、仓库、表、应用信息
+func buildPendingApprovals(ctx context.Context, userid uint64,
+	approvals []*pendingApproval, scope *approvalScope) ([]*pb.PendingApproval, error) {
+	userIds := []uint64{}
+	appids := []uint64{}
+	accessDBIds := []int{}
+	accessTableIds := []int{}
+
+	for _, v := range approvals {
+		userIds = append(userIds, v.applicant)
+
+		switch {
+		case v.accessDB != nil:
+			appids = append(appids, v.accessDB.Appid)
+			accessDBIds = append(accessDBIds, v.accessDB.Id)
+		case v.accessTable != nil:
+			appids = append(appids, v.accessTable.Appid)
+			accessTableIds = append(accessTableIds, v.accessTable.Id)
+		}
+	}
+
+	dbExpires, err := getAccessExpireMap(ctx, consts.AccessTypeDB, accessDBIds)
+	if err != nil {
+		return nil, err
+	}
+
+	tableExpires, err := getAccessExpireMap(ctx, consts.AccessTypeTable, accessTableIds)
+	if err != nil {
+		return nil, err
+	}
+
+	apps := []*st.TblAppInfo{}
+	if len(appids) > 0 {
+		apps, err = table.GetAppListByAppids(ctx, lo.Uniq(appids))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, app := range apps {
+			userIds = append(userIds, GetUserIds(app.Creator, app.Manager)...)
+		}
+	}
+
+	userMaps, err := table.GetUserBasesMapByIds(ctx, lo.Uniq(userIds))
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*pb.PendingApproval, len(approvals))
+
+	for k, v := range approvals {
+		item := pb.PendingApproval{
+			Kind:        v.kind,
+			Id:          v.id,
+			Applicant:   userMaps[v.applicant],
+			ApplyTime:   v.applyTime,
+			WorkspaceID: v.workspaceID,
+			DB:          GetDBBase(scope.dbs[v.dbID]),
+			Table:       GetTableBase(scope.tables[v.tableID]),
+		}
+
+		switch {
+		case v.wsMember != nil:
+			item.ExpireType = v.wsMember.ExpireType
+		case v.member != nil:
+			item.Product = getProductBase(scope.products[v.productID])
+			item.Role = v.member.Role
+			item.ExpireType = v.member.ExpireType
+			if v.member.Status == consts.ProductMemberStatusChangeRole {
+				item.Role = v.member.ChangeRole
+			}
+		case v.accessDB != nil:
+			item.App = GetAppBaseFromApp(userid, GetAppByAppid(apps, v.accessDB.Appid), userMaps)
+			item.Reason = v.accessDB.Reason
+			item.Root = v.accessDB.Root
+			item.Op = strings.Split(v.accessDB.Op, ",")
+			item.ExpireType = getAccessExpire(dbExpires, v.accessDB.Id).ExpireType
+		case v.accessTable != nil:
+			item.App = GetAppBaseFromApp(userid, GetAppByAppid(apps, v.accessTable.Appid), userMaps)
+			item.Reason = v.accessTable.Reason
+			item.QueryAll = v.accessTable.QueryAll
+			item.Op = strings.Split(v.accessTable.Op, ",")
+			item.ExpireType = getAccessExpire(tableExpires, v.accessTable.Id).ExpireType
+		}
+
+		if v.renewal != nil {
+			item.Reason = v.renewal.RenewReason
+			item.ExpireType = v.renewal.RenewExpireType
+		}
+
+		ret[k] = &item
+	}
+
+	return ret, nil
+}
+
+func getProductBase(product *table.TblProduct) *pb.ProductBase {
+	if product == nil {
+		return nil
+	}
+
+	return &pb.ProductBase{
+		Id:        product.Id,
+		Name:      product.Name,
+		Intro:     product.Intro,
+		Status:    product.Status,
+		CreatedAt: product.CreatedAt.Unix(),
+	}
+}
+
+// addBatchApprovalResult 记录批量审批中单条申请的审批结果
+func addBatchApprovalResult(ret *pb.BatchApprovalResponse, result *pb.BatchApprovalResult, err error) {
+	if err != nil {
+		result.Code = errs.Code(err)
+		result.Msg = errs.Msg(err)
+		ret.Failed++
+	} else {
+		result.Success = true
+		ret.Success++
+	}
+
+	ret.Results = append(ret.Results, result)
+}
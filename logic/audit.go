@@ -0,0 +1,122 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+)
+
+// AuditLogList 审计日志列表，仅空间管理员可查看
+func AuditLogList(ctx context.Context, userid uint64,
+	workspaceID int, req *pb.AuditLogListRequest) (*pb.AuditLogListResponse, error) {
+	filter := table.AuditLogFilter{
+		UserID:     req.Userid,
+		Api:        req.Api,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+	}
+
+	return getAuditLogs(ctx, userid, workspaceID, &filter, req.Page, req.Size)
+}
+
+// EntityHistory 对象变更历史，仅空间管理员可查看
+func EntityHistory(ctx context.Context, userid uint64,
+	workspaceID int, req *pb.EntityHistoryRequest) (*pb.AuditLogListResponse, error) {
+	filter := table.AuditLogFilter{
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+	}
+
+	return getAuditLogs(ctx, userid, workspaceID, &filter, req.Page, req.Size)
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+func getAuditLogs(ctx context.Context, userid uint64, workspaceID int,
+	filter *table.AuditLogFilter, page, size int) (*pb.AuditLogListResponse, error) {
+	myRole, _, err := GetUserWorkspaceRole(ctx, userid, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if myRole != consts.WorkspaceMemberManager {
+		return nil, errs.New(errs.RetWebMemberNotManager, "not workspace manager")
+	}
+
+	filter.WorkspaceID = workspaceID
+
+	pageInfo, logs, err := table.GetAuditLogs(ctx, filter, page, size)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := pb.AuditLogListResponse{
+		Total:     pageInfo.Total,
+		TotalPage: pageInfo.TotalPage,
+		Page:      page,
+		Size:      size,
+		Logs:      []*pb.AuditLog{},
+	}
+
+	if len(logs) == 0 {
+		return &ret, nil
+	}
+
+	userIds := []uint64{}
+	for _, v := range logs {
+		if v.UserID > 0 {
+			userIds = append(userIds, v.UserID)
+		}
+	}
+
+	userMaps, err := table.GetUserBasesMapByIds(ctx, userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range logs {
+		diff := map[string]*pb.AuditChange{}
+		if v.Diff != "" {
+			err = json.Api.Unmarshal([]byte(v.Diff), &diff)
+			if err != nil {
+				log.Errorf(ctx, errs.ErrSystem, "unmarshal audit log [%d] diff error: %v", v.Id, err)
+			}
+		}
+
+		ret.Logs = append(ret.Logs, &pb.AuditLog{
+			Id:         v.Id,
+			Operator:   userMaps[v.UserID],
+			IP:         v.IP,
+			RequestID:  v.RequestID,
+			Api:        v.Api,
+			EntityType: v.EntityType,
+			EntityID:   v.EntityID,
+			Action:     v.Action,
+			Diff:       diff,
+			CreatedAt:  v.CreatedAt.Unix(),
+		})
+	}
+
+	return &ret, nil
+}
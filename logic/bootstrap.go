@@ -0,0 +1,165 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/types"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/auth"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	tb "github.com/horm-database/server/model/table"
+)
+
+// InitWorkspace 初始化部署：创建管理员账号，空间不存在时以管理员为创建者创建空间，
+// 可重复执行，已存在的账号和空间不会被修改，只会补齐管理员身份
+func InitWorkspace(ctx context.Context, workspace, name,
+	account, password, nickname string) (int, uint64, error) {
+	userid, err := createAdminUser(ctx, account, password, nickname)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	workspaceInfo, err := table.GetWorkspace(ctx, workspace)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if workspaceInfo == nil || workspaceInfo.Id == 0 {
+		_, err = CreateWorkspace(ctx, userid, &pb.CreateWorkspaceRequest{Workspace: workspace, Name: name})
+		if err != nil {
+			return 0, 0, err
+		}
+
+		workspaceInfo, err = table.GetWorkspace(ctx, workspace)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	err = addWorkspaceAdmin(ctx, workspaceInfo, userid)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return workspaceInfo.Id, userid, nil
+}
+
+// CreateAdmin 创建管理员账号（账号已存在时直接使用），并设为空间管理员
+func CreateAdmin(ctx context.Context, workspace, account, password, nickname string) (uint64, error) {
+	workspaceInfo, err := table.GetWorkspace(ctx, workspace)
+	if err != nil {
+		return 0, err
+	}
+
+	if workspaceInfo == nil || workspaceInfo.Id == 0 {
+		return 0, errs.Newf(errs.RetWebWorkspaceNotExists, "workspace [%s] not exists", workspace)
+	}
+
+	userid, err := createAdminUser(ctx, account, password, nickname)
+	if err != nil {
+		return 0, err
+	}
+
+	return userid, addWorkspaceAdmin(ctx, workspaceInfo, userid)
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// createAdminUser 创建账号，账号已存在时直接返回其 userid，不修改密码
+func createAdminUser(ctx context.Context, account, password, nickname string) (uint64, error) {
+	notFind, user, err := table.GetUserByAccount(ctx, account)
+	if err != nil {
+		return 0, err
+	}
+
+	if !notFind {
+		return user.Id, nil
+	}
+
+	if password == "" {
+		return 0, errs.Newf(errs.RetWebParamEmpty, "input param [password] is empty")
+	}
+
+	user.Id, err = GenerateUserid(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if nickname == "" {
+		nickname = account
+	}
+
+	user.Account = account
+	user.Nickname = nickname
+	user.Password, err = auth.HashPassword(password)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.Id, table.InsertUser(ctx, user)
+}
+
+// addWorkspaceAdmin 将用户设为空间管理员，并确保其为已加入的永久成员
+func addWorkspaceAdmin(ctx context.Context, workspace *tb.TblWorkspace, userid uint64) error {
+	if !IsManager(userid, workspace.Manager) {
+		managers := append(GetUserIds(workspace.Manager), userid)
+
+		err := table.UpdateWorkspaceByID(ctx, workspace.Id, horm.Map{"manager": types.JoinUint64(managers, ",")})
+		if err != nil {
+			return err
+		}
+	}
+
+	isNil, member, err := table.GetWorkspaceMemberByUser(ctx, workspace.Id, userid)
+	if err != nil {
+		return err
+	}
+
+	if isNil {
+		member := table.TblWorkspaceMember{
+			WorkspaceID: workspace.Id,
+			UserID:      userid,
+			Status:      consts.WorkspaceMemberStatusJoined,
+			JoinTime:    time.Now().Unix(),
+			ExpireType:  consts.ExpireTypePermanent,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+
+		_, err = table.InsertWorkspaceMember(ctx, &member)
+		return err
+	}
+
+	if member.Status == consts.WorkspaceMemberStatusJoined && member.ExpireTime == 0 {
+		return nil
+	}
+
+	update := horm.Map{
+		"status":      consts.WorkspaceMemberStatusJoined,
+		"join_time":   time.Now().Unix(),
+		"expire_type": consts.ExpireTypePermanent,
+		"expire_time": 0,
+		"remind_time": 0,
+		"out_time":    0,
+	}
+
+	return table.UpdateWorkspaceMemberByID(ctx, member.Id, update)
+}
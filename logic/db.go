@@ -62,7 +62,15 @@ func AddDB(ctx context.Context, userid uint64, req *pb.AddDBRequest) (*pb.AddDBR
 		UpdatedAt:       time.Now(),
 	}
 
-	id, err := table.AddDB(ctx, &data)
+	var id int
+	err = table.Transaction(ctx, func(ctx context.Context) (err error) {
+		id, err = table.AddDB(ctx, &data)
+		if err != nil {
+			return err
+		}
+
+		return RefreshSearchIndex(ctx, consts.SearchTypeDB, id)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +90,14 @@ func UpdateDBBase(ctx context.Context, userid uint64, req *pb.UpdateDBBaseReques
 		"desc":  req.Desc,
 	}
 
-	return table.UpdateDBByID(ctx, req.DBId, update)
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		err := table.UpdateDBByID(ctx, req.DBId, update)
+		if err != nil {
+			return err
+		}
+
+		return RefreshSearchIndex(ctx, consts.SearchTypeDB, req.DBId)
+	})
 }
 
 func MaintainDBManager(ctx context.Context, userid uint64, req *pb.MaintainDBManagerRequest) error {
@@ -114,7 +129,14 @@ func MaintainDBManager(ctx context.Context, userid uint64, req *pb.MaintainDBMan
 		"manager": types.JoinUint64(managerUids, ","),
 	}
 
-	return table.UpdateDBByID(ctx, req.DBId, update)
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		err := table.UpdateDBByID(ctx, req.DBId, update)
+		if err != nil {
+			return err
+		}
+
+		return RefreshSearchIndex(ctx, consts.SearchTypeDB, req.DBId)
+	})
 }
 
 func UpdateDBStatus(ctx context.Context, userid uint64, req *pb.UpdateDBStatusRequest) error {
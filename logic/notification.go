@@ -0,0 +1,114 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/model/table"
+)
+
+// NotificationList 我的站内信列表
+func NotificationList(ctx context.Context, userid uint64,
+	req *pb.NotificationListRequest) (*pb.NotificationListResponse, error) {
+	pageInfo, notifications, err := table.GetNotifications(ctx, userid, req.Status, req.Event, req.Page, req.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := pb.NotificationListResponse{
+		Total:         pageInfo.Total,
+		TotalPage:     pageInfo.TotalPage,
+		Page:          req.Page,
+		Size:          req.Size,
+		Notifications: []*pb.Notification{},
+	}
+
+	if len(notifications) == 0 {
+		return &ret, nil
+	}
+
+	details := make([]*Notification, len(notifications))
+	userIds := []uint64{}
+
+	for k, v := range notifications {
+		details[k] = &Notification{}
+
+		err = json.Api.Unmarshal([]byte(v.Detail), details[k])
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "unmarshal notification [%d] detail error: %v", v.Id, err)
+		}
+
+		userIds = append(userIds, details[k].Applicant, details[k].Operator)
+	}
+
+	userMaps, err := table.GetUserBasesMapByIds(ctx, GetUserIds(userIds))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range notifications {
+		detail := details[k]
+
+		ret.Notifications = append(ret.Notifications, &pb.Notification{
+			Id:        v.Id,
+			Event:     v.Event,
+			Kind:      v.Kind,
+			Title:     v.Title,
+			Applicant: userMaps[detail.Applicant],
+			Operator:  userMaps[detail.Operator],
+			Approved:  detail.Approved,
+			Reason:    detail.Reason,
+			Link: &pb.NotificationLink{
+				WorkspaceID: detail.WorkspaceID,
+				ProductID:   detail.ProductID,
+				Appid:       detail.Appid,
+				DbID:        detail.DbID,
+				TableID:     detail.TableID,
+				Role:        detail.Role,
+				MemberID:    detail.MemberID,
+				AccessID:    detail.AccessID,
+			},
+			Status:    v.Status,
+			ReadTime:  v.ReadTime,
+			CreatedAt: v.CreatedAt.Unix(),
+		})
+	}
+
+	return &ret, nil
+}
+
+// NotificationMarkRead 将我的站内信标为已读
+func NotificationMarkRead(ctx context.Context, userid uint64, req *pb.NotificationMarkReadRequest) error {
+	if req.All {
+		return table.MarkNotificationsRead(ctx, userid, nil)
+	}
+
+	return table.MarkNotificationsRead(ctx, userid, req.Ids)
+}
+
+// NotificationUnreadCount 我的未读站内信数
+func NotificationUnreadCount(ctx context.Context, userid uint64) (*pb.NotificationUnreadCountResponse, error) {
+	count, err := table.CountUnreadNotifications(ctx, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.NotificationUnreadCountResponse{Count: count}, nil
+}
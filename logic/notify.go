@@ -0,0 +1,397 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/mail"
+	"github.com/horm-database/manage/model/outbox"
+	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/manage/util"
+	"github.com/samber/lo"
+)
+
+// 审批通知写入接收人的站内信，并通过异步任务按接收人的通知设置发送邮件、推送 webhook。
+
+// TopicNotify 发送审批通知的异步任务，每个接收人的每个通知渠道一个任务，失败重试，通知至少送达一次
+const TopicNotify = "notify"
+
+// 审批通知邮件模板
+const (
+	templateApprovalRequest = "approval_request" // 新的申请/申请已撤销，通知审批人
+	templateApprovalResult  = "approval_result"  // 审批结果，通知申请人
+	templateExpire          = "expire"           // 应用接入权限、成员权限即将过期/已过期，通知应用管理员或成员本人
+)
+
+const notifyWebhookTimeout = 5 * time.Second
+
+// Notification 审批通知
+type Notification struct {
+	Event       string `json:"event"`                  // 事件 apply-新的申请 withdraw-申请已撤销 result-审批结果 expiring-权限即将过期 expired-权限已过期
+	Kind        string `json:"kind"`                   // 申请类型，如 workspace_join、access_db
+	Receiver    uint64 `json:"receiver"`               // 接收人
+	Applicant   uint64 `json:"applicant"`              // 申请人
+	Operator    uint64 `json:"operator,omitempty"`     // 审批人
+	WorkspaceID int    `json:"workspace_id,omitempty"` // 空间id
+	ProductID   int    `json:"product_id,omitempty"`   // 产品id
+	Appid       uint64 `json:"appid,omitempty"`        // 应用id
+	DbID        int    `json:"db_id,omitempty"`        // 仓库id
+	TableID     int    `json:"table_id,omitempty"`     // 表id
+	MemberID    int    `json:"member_id,omitempty"`    // 空间/产品成员记录id
+	AccessID    int    `json:"access_id,omitempty"`    // 应用接入仓库/表数据的权限记录id
+	InboxID     int    `json:"inbox_id,omitempty"`     // 站内信id
+	Role        int8   `json:"role,omitempty"`         // 申请的产品角色 2-开发者 3-运营者
+	Approved    bool   `json:"approved,omitempty"`     // 是否审批通过
+	Reason      string `json:"reason,omitempty"`       // 申请、撤销或拒绝理由
+	ExpireTime  int64  `json:"expire_time,omitempty"`  // 权限过期时间（到期通知）
+	Channel     string `json:"channel,omitempty"`      // 通知渠道 mail、webhook
+}
+
+// notifyWebhookBody 推送到用户 webhook 的通知内容
+type notifyWebhookBody struct {
+	Event   string        `json:"event"`
+	Kind    string        `json:"kind"`
+	Subject string        `json:"subject"`
+	Content string        `json:"content"` // html
+	Detail  *Notification `json:"detail"`
+}
+
+func init() {
+	outbox.Register(TopicNotify, func(ctx context.Context, payload []byte) error {
+		n := Notification{}
+		if err := json.Api.Unmarshal(payload, &n); err != nil {
+			return errs.Newf(errs.ErrServerDecode, "decode notification error: %v", err)
+		}
+
+		if n.Channel != consts.NotifyChannelMail && n.Channel != consts.NotifyChannelWebhook {
+			return errs.Newf(errs.ErrServerDecode, "notification channel [%s] is invalid", n.Channel)
+		}
+
+		return SendNotification(ctx, &n)
+	})
+}
+
+// GetNotifySetting 获取我的通知设置
+func GetNotifySetting(ctx context.Context, userid uint64) (*pb.NotifySetting, error) {
+	setting, err := getNotifySetting(ctx, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := pb.NotifySetting{
+		Email:      setting.Email != consts.NotifyEmailOff,
+		WebhookURL: setting.WebhookURL,
+		Locale:     setting.Locale,
+		MuteEvents: []string{},
+	}
+
+	if setting.MuteEvents != "" {
+		ret.MuteEvents = strings.Split(setting.MuteEvents, ",")
+	}
+
+	return &ret, nil
+}
+
+// UpdateNotifySetting 修改我的通知设置
+func UpdateNotifySetting(ctx context.Context, userid uint64, req *pb.NotifySetting) error {
+	var email int8 = consts.NotifyEmailOn
+	if !req.Email {
+		email = consts.NotifyEmailOff
+	}
+
+	isNil, setting, err := table.GetNotifySettingByUser(ctx, userid)
+	if err != nil {
+		return err
+	}
+
+	if isNil {
+		newSetting := table.TblNotifySetting{
+			UserID:     userid,
+			Email:      email,
+			WebhookURL: req.WebhookURL,
+			Locale:     req.Locale,
+			MuteEvents: strings.Join(lo.Uniq(req.MuteEvents), ","),
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}
+		return table.InsertNotifySetting(ctx, &newSetting)
+	}
+
+	update := horm.Map{
+		"email":       email,
+		"webhook_url": req.WebhookURL,
+		"locale":      req.Locale,
+		"mute_events": strings.Join(lo.Uniq(req.MuteEvents), ","),
+	}
+
+	return table.UpdateNotifySettingByID(ctx, setting.Id, update)
+}
+
+// SendNotification 按接收人的通知设置发送通知渠道 n.Channel，邮件渠道同时生成站内信标题
+func SendNotification(ctx context.Context, n *Notification) error {
+	sendMail := n.Channel == consts.NotifyChannelMail
+	sendWebhook := n.Channel == consts.NotifyChannelWebhook
+
+	setting, err := getNotifySetting(ctx, n.Receiver)
+	if err != nil {
+		return err
+	}
+
+	users, err := table.GetUserBasesMapByIds(ctx, []uint64{n.Receiver, n.Applicant, n.Operator})
+	if err != nil {
+		return err
+	}
+
+	receiver := users[n.Receiver]
+	if receiver == nil { // 用户已不存在
+		return nil
+	}
+
+	data, err := notificationData(ctx, n, users)
+	if err != nil {
+		return err
+	}
+
+	name := templateApprovalRequest
+	switch n.Event {
+	case consts.NotifyEventResult:
+		name = templateApprovalResult
+	case consts.NotifyEventExpiring, consts.NotifyEventExpired:
+		name = templateExpire
+	}
+
+	subject, body, err := mail.Render(name, setting.Locale, data)
+	if err != nil {
+		return err
+	}
+
+	if sendMail && n.InboxID != 0 {
+		err = table.UpdateNotificationByID(ctx, n.InboxID, horm.Map{"title": subject})
+		if err != nil {
+			return err
+		}
+	}
+
+	if lo.Contains(strings.Split(setting.MuteEvents, ","), n.Event) {
+		return nil
+	}
+
+	if sendMail && setting.Email != consts.NotifyEmailOff && strings.Contains(receiver.Account, "@") {
+		err = mail.SendMail(ctx, []string{receiver.Account}, nil, subject, body)
+		if err != nil {
+			return err
+		}
+	}
+
+	if sendWebhook && setting.WebhookURL != "" {
+		err = util.PostPublicJSON(ctx, setting.WebhookURL, &notifyWebhookBody{
+			Event:   n.Event,
+			Kind:    n.Kind,
+			Subject: subject,
+			Content: body,
+			Detail:  n,
+		}, nil, notifyWebhookTimeout)
+		if err != nil {
+			return errs.Newf(errs.ErrSystem, "post notification to webhook error: %v", err)
+		}
+	}
+
+	return nil
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// notify 为每个接收人写入站内信及异步通知任务，接收人去重，不通知操作人自己，须与业务写入在同一个事务（table.Transaction）中调用
+func notify(ctx context.Context, n *Notification, receivers []uint64) error {
+	self := n.Applicant
+	if n.Event == consts.NotifyEventResult {
+		self = n.Operator
+	}
+
+	for _, receiver := range lo.Uniq(receivers) {
+		if receiver == 0 || receiver == self {
+			continue
+		}
+
+		job := *n
+		job.Receiver = receiver
+
+		detail, err := json.Api.Marshal(&job)
+		if err != nil {
+			return errs.Newf(errs.ErrSystem, "marshal notification [%s:%s] error: %v", n.Kind, n.Event, err)
+		}
+
+		inbox := table.TblNotification{
+			UserID:    receiver,
+			Event:     n.Event,
+			Kind:      n.Kind,
+			Detail:    string(detail),
+			Status:    consts.NotificationStatusUnread,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		job.InboxID, err = table.InsertNotification(ctx, &inbox)
+		if err != nil {
+			return err
+		}
+
+		for _, channel := range []string{consts.NotifyChannelMail, consts.NotifyChannelWebhook} {
+			job.Channel = channel
+
+			err = outbox.Publish(ctx, TopicNotify, &job)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getNotifySetting 用户的通知设置，未设置时返回默认设置
+func getNotifySetting(ctx context.Context, userid uint64) (*table.TblNotifySetting, error) {
+	isNil, setting, err := table.GetNotifySettingByUser(ctx, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNil {
+		return &table.TblNotifySetting{UserID: userid, Email: consts.NotifyEmailOn}, nil
+	}
+
+	return setting, nil
+}
+
+// workspaceApprovers 空间申请的审批人，即空间管理员
+func workspaceApprovers(ctx context.Context, workspaceID int) []uint64 {
+	workspace, err := table.GetWorkspaceByID(ctx, workspaceID)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "get workspace [%d] approvers error: %v", workspaceID, err)
+		return nil
+	}
+
+	return GetUserIds(workspace.Manager)
+}
+
+// productApprovers 产品申请的审批人，即产品管理员
+func productApprovers(ctx context.Context, productID int) []uint64 {
+	isNil, product, err := table.GetProductByID(ctx, productID)
+	if err != nil || isNil {
+		log.Errorf(ctx, errs.ErrSystem, "get product [%d] approvers error: %v", productID, err)
+		return nil
+	}
+
+	return GetUserIds(product.Manager)
+}
+
+// dbApprovers 应用接入仓库的审批人，即仓库管理员与所属产品管理员
+func dbApprovers(ctx context.Context, dbID int) []uint64 {
+	db, managers, err := GetDBAndManagers(ctx, dbID)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "get db [%d] approvers error: %v", dbID, err)
+		return nil
+	}
+
+	return append(managers, productApprovers(ctx, db.ProductID)...)
+}
+
+// tableApprovers 应用接入表数据的审批人，同表所在仓库的审批人
+func tableApprovers(ctx context.Context, tableID int) []uint64 {
+	isNil, tableInfo, err := table.GetTableByID(ctx, tableID)
+	if err != nil || isNil {
+		log.Errorf(ctx, errs.ErrSystem, "get table [%d] approvers error: %v", tableID, err)
+		return nil
+	}
+
+	return dbApprovers(ctx, tableInfo.DB)
+}
+
+// notificationData 通知模板数据
+func notificationData(ctx context.Context, n *Notification,
+	users map[uint64]*pb.UsersBase) (map[string]interface{}, error) {
+	data := map[string]interface{}{
+		"Event":     n.Event,
+		"Kind":      n.Kind,
+		"Role":      n.Role,
+		"Approved":  n.Approved,
+		"Reason":    n.Reason,
+		"Applicant": nickname(users[n.Applicant], n.Applicant),
+		"Operator":  nickname(users[n.Operator], n.Operator),
+	}
+
+	switch n.Kind {
+	case consts.NotifyKindWorkspaceJoin, consts.NotifyKindWorkspaceRenewal:
+		workspace, err := table.GetWorkspaceByID(ctx, n.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		data["Target"] = workspace.Name
+	case consts.NotifyKindProductJoin, consts.NotifyKindProductRenewal, consts.NotifyKindProductChangeRole:
+		_, product, err := table.GetProductByID(ctx, n.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		data["Target"] = product.Name
+	case consts.NotifyKindAccessDB, consts.NotifyKindAccessDBRenewal:
+		_, db, err := table.GetDBByID(ctx, n.DbID)
+		if err != nil {
+			return nil, err
+		}
+		data["Target"] = db.Name
+	case consts.NotifyKindAccessTable, consts.NotifyKindAccessTableRenewal:
+		_, tableInfo, err := table.GetTableByID(ctx, n.TableID)
+		if err != nil {
+			return nil, err
+		}
+		data["Target"] = tableInfo.Name
+	}
+
+	if n.ExpireTime != 0 {
+		data["ExpireTime"] = time.Unix(n.ExpireTime, 0).Format("2006-01-02 15:04")
+	}
+
+	if n.Appid != 0 {
+		_, app, err := table.GetAppDetail(ctx, n.Appid)
+		if err != nil {
+			return nil, err
+		}
+		data["App"] = app.Name
+	}
+
+	return data, nil
+}
+
+func nickname(user *pb.UsersBase, userid uint64) string {
+	if user == nil {
+		return strconv.FormatUint(userid, 10)
+	}
+
+	if user.Nickname != "" {
+		return user.Nickname
+	}
+
+	return user.Account
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"sort"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/outbox"
+)
+
+// OutboxStats 异步任务队列统计，仅空间管理员可查看
+func OutboxStats(ctx context.Context, userid uint64, workspaceID int) (*pb.OutboxStatsResponse, error) {
+	myRole, _, err := GetUserWorkspaceRole(ctx, userid, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if myRole != consts.WorkspaceMemberManager {
+		return nil, errs.New(errs.RetWebMemberNotManager, "not workspace manager")
+	}
+
+	stats, err := outbox.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := pb.OutboxStatsResponse{
+		Pending:    stats.Pending,
+		Processing: stats.Processing,
+		Failed:     stats.Failed,
+		Topics:     []*pb.OutboxTopicStats{},
+	}
+
+	for topic, c := range stats.Topics {
+		ret.Topics = append(ret.Topics, &pb.OutboxTopicStats{
+			Topic:   topic,
+			Succeed: c.Succeed,
+			Failed:  c.Failed,
+			Dead:    c.Dead,
+		})
+	}
+
+	sort.Slice(ret.Topics, func(i, j int) bool {
+		return ret.Topics[i].Topic < ret.Topics[j].Topic
+	})
+
+	return &ret, nil
+}
+
+// OutboxRequeue 将失败的任务重新入队，仅空间管理员可操作
+func OutboxRequeue(ctx context.Context, userid uint64, workspaceID int,
+	req *pb.OutboxRequeueRequest) (*pb.OutboxRequeueResponse, error) {
+	myRole, _, err := GetUserWorkspaceRole(ctx, userid, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if myRole != consts.WorkspaceMemberManager {
+		return nil, errs.New(errs.RetWebMemberNotManager, "not workspace manager")
+	}
+
+	num, err := outbox.Requeue(ctx, req.Topic)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.OutboxRequeueResponse{Requeued: num}, nil
+}
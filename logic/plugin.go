@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/horm-database/common/errs"
 	"github.com/horm-database/common/types"
 	"github.com/horm-database/go-horm/horm"
 	"github.com/horm-database/manage/api/pb"
@@ -29,7 +30,8 @@ import (
 )
 
 // AddPlugin 新增插件
-func AddPlugin(ctx context.Context, userid uint64, req *pb.AddPluginRequest) (*pb.AddPluginResponse, error) {
+func AddPlugin(ctx context.Context, userid uint64,
+	workspaceID int, req *pb.AddPluginRequest) (*pb.AddPluginResponse, error) {
 	data := st.TblPlugin{
 		Name:    req.Name,
 		Intro:   req.Intro,
@@ -51,10 +53,20 @@ func AddPlugin(ctx context.Context, userid uint64, req *pb.AddPluginRequest) (*p
 		return nil, err
 	}
 
+	err = table.AddWorkspaceResource(ctx, workspaceID, consts.WorkspaceResourcePlugin, uint64(id))
+	if err != nil {
+		return nil, err
+	}
+
 	return &pb.AddPluginResponse{ID: id}, nil
 }
 
 func UpdatePlugin(ctx context.Context, userid uint64, req *pb.UpdatePluginRequest) error {
+	err := checkPluginExists(ctx, req.PluginID)
+	if err != nil {
+		return err
+	}
+
 	update := horm.Map{
 		"name":          req.Name,
 		"intro":         req.Intro,
@@ -67,6 +79,16 @@ func UpdatePlugin(ctx context.Context, userid uint64, req *pb.UpdatePluginReques
 
 // ReplacePluginConfig 新增/修改插件配置
 func ReplacePluginConfig(ctx context.Context, userid uint64, req *pb.ReplacePluginConfigRequest) error {
+	err := checkPluginExists(ctx, req.PluginID)
+	if err != nil {
+		return err
+	}
+
+	err = verifyPluginConfigDefine(req.Type, req.MoreInfo)
+	if err != nil {
+		return err
+	}
+
 	data := st.TblPluginConfig{
 		PluginID:      req.PluginID,
 		PluginVersion: req.PluginVersion,
@@ -84,6 +106,11 @@ func ReplacePluginConfig(ctx context.Context, userid uint64, req *pb.ReplacePlug
 
 // DelPluginConfig 删除插件配置
 func DelPluginConfig(ctx context.Context, userid uint64, req *pb.DelPluginConfigRequest) error {
+	err := checkPluginExists(ctx, req.PluginID)
+	if err != nil {
+		return err
+	}
+
 	return table.DelPluginConfigByKey(ctx, req.PluginID, req.PluginVersion, req.Key)
 }
 
@@ -161,6 +188,20 @@ func PluginConfigs(ctx context.Context, req *pb.PluginConfigsRequest) (*pb.Plugi
 
 ///////////////////////////////// function /////////////////////////////////////////
 
+// checkPluginExists 插件需为官方插件或当前空间下的插件
+func checkPluginExists(ctx context.Context, pluginID int) error {
+	isNil, _, err := table.GetPluginByID(ctx, pluginID)
+	if err != nil {
+		return err
+	}
+
+	if isNil {
+		return errs.Newf(errs.RetWebNotFindPlugin, "not find plugin %d", pluginID)
+	}
+
+	return nil
+}
+
 func PluginsToMap(plugins []*st.TblPlugin) map[int]*st.TblPlugin {
 	ret := map[int]*st.TblPlugin{}
 	for _, v := range plugins {
@@ -185,7 +226,7 @@ func GetDefaultScheduleConfig() *conf.ScheduleConfig {
 		Timeout:       1000,
 		RequestSource: []string{"api", "web"},
 		OpType:        []string{"read", "mod", "del"},
-		GrayScale:     100,
+		GrayScale:     consts.ScheduleDefaultGrayScale,
 		AppRule: &conf.AppRule{
 			ActType: sc.ActionTypeExec,
 			AppIDs:  []uint64{},
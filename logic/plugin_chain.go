@@ -0,0 +1,356 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/api/pb"
+	cc "github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/server/consts"
+	st "github.com/horm-database/server/model/table"
+	"github.com/samber/lo"
+)
+
+var pluginChainTypes = []int8{consts.PrePlugin, consts.PostPlugin, consts.DeferPlugin}
+
+// pluginChain 表插件链
+type pluginChain struct {
+	tableID int
+	version int                        // 插件链版本
+	plugins map[int]*st.TblTablePlugin // 表插件 id => 表插件
+	orders  map[int8][]int             // 插件类型 => 按执行顺序排列的表插件 id
+	issues  []*pb.PluginChainIssue     // 插件链存在的问题，orders 为修复后的顺序
+}
+
+// ReorderTablePlugins 按给定的完整顺序调整表插件链
+func ReorderTablePlugins(ctx context.Context, userid uint64,
+	req *pb.ReorderTablePluginsRequest) (*pb.ReorderTablePluginsResponse, error) {
+	_, _, err := IsTableManager(ctx, userid, req.TableId)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := loadPluginChain(ctx, req.TableId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ChainVersion != chain.version {
+		return nil, chain.conflictError()
+	}
+
+	orders := map[int8][]int{}
+	for typ, ids := range map[int8][]int{
+		consts.PrePlugin:   req.PrePlugins,
+		consts.PostPlugin:  req.PostPlugins,
+		consts.DeferPlugin: req.DeferPlugins,
+	} {
+		if ids == nil {
+			continue
+		}
+
+		if !samePluginIDs(chain.orders[typ], ids) {
+			return nil, errs.Newf(errs.RetWebParamEmpty,
+				"%s order must contain all and only the current %s of table %d",
+				PluginTypeDesc(typ), PluginTypeDesc(typ), req.TableId)
+		}
+
+		orders[typ] = ids
+	}
+
+	err = chain.save(ctx, orders, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReorderTablePluginsResponse{ChainVersion: chain.version}, nil
+}
+
+// RepairPluginChain 修复表插件链中的多头、孤儿、分叉与环
+func RepairPluginChain(ctx context.Context, userid uint64, tableID int) (*pb.RepairPluginChainResponse, error) {
+	_, _, err := IsTableManager(ctx, userid, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := getPluginChain(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = chain.save(ctx, chain.orders, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.RepairPluginChainResponse{ChainVersion: chain.version, Issues: chain.issues}, nil
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// getPluginChain 获取表插件链，插件链损坏时 issues 不为空，orders 为修复后的顺序
+func getPluginChain(ctx context.Context, tableID int) (*pluginChain, error) {
+	// 先取版本再取插件，保证保存时能检测到取插件之后的修改
+	version, err := table.GetTablePluginChainVersion(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	tablePlugins, err := table.GetTablePlugins(ctx, tableID, pluginChainTypes...)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := pluginChain{
+		tableID: tableID,
+		version: version,
+		plugins: map[int]*st.TblTablePlugin{},
+		orders:  map[int8][]int{},
+		issues:  []*pb.PluginChainIssue{},
+	}
+
+	typePlugins := map[int8][]*st.TblTablePlugin{}
+	for _, tablePlugin := range tablePlugins {
+		chain.plugins[tablePlugin.Id] = tablePlugin
+		typePlugins[tablePlugin.Type] = append(typePlugins[tablePlugin.Type], tablePlugin)
+	}
+
+	for _, typ := range pluginChainTypes {
+		order, issues := orderPluginChain(typ, typePlugins[typ])
+		chain.orders[typ] = order
+		chain.issues = append(chain.issues, issues...)
+	}
+
+	return &chain, nil
+}
+
+// loadPluginChain 获取表插件链，插件链损坏时返回错误
+func loadPluginChain(ctx context.Context, tableID int) (*pluginChain, error) {
+	chain, err := getPluginChain(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(chain.issues) > 0 {
+		var problems []string
+		for _, issue := range chain.issues {
+			problems = append(problems, PluginTypeDesc(issue.Type)+" "+issue.Issue)
+		}
+
+		return nil, errs.Newf(cc.RetWebPluginChainBroken,
+			"plugin chain of table %d is broken (%s), please repair plugin chain first",
+			tableID, strings.Join(problems, ", "))
+	}
+
+	return chain, nil
+}
+
+// save 按 orders 保存插件链（未包含的插件类型不变），并将 detach 中的插件移出插件链并下线。
+// 仅写入位置有变化的插件，游离插件（新写入的下线插件）加入插件链时同时上线，
+// updates 为随插件链一起修改的插件其他字段，所有变更在一条语句内完成，插件链已被修改时返回错误。
+func (c *pluginChain) save(ctx context.Context, orders map[int8][]int, updates map[int]horm.Map, detach ...int) error {
+	var links []*table.TablePluginLink
+
+	for _, typ := range pluginChainTypes {
+		ids, ok := orders[typ]
+		if !ok {
+			continue
+		}
+
+		for i, id := range ids {
+			front := 0
+			if i > 0 {
+				front = ids[i-1]
+			}
+
+			tablePlugin := c.plugins[id]
+			if tablePlugin == nil {
+				update := horm.Map{"status": cc.StatusOnline}
+				for k, v := range updates[id] {
+					update[k] = v
+				}
+
+				links = append(links, &table.TablePluginLink{ID: id, Type: typ, Front: front, Update: update})
+			} else if tablePlugin.Type != typ || tablePlugin.Front != front || updates[id] != nil {
+				links = append(links, &table.TablePluginLink{ID: id, Type: typ, Front: front, Update: updates[id]})
+			}
+		}
+	}
+
+	for _, id := range detach {
+		links = append(links, &table.TablePluginLink{ID: id, Type: cc.TablePluginTypeDetached,
+			Update: horm.Map{"status": cc.StatusOffline}})
+	}
+
+	if len(links) == 0 {
+		return nil
+	}
+
+	ok, err := table.UpdateTablePluginChain(ctx, c.tableID, c.version, links)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return c.conflictError()
+	}
+
+	c.version++
+	return nil
+}
+
+func (c *pluginChain) conflictError() error {
+	return errs.Newf(cc.RetWebPluginChainConflict,
+		"plugin chain of table %d has been modified, please refresh and retry", c.tableID)
+}
+
+// orderPluginChain 按 front 排列同一类型的表插件，返回执行顺序与插件链存在的问题。
+// 插件链损坏时，依次从各头插件、孤儿插件（按 id 升序）开始遍历，最后追加环上的插件，得到修复后的顺序。
+func orderPluginChain(typ int8, tablePlugins []*st.TblTablePlugin) ([]int, []*pb.PluginChainIssue) {
+	order := []int{}
+	issues := []*pb.PluginChainIssue{}
+
+	if len(tablePlugins) == 0 {
+		return order, issues
+	}
+
+	exists := map[int]bool{}
+	var ids []int
+	for _, tablePlugin := range tablePlugins {
+		exists[tablePlugin.Id] = true
+		ids = append(ids, tablePlugin.Id)
+	}
+	sort.Ints(ids)
+
+	backs := map[int][]int{} // front => 后继插件 id
+	var heads, orphans []int
+	for _, tablePlugin := range tablePlugins {
+		switch {
+		case tablePlugin.Front == 0:
+			heads = append(heads, tablePlugin.Id)
+		case !exists[tablePlugin.Front]:
+			orphans = append(orphans, tablePlugin.Id)
+		default:
+			backs[tablePlugin.Front] = append(backs[tablePlugin.Front], tablePlugin.Id)
+		}
+	}
+
+	sort.Ints(heads)
+	sort.Ints(orphans)
+
+	addIssue := func(issue string, pluginIDs []int) {
+		issues = append(issues, &pb.PluginChainIssue{Type: typ, Issue: issue, PluginIDs: pluginIDs})
+	}
+
+	if len(heads) > 1 {
+		addIssue(cc.PluginChainIssueMultiHead, heads)
+	}
+
+	if len(orphans) > 0 {
+		addIssue(cc.PluginChainIssueOrphan, orphans)
+	}
+
+	var forks []int
+	for _, id := range ids {
+		sort.Ints(backs[id])
+		if len(backs[id]) > 1 {
+			forks = append(forks, backs[id]...)
+		}
+	}
+
+	if len(forks) > 0 {
+		addIssue(cc.PluginChainIssueFork, forks)
+	}
+
+	visited := map[int]bool{}
+
+	var visit func(id int)
+	visit = func(id int) {
+		visited[id] = true
+		order = append(order, id)
+
+		for _, back := range backs[id] {
+			if !visited[back] {
+				visit(back)
+			}
+		}
+	}
+
+	for _, id := range append(heads, orphans...) {
+		visit(id)
+	}
+
+	var cycles []int
+	for _, id := range ids {
+		if !visited[id] {
+			cycles = append(cycles, id)
+		}
+	}
+
+	if len(cycles) > 0 {
+		addIssue(cc.PluginChainIssueCycle, cycles)
+
+		for _, id := range cycles {
+			if !visited[id] {
+				visit(id)
+			}
+		}
+	}
+
+	return order, issues
+}
+
+// samePluginIDs 判断 ids 是否恰好为 current 的一个排列
+func samePluginIDs(current, ids []int) bool {
+	if len(current) != len(ids) {
+		return false
+	}
+
+	count := map[int]int{}
+	for _, id := range current {
+		count[id]++
+	}
+
+	for _, id := range ids {
+		if count[id] == 0 {
+			return false
+		}
+		count[id]--
+	}
+
+	return true
+}
+
+// insertPluginID 将 id 插入到插件链 order 中 front 之后，front 为 0 时插入到链头
+func insertPluginID(order []int, front, id int) ([]int, error) {
+	pos := 0
+	if front != 0 {
+		pos = lo.IndexOf(order, front) + 1
+		if pos == 0 {
+			return nil, errs.Newf(errs.RetWebNotFindTablePlugin, "front plugin [%d] is not in the plugin chain", front)
+		}
+	}
+
+	ret := make([]int, 0, len(order)+1)
+	ret = append(ret, order[:pos]...)
+	ret = append(ret, id)
+	return append(ret, order[pos:]...), nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package logic
+
+import (
+	"reflect"
+	"testing"
+
+	cc "github.com/horm-database/manage/consts"
+	"github.com/horm-database/server/consts"
+	st "github.com/horm-database/server/model/table"
+)
+
+func TestOrderPluginChain(t *testing.T) {
+	// plugins 为 id => front
+	tests := []struct {
+		name    string
+		plugins [][2]int
+		order   []int
+		issues  map[string][]int
+	}{
+		{
+			name:   "empty",
+			order:  []int{},
+			issues: map[string][]int{},
+		},
+		{
+			name:    "linear",
+			plugins: [][2]int{{3, 0}, {1, 3}, {2, 1}},
+			order:   []int{3, 1, 2},
+			issues:  map[string][]int{},
+		},
+		{
+			name:    "multi head",
+			plugins: [][2]int{{2, 0}, {1, 0}, {3, 1}},
+			order:   []int{1, 3, 2},
+			issues:  map[string][]int{cc.PluginChainIssueMultiHead: {1, 2}},
+		},
+		{
+			name:    "orphan",
+			plugins: [][2]int{{1, 0}, {3, 9}, {2, 8}},
+			order:   []int{1, 2, 3},
+			issues:  map[string][]int{cc.PluginChainIssueOrphan: {2, 3}},
+		},
+		{
+			name:    "fork",
+			plugins: [][2]int{{1, 0}, {3, 1}, {2, 1}, {4, 3}},
+			order:   []int{1, 2, 3, 4},
+			issues:  map[string][]int{cc.PluginChainIssueFork: {2, 3}},
+		},
+		{
+			name:    "cycle",
+			plugins: [][2]int{{1, 0}, {2, 3}, {3, 2}},
+			order:   []int{1, 2, 3},
+			issues:  map[string][]int{cc.PluginChainIssueCycle: {2, 3}},
+		},
+		{
+			name:    "cycle without head",
+			plugins: [][2]int{{1, 2}, {2, 1}},
+			order:   []int{1, 2},
+			issues:  map[string][]int{cc.PluginChainIssueCycle: {1, 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tablePlugins []*st.TblTablePlugin
+			for _, v := range tt.plugins {
+				tablePlugins = append(tablePlugins, &st.TblTablePlugin{Id: v[0], Type: consts.PrePlugin, Front: v[1]})
+			}
+
+			order, issues := orderPluginChain(consts.PrePlugin, tablePlugins)
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("order = %v, want %v", order, tt.order)
+			}
+
+			got := map[string][]int{}
+			for _, issue := range issues {
+				if issue.Type != consts.PrePlugin {
+					t.Errorf("issue %s type = %d, want %d", issue.Issue, issue.Type, consts.PrePlugin)
+				}
+				got[issue.Issue] = issue.PluginIDs
+			}
+
+			if !reflect.DeepEqual(got, tt.issues) {
+				t.Errorf("issues = %v, want %v", got, tt.issues)
+			}
+		})
+	}
+}
+
+func TestSamePluginIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		current []int
+		ids     []int
+		want    bool
+	}{
+		{"both empty", nil, []int{}, true},
+		{"same order", []int{1, 2, 3}, []int{1, 2, 3}, true},
+		{"permutation", []int{1, 2, 3}, []int{3, 1, 2}, true},
+		{"missing", []int{1, 2, 3}, []int{1, 2}, false},
+		{"extra", []int{1, 2}, []int{1, 2, 3}, false},
+		{"unknown id", []int{1, 2, 3}, []int{1, 2, 4}, false},
+		{"duplicate", []int{1, 2}, []int{1, 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := samePluginIDs(tt.current, tt.ids); got != tt.want {
+				t.Errorf("samePluginIDs(%v, %v) = %v, want %v", tt.current, tt.ids, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertPluginID(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   []int
+		front   int
+		want    []int
+		wantErr bool
+	}{
+		{name: "empty chain", order: []int{}, front: 0, want: []int{9}},
+		{name: "head", order: []int{1, 2, 3}, front: 0, want: []int{9, 1, 2, 3}},
+		{name: "middle", order: []int{1, 2, 3}, front: 2, want: []int{1, 2, 9, 3}},
+		{name: "tail", order: []int{1, 2, 3}, front: 3, want: []int{1, 2, 3, 9}},
+		{name: "front not in chain", order: []int{1, 2, 3}, front: 4, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := append([]int{}, tt.order...)
+
+			got, err := insertPluginID(order, tt.front, 9)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("insertPluginID error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("insertPluginID = %v, want %v", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("input order modified to %v", order)
+			}
+		})
+	}
+}
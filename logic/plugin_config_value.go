@@ -0,0 +1,467 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	cj "github.com/horm-database/common/json"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	st "github.com/horm-database/server/model/table"
+	"github.com/samber/lo"
+)
+
+// 时间类型配置值的格式，与 server 解析插件配置一致
+const (
+	pluginConfigTimeLayout = "2006-01-02 15:04:05"
+	pluginConfigDateLayout = "2006-01-02"
+)
+
+// checkPluginConfigValues 按插件版本的配置定义校验表插件配置值，所有字段的错误一并返回
+func checkPluginConfigValues(ctx context.Context,
+	pluginID, pluginVersion int, values map[string]interface{}) error {
+	configs, err := table.GetPluginConfigs(ctx, pluginID, pluginVersion)
+	if err != nil {
+		return err
+	}
+
+	fieldErrs := verifyPluginConfigValues(configs, values)
+	if len(fieldErrs) > 0 {
+		return errs.Newf(errs.RetWebParamEmpty, "plugin config is invalid: %s", strings.Join(fieldErrs, "; "))
+	}
+
+	return nil
+}
+
+// verifyPluginConfigDefine 校验插件配置定义，more_info 须符合配置类型，否则无法校验配置值
+func verifyPluginConfigDefine(typ int8, moreInfo string) error {
+	if typ < consts.PluginConfigTypeBool || typ > consts.PluginConfigTypeMultiConf {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [type] is invalid")
+	}
+
+	if moreInfo == "" {
+		if typ == consts.PluginConfigTypeEnum || typ == consts.PluginConfigTypeMultiConf {
+			return errs.Newf(errs.RetWebParamEmpty, "input param [more_info] is empty")
+		}
+		return nil
+	}
+
+	var err error
+
+	switch typ {
+	case consts.PluginConfigTypeInt, consts.PluginConfigTypeUint, consts.PluginConfigTypeFloat:
+		_, _, err = parseMinMax(moreInfo)
+	case consts.PluginConfigTypeEnum:
+		_, err = parseEnumMoreInfo(moreInfo)
+	case consts.PluginConfigTypeTime:
+		_, err = parseTimeMoreInfo(moreInfo)
+	case consts.PluginConfigTypeArray, consts.PluginConfigTypeMap:
+		elem := pb.TypeMoreInfo{}
+		err = cj.Api.Unmarshal([]byte(moreInfo), &elem)
+		if err == nil && (elem.Type >= consts.PluginConfigTypeArray ||
+			(typ == consts.PluginConfigTypeArray && elem.Type == consts.PluginConfigTypeEnum)) {
+			err = fmt.Errorf("element type %d is not supported", elem.Type)
+		}
+
+		if err == nil {
+			err = verifyPluginConfigDefine(elem.Type, elem.MoreInfo)
+		}
+	case consts.PluginConfigTypeMultiConf:
+		var items []*pb.MultiConfMoreInfo
+		items, err = parseMultiConfMoreInfo(moreInfo)
+		for _, item := range items {
+			if err != nil {
+				break
+			}
+
+			if item.Key == "" || item.Type >= consts.PluginConfigTypeArray {
+				err = fmt.Errorf("multi-conf [%s] key or type %d is invalid", item.Key, item.Type)
+				break
+			}
+
+			err = verifyPluginConfigDefine(item.Type, item.MoreInfo)
+		}
+	}
+
+	if err != nil {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [more_info] is invalid: %v", err)
+	}
+
+	return nil
+}
+
+// verifyPluginConfigValues 校验配置值：必输项、未定义的配置、各配置值的类型与取值范围
+func verifyPluginConfigValues(configs []*st.TblPluginConfig, values map[string]interface{}) []string {
+	fieldErrs := []string{}
+	defined := map[string]bool{}
+
+	for _, conf := range configs {
+		defined[conf.Key] = true
+
+		value, ok := values[conf.Key]
+		if !ok || value == nil {
+			if conf.NotNull == consts.PluginConfigNotNull {
+				fieldErrs = append(fieldErrs, fmt.Sprintf("[%s] is required", conf.Key))
+			}
+			continue
+		}
+
+		fieldErrs = append(fieldErrs, verifyConfigValue(conf.Key, conf.Type, conf.MoreInfo, value)...)
+	}
+
+	for _, key := range sortedKeys(values) {
+		if !defined[key] {
+			fieldErrs = append(fieldErrs, fmt.Sprintf("[%s] is not defined by plugin", key))
+		}
+	}
+
+	return fieldErrs
+}
+
+// verifyConfigValue 校验单个配置值，field 为配置路径，例如 rules[0].name
+func verifyConfigValue(field string, typ int8, moreInfo string, value interface{}) []string {
+	fail := func(format string, args ...interface{}) []string {
+		return []string{fmt.Sprintf("[%s] ", field) + fmt.Sprintf(format, args...)}
+	}
+
+	switch typ {
+	case consts.PluginConfigTypeBool:
+		if _, ok := value.(bool); !ok {
+			return fail("must be bool")
+		}
+	case consts.PluginConfigTypeString:
+		if _, ok := value.(string); !ok {
+			return fail("must be string")
+		}
+	case consts.PluginConfigTypeInt, consts.PluginConfigTypeUint, consts.PluginConfigTypeFloat:
+		return verifyNumberValue(field, typ, moreInfo, value)
+	case consts.PluginConfigTypeEnum:
+		enum, err := parseEnumMoreInfo(moreInfo)
+		if err != nil {
+			return fail("enum options is invalid: %v", err)
+		}
+
+		options := []string{}
+		for _, option := range enum.Options {
+			options = append(options, option.Key)
+		}
+
+		choices := []interface{}{value}
+		if enum.Multiple {
+			arr, ok := value.([]interface{})
+			if !ok {
+				return fail("must be array of enum options")
+			}
+			choices = arr
+		}
+
+		for _, choice := range choices {
+			key, ok := choice.(string)
+			if !ok || lo.IndexOf(options, key) == -1 {
+				return fail("%v is not in enum options [%s]", choice, strings.Join(options, ","))
+			}
+		}
+	case consts.PluginConfigTypeTime:
+		timeType, err := parseTimeMoreInfo(moreInfo)
+		if err != nil {
+			return fail("time type is invalid: %v", err)
+		}
+
+		layout := pluginConfigTimeLayout
+		if timeType == consts.PluginConfigTimeTypeDate || timeType == consts.PluginConfigTimeTypeDateInterval {
+			layout = pluginConfigDateLayout
+		}
+
+		if timeType == consts.PluginConfigTimeTypeTimeInterval || timeType == consts.PluginConfigTimeTypeDateInterval {
+			arr, ok := value.([]interface{})
+			if !ok || len(arr) != 2 {
+				return fail("must be %s array with start and end", timeType)
+			}
+
+			times := make([]time.Time, len(arr))
+			for i, v := range arr {
+				str, ok := v.(string)
+				if !ok {
+					return fail("must be %s array with start and end", timeType)
+				}
+
+				times[i], err = time.Parse(layout, str)
+				if err != nil {
+					return fail("%s is not in format %s", str, layout)
+				}
+			}
+
+			if times[0].After(times[1]) {
+				return fail("start %v is after end %v", arr[0], arr[1])
+			}
+		} else {
+			str, ok := value.(string)
+			if !ok {
+				return fail("must be %s string", timeType)
+			}
+
+			if _, err = time.Parse(layout, str); err != nil {
+				return fail("%s is not in format %s", str, layout)
+			}
+		}
+	case consts.PluginConfigTypeArray:
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fail("must be array")
+		}
+
+		if moreInfo == "" {
+			return nil
+		}
+
+		elem := pb.TypeMoreInfo{}
+		if err := cj.Api.Unmarshal([]byte(moreInfo), &elem); err != nil {
+			return fail("element type is invalid: %v", err)
+		}
+
+		fieldErrs := []string{}
+		for i, v := range arr {
+			fieldErrs = append(fieldErrs,
+				verifyConfigValue(fmt.Sprintf("%s[%d]", field, i), elem.Type, elem.MoreInfo, v)...)
+		}
+		return fieldErrs
+	case consts.PluginConfigTypeMap:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("must be map")
+		}
+
+		if moreInfo == "" {
+			return nil
+		}
+
+		elem := pb.TypeMoreInfo{}
+		if err := cj.Api.Unmarshal([]byte(moreInfo), &elem); err != nil {
+			return fail("element type is invalid: %v", err)
+		}
+
+		fieldErrs := []string{}
+		for _, k := range sortedKeys(m) {
+			fieldErrs = append(fieldErrs, verifyConfigValue(field+"."+k, elem.Type, elem.MoreInfo, m[k])...)
+		}
+		return fieldErrs
+	case consts.PluginConfigTypeMultiConf:
+		items, err := parseMultiConfMoreInfo(moreInfo)
+		if err != nil {
+			return fail("multi-conf define is invalid: %v", err)
+		}
+
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fail("must be array of config")
+		}
+
+		fieldErrs := []string{}
+		for i, v := range arr {
+			itemField := fmt.Sprintf("%s[%d]", field, i)
+
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				fieldErrs = append(fieldErrs, fmt.Sprintf("[%s] must be config object", itemField))
+				continue
+			}
+
+			defined := map[string]bool{}
+			for _, item := range items {
+				defined[item.Key] = true
+				if itemValue, ok := m[item.Key]; ok && itemValue != nil {
+					fieldErrs = append(fieldErrs,
+						verifyConfigValue(itemField+"."+item.Key, item.Type, item.MoreInfo, itemValue)...)
+				}
+			}
+
+			for _, k := range sortedKeys(m) {
+				if !defined[k] {
+					fieldErrs = append(fieldErrs, fmt.Sprintf("[%s.%s] is not defined by plugin", itemField, k))
+				}
+			}
+		}
+		return fieldErrs
+	default:
+		return fail("config type %d is unknown", typ)
+	}
+
+	return nil
+}
+
+// verifyNumberValue 校验 int、uint、float 配置值及其最小最大值
+func verifyNumberValue(field string, typ int8, moreInfo string, value interface{}) []string {
+	str, ok := numberString(value)
+	if !ok {
+		return []string{fmt.Sprintf("[%s] must be number", field)}
+	}
+
+	var err error
+	switch typ {
+	case consts.PluginConfigTypeInt:
+		_, err = strconv.ParseInt(str, 10, 64)
+	case consts.PluginConfigTypeUint:
+		_, err = strconv.ParseUint(str, 10, 64)
+	}
+
+	if err != nil {
+		return []string{fmt.Sprintf("[%s] must be %s", field, pluginConfigTypeDesc(typ))}
+	}
+
+	min, max, err := parseMinMax(moreInfo)
+	if err != nil {
+		return []string{fmt.Sprintf("[%s] min/max is invalid: %v", field, err)}
+	}
+
+	number, _ := strconv.ParseFloat(str, 64)
+	if min != nil && number < *min {
+		return []string{fmt.Sprintf("[%s] must not be less than %v", field, *min)}
+	}
+
+	if max != nil && number > *max {
+		return []string{fmt.Sprintf("[%s] must not be greater than %v", field, *max)}
+	}
+
+	return nil
+}
+
+// numberString 数字转为字符串，配置值经 json 解析后可能为 json.Number 或 float64
+func numberString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), true
+	default:
+		return "", false
+	}
+}
+
+func parseMinMax(moreInfo string) (min, max *float64, err error) {
+	if moreInfo == "" {
+		return nil, nil, nil
+	}
+
+	minMax := pb.MinMaxMoreInfo{}
+	err = cj.Api.Unmarshal([]byte(moreInfo), &minMax)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parse := func(v interface{}) (*float64, error) {
+		if v == nil {
+			return nil, nil
+		}
+
+		str, ok := numberString(v)
+		if !ok {
+			return nil, fmt.Errorf("%v is not number", v)
+		}
+
+		f, err := strconv.ParseFloat(str, 64)
+		return &f, err
+	}
+
+	min, err = parse(minMax.Min)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	max, err = parse(minMax.Max)
+	return min, max, err
+}
+
+func parseEnumMoreInfo(moreInfo string) (*pb.EnumMoreInfo, error) {
+	enum := pb.EnumMoreInfo{}
+	err := cj.Api.Unmarshal([]byte(moreInfo), &enum)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(enum.Options) == 0 {
+		return nil, fmt.Errorf("enum options is empty")
+	}
+
+	return &enum, nil
+}
+
+// parseTimeMoreInfo 时间类型，more_info 为空时为 time
+func parseTimeMoreInfo(moreInfo string) (string, error) {
+	if moreInfo == "" {
+		return consts.PluginConfigTimeTypeTime, nil
+	}
+
+	timeInfo := pb.TimeMoreInfo{}
+	err := cj.Api.Unmarshal([]byte(moreInfo), &timeInfo)
+	if err != nil {
+		return "", err
+	}
+
+	switch timeInfo.Type {
+	case consts.PluginConfigTimeTypeTime, consts.PluginConfigTimeTypeDate,
+		consts.PluginConfigTimeTypeTimeInterval, consts.PluginConfigTimeTypeDateInterval:
+		return timeInfo.Type, nil
+	default:
+		return "", fmt.Errorf("time type [%s] is unknown", timeInfo.Type)
+	}
+}
+
+func parseMultiConfMoreInfo(moreInfo string) ([]*pb.MultiConfMoreInfo, error) {
+	items := []*pb.MultiConfMoreInfo{}
+	err := cj.Api.Unmarshal([]byte(moreInfo), &items)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("multi-conf items is empty")
+	}
+
+	return items, nil
+}
+
+func pluginConfigTypeDesc(typ int8) string {
+	switch typ {
+	case consts.PluginConfigTypeInt:
+		return "int"
+	case consts.PluginConfigTypeUint:
+		return "uint"
+	default:
+		return "float"
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
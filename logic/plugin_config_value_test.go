@@ -0,0 +1,186 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package logic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/horm-database/manage/consts"
+	st "github.com/horm-database/server/model/table"
+)
+
+func TestVerifyPluginConfigValues(t *testing.T) {
+	configs := []*st.TblPluginConfig{
+		{Key: "name", Type: consts.PluginConfigTypeString, NotNull: consts.PluginConfigNotNull},
+		{Key: "limit", Type: consts.PluginConfigTypeInt, NotNull: consts.PluginConfigNullable,
+			MoreInfo: `{"min":1,"max":100}`},
+	}
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		errs   []string
+	}{
+		{
+			name:   "valid",
+			values: map[string]interface{}{"name": "a", "limit": float64(10)},
+			errs:   []string{},
+		},
+		{
+			name:   "nullable omitted",
+			values: map[string]interface{}{"name": "a"},
+			errs:   []string{},
+		},
+		{
+			name:   "required missing",
+			values: map[string]interface{}{"limit": float64(10)},
+			errs:   []string{"[name] is required"},
+		},
+		{
+			name:   "required null",
+			values: map[string]interface{}{"name": nil},
+			errs:   []string{"[name] is required"},
+		},
+		{
+			name:   "undefined keys sorted",
+			values: map[string]interface{}{"name": "a", "z": 1, "b": 2},
+			errs:   []string{"[b] is not defined by plugin", "[z] is not defined by plugin"},
+		},
+		{
+			name:   "all errors",
+			values: map[string]interface{}{"limit": float64(0), "x": true},
+			errs: []string{
+				"[name] is required",
+				"[limit] must not be less than 1",
+				"[x] is not defined by plugin",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := verifyPluginConfigValues(configs, tt.values)
+			if !reflect.DeepEqual(got, tt.errs) {
+				t.Errorf("verifyPluginConfigValues() = %q, want %q", got, tt.errs)
+			}
+		})
+	}
+}
+
+func TestVerifyConfigValue(t *testing.T) {
+	enum := `{"options":[{"key":"a"},{"key":"b"}]}`
+	multiEnum := `{"multiple":true,"options":[{"key":"a"},{"key":"b"}]}`
+	multiConf := `[{"key":"host","type":2},{"key":"port","type":3,"more_info":"{\"min\":1,\"max\":65535}"}]`
+
+	tests := []struct {
+		name     string
+		typ      int8
+		moreInfo string
+		value    interface{}
+		errs     []string
+	}{
+		{name: "bool", typ: consts.PluginConfigTypeBool, value: true},
+		{name: "bool invalid", typ: consts.PluginConfigTypeBool, value: "true",
+			errs: []string{"[f] must be bool"}},
+		{name: "string invalid", typ: consts.PluginConfigTypeString, value: float64(1),
+			errs: []string{"[f] must be string"}},
+
+		{name: "int", typ: consts.PluginConfigTypeInt, moreInfo: `{"min":-5,"max":5}`, value: float64(-5)},
+		{name: "int fraction", typ: consts.PluginConfigTypeInt, value: 1.5,
+			errs: []string{"[f] must be int"}},
+		{name: "int below min", typ: consts.PluginConfigTypeInt, moreInfo: `{"min":-5,"max":5}`, value: float64(-6),
+			errs: []string{"[f] must not be less than -5"}},
+		{name: "float above max", typ: consts.PluginConfigTypeFloat, moreInfo: `{"max":1.5}`, value: 1.6,
+			errs: []string{"[f] must not be greater than 1.5"}},
+		{name: "uint negative", typ: consts.PluginConfigTypeUint, value: float64(-1),
+			errs: []string{"[f] must be uint"}},
+		{name: "number invalid", typ: consts.PluginConfigTypeFloat, value: "1",
+			errs: []string{"[f] must be number"}},
+
+		{name: "enum", typ: consts.PluginConfigTypeEnum, moreInfo: enum, value: "b"},
+		{name: "enum not option", typ: consts.PluginConfigTypeEnum, moreInfo: enum, value: "c",
+			errs: []string{"[f] c is not in enum options [a,b]"}},
+		{name: "enum single got array", typ: consts.PluginConfigTypeEnum, moreInfo: enum,
+			value: []interface{}{"a"}, errs: []string{"[f] [a] is not in enum options [a,b]"}},
+		{name: "enum multiple", typ: consts.PluginConfigTypeEnum, moreInfo: multiEnum,
+			value: []interface{}{"a", "b"}},
+		{name: "enum multiple not option", typ: consts.PluginConfigTypeEnum, moreInfo: multiEnum,
+			value: []interface{}{"a", "c"}, errs: []string{"[f] c is not in enum options [a,b]"}},
+		{name: "enum multiple got string", typ: consts.PluginConfigTypeEnum, moreInfo: multiEnum,
+			value: "a", errs: []string{"[f] must be array of enum options"}},
+
+		{name: "time", typ: consts.PluginConfigTypeTime, value: "2024-02-29 23:59:59"},
+		{name: "time layout", typ: consts.PluginConfigTypeTime, value: "2024-02-29",
+			errs: []string{"[f] 2024-02-29 is not in format 2006-01-02 15:04:05"}},
+		{name: "date", typ: consts.PluginConfigTypeTime, moreInfo: `{"type":"date"}`, value: "2024-02-29"},
+		{name: "date invalid day", typ: consts.PluginConfigTypeTime, moreInfo: `{"type":"date"}`,
+			value: "2023-02-29", errs: []string{"[f] 2023-02-29 is not in format 2006-01-02"}},
+		{name: "date not string", typ: consts.PluginConfigTypeTime, moreInfo: `{"type":"date"}`,
+			value: float64(20240229), errs: []string{"[f] must be date string"}},
+		{name: "time interval", typ: consts.PluginConfigTypeTime, moreInfo: `{"type":"time_interval"}`,
+			value: []interface{}{"2024-01-01 00:00:00", "2024-01-01 00:00:00"}},
+		{name: "time interval reversed", typ: consts.PluginConfigTypeTime, moreInfo: `{"type":"time_interval"}`,
+			value: []interface{}{"2024-01-01 00:00:01", "2024-01-01 00:00:00"},
+			errs:  []string{"[f] start 2024-01-01 00:00:01 is after end 2024-01-01 00:00:00"}},
+		{name: "date interval", typ: consts.PluginConfigTypeTime, moreInfo: `{"type":"date_interval"}`,
+			value: []interface{}{"2024-01-01", "2024-12-31"}},
+		{name: "date interval bad end", typ: consts.PluginConfigTypeTime, moreInfo: `{"type":"date_interval"}`,
+			value: []interface{}{"2024-01-01", "2024-13-01"},
+			errs:  []string{"[f] 2024-13-01 is not in format 2006-01-02"}},
+		{name: "date interval one element", typ: consts.PluginConfigTypeTime, moreInfo: `{"type":"date_interval"}`,
+			value: []interface{}{"2024-01-01"}, errs: []string{"[f] must be date_interval array with start and end"}},
+		{name: "time type unknown", typ: consts.PluginConfigTypeTime, moreInfo: `{"type":"week"}`, value: "1",
+			errs: []string{"[f] time type is invalid: time type [week] is unknown"}},
+
+		{name: "array elements", typ: consts.PluginConfigTypeArray, moreInfo: `{"type":3}`,
+			value: []interface{}{float64(1), "2", true},
+			errs:  []string{"[f[1]] must be number", "[f[2]] must be number"}},
+		{name: "map elements", typ: consts.PluginConfigTypeMap, moreInfo: `{"type":1}`,
+			value: map[string]interface{}{"b": "x", "a": true, "c": float64(0)},
+			errs:  []string{"[f.b] must be bool", "[f.c] must be bool"}},
+
+		{name: "multi-conf", typ: consts.PluginConfigTypeMultiConf, moreInfo: multiConf,
+			value: []interface{}{map[string]interface{}{"host": "a", "port": float64(80)}, map[string]interface{}{}}},
+		{name: "multi-conf nested errors", typ: consts.PluginConfigTypeMultiConf, moreInfo: multiConf,
+			value: []interface{}{
+				map[string]interface{}{"host": "a", "port": float64(0)},
+				map[string]interface{}{"host": float64(1), "user": "root"},
+				"a:80",
+			},
+			errs: []string{
+				"[f[0].port] must not be less than 1",
+				"[f[1].host] must be string",
+				"[f[1].user] is not defined by plugin",
+				"[f[2]] must be config object",
+			}},
+		{name: "multi-conf not array", typ: consts.PluginConfigTypeMultiConf, moreInfo: multiConf,
+			value: map[string]interface{}{}, errs: []string{"[f] must be array of config"}},
+
+		{name: "unknown type", typ: 99, value: "a", errs: []string{"[f] config type 99 is unknown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := verifyConfigValue("f", tt.typ, tt.moreInfo, tt.value)
+			if len(got) == 0 && len(tt.errs) == 0 {
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.errs) {
+				t.Errorf("verifyConfigValue() = %q, want %q", got, tt.errs)
+			}
+		})
+	}
+}
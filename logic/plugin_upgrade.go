@@ -0,0 +1,403 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/orm/obj"
+	st "github.com/horm-database/server/model/table"
+	"github.com/samber/lo"
+)
+
+// PluginUsage 插件在当前空间各表上的使用情况
+func PluginUsage(ctx context.Context, pluginID int) (*pb.PluginUsageResponse, error) {
+	isNil, _, err := table.GetPluginByID(ctx, pluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNil {
+		return nil, errs.Newf(errs.RetWebNotFindPlugin, "not find plugin %d", pluginID)
+	}
+
+	tablePlugins, tableMaps, err := getPluginUsages(ctx, pluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	deprecatedVersions, err := deprecatedPluginVersions(ctx, []int{pluginID})
+	if err != nil {
+		return nil, err
+	}
+
+	ret := pb.PluginUsageResponse{
+		Total:    len(tablePlugins),
+		Versions: []*pb.PluginVersionUsage{},
+		Usages:   []*pb.PluginUsage{},
+	}
+
+	versionUsages := map[int]*pb.PluginVersionUsage{}
+
+	for _, tablePlugin := range tablePlugins {
+		tableInfo := tableMaps[tablePlugin.TableId]
+		deprecated := deprecatedVersions[fmt.Sprintf("%d_%d", pluginID, tablePlugin.PluginVersion)] != nil
+
+		ret.Usages = append(ret.Usages, &pb.PluginUsage{
+			TablePluginID: tablePlugin.Id,
+			TableID:       tablePlugin.TableId,
+			TableName:     tableInfo.Name,
+			DB:            tableInfo.DB,
+			Type:          tablePlugin.Type,
+			PluginVersion: tablePlugin.PluginVersion,
+			Status:        tablePlugin.Status,
+			Deprecated:    deprecated,
+		})
+
+		versionUsage := versionUsages[tablePlugin.PluginVersion]
+		if versionUsage == nil {
+			versionUsage = &pb.PluginVersionUsage{Version: tablePlugin.PluginVersion, Deprecated: deprecated}
+			versionUsages[tablePlugin.PluginVersion] = versionUsage
+			ret.Versions = append(ret.Versions, versionUsage)
+		}
+		versionUsage.Count++
+	}
+
+	sort.Slice(ret.Versions, func(i, j int) bool {
+		return ret.Versions[i].Version > ret.Versions[j].Version
+	})
+
+	return &ret, nil
+}
+
+// UpgradePluginVersion 按灰度顺序将选定表上的插件升级到目标版本，并按 key 映射迁移插件配置。
+// 每张表的插件全部校验通过后才会写入，某张表失败时停止处理后续的表。
+func UpgradePluginVersion(ctx context.Context, userid uint64,
+	req *pb.UpgradePluginVersionRequest) (*pb.UpgradePluginVersionResponse, error) {
+	plugin, err := isPluginManager(ctx, userid, req.PluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FromVersion == req.ToVersion {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "input param [from_version] is invalid")
+	}
+
+	newConfigs, err := table.GetPluginConfigs(ctx, req.PluginID, req.ToVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkConfigKeyMapping(newConfigs, req.KeyMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	tablePlugins, tableMaps, err := getPluginUsages(ctx, req.PluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	tablePluginMaps := map[int][]*st.TblTablePlugin{}
+	for _, tablePlugin := range tablePlugins {
+		if req.FromVersion == 0 || tablePlugin.PluginVersion == req.FromVersion ||
+			tablePlugin.PluginVersion == req.ToVersion {
+			tablePluginMaps[tablePlugin.TableId] = append(tablePluginMaps[tablePlugin.TableId], tablePlugin)
+		}
+	}
+
+	for _, tableID := range req.TableIds {
+		if len(tablePluginMaps[tableID]) == 0 {
+			return nil, errs.Newf(errs.RetWebParamEmpty,
+				"table [%d] does not use plugin %s of the version to upgrade", tableID, plugin.Name)
+		}
+	}
+
+	ret := pb.UpgradePluginVersionResponse{
+		ToVersion: req.ToVersion,
+		DryRun:    req.DryRun,
+		Results:   []*pb.TablePluginUpgrade{},
+	}
+
+	processed, stopped := 0, false
+
+	for _, tableID := range lo.Uniq(req.TableIds) {
+		results := []*pb.TablePluginUpgrade{}
+		for _, tablePlugin := range tablePluginMaps[tableID] {
+			results = append(results, &pb.TablePluginUpgrade{
+				TablePluginID: tablePlugin.Id,
+				TableID:       tableID,
+				TableName:     tableMaps[tableID].Name,
+				Type:          tablePlugin.Type,
+				FromVersion:   tablePlugin.PluginVersion,
+				Status:        consts.PluginUpgradeStatusPending,
+				ConfigDiff:    []*pb.ConfigDiff{},
+			})
+		}
+		ret.Results = append(ret.Results, results...)
+
+		upToDate := lo.EveryBy(results, func(v *pb.TablePluginUpgrade) bool {
+			return v.FromVersion == req.ToVersion
+		})
+
+		if upToDate {
+			for _, result := range results {
+				result.Status = consts.PluginUpgradeStatusUpToDate
+			}
+			continue
+		}
+
+		if stopped || (req.BatchSize > 0 && processed >= req.BatchSize) {
+			ret.Pending++
+			continue
+		}
+
+		processed++
+
+		err = upgradeTablePlugins(ctx, userid, req, tableID, tablePluginMaps[tableID], newConfigs, results)
+		if err != nil {
+			ret.Failed++
+			stopped = !req.DryRun // 试运行时继续检查后续的表
+			for _, result := range results {
+				if result.Status == consts.PluginUpgradeStatusPending {
+					result.Status = consts.PluginUpgradeStatusFailed
+					result.Error = err.Error()
+				}
+			}
+			continue
+		}
+
+		if !req.DryRun {
+			ret.Upgraded++
+		}
+	}
+
+	return &ret, nil
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// getPluginUsages 获取当前空间内挂载了插件的表插件及其所属表
+func getPluginUsages(ctx context.Context,
+	pluginID int) ([]*st.TblTablePlugin, map[int]*obj.TblTable, error) {
+	tablePlugins, err := table.GetPluginTablePlugins(ctx, pluginID, pluginChainTypes...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tableIDs []int
+	for _, tablePlugin := range tablePlugins {
+		tableIDs = append(tableIDs, tablePlugin.TableId)
+	}
+
+	tables, err := table.GetTableByIds(ctx, lo.Uniq(tableIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tableMaps := table.TablesToMap(tables)
+
+	// 仅保留当前空间可见的表
+	tablePlugins = lo.Filter(tablePlugins, func(v *st.TblTablePlugin, _ int) bool {
+		return tableMaps[v.TableId] != nil
+	})
+
+	return tablePlugins, tableMaps, nil
+}
+
+// upgradeTablePlugins 升级一张表上的插件，所有表插件校验通过后在一个事务内写入
+func upgradeTablePlugins(ctx context.Context, userid uint64, req *pb.UpgradePluginVersionRequest, tableID int,
+	tablePlugins []*st.TblTablePlugin, newConfigs []*st.TblPluginConfig, results []*pb.TablePluginUpgrade) error {
+	_, _, err := IsTableManager(ctx, userid, tableID)
+	if err != nil {
+		return err
+	}
+
+	updates := map[int]horm.Map{}
+
+	for i, tablePlugin := range tablePlugins {
+		if tablePlugin.PluginVersion == req.ToVersion {
+			results[i].Status = consts.PluginUpgradeStatusUpToDate
+			continue
+		}
+
+		err = checkTablePlugin(ctx, req.PluginID, req.ToVersion, tablePlugin.Type)
+		if err != nil {
+			return err
+		}
+
+		values := map[string]interface{}{}
+		if tablePlugin.Config != "" {
+			err = json.Api.Unmarshal([]byte(tablePlugin.Config), &values)
+			if err != nil { // 无法迁移原配置，不能以空配置覆盖
+				return errs.Newf(errs.ErrServerDecode,
+					"table plugin [%d] config can not be decoded, please fix it first: %v", tablePlugin.Id, err)
+			}
+		}
+
+		newValues, diff := mapPluginConfigValues(values, newConfigs, req.KeyMapping)
+		results[i].ConfigDiff = diff
+
+		fieldErrs := verifyPluginConfigValues(newConfigs, newValues)
+		if len(fieldErrs) > 0 {
+			return errs.Newf(errs.RetWebParamEmpty, "table plugin [%d] config is invalid in version %d: %s",
+				tablePlugin.Id, req.ToVersion, strings.Join(fieldErrs, "; "))
+		}
+
+		updates[tablePlugin.Id] = horm.Map{
+			"plugin_version": req.ToVersion,
+			"config":         json.MarshalToString(newValues),
+		}
+	}
+
+	if req.DryRun {
+		for i, tablePlugin := range tablePlugins {
+			if _, ok := updates[tablePlugin.Id]; ok {
+				results[i].Status = consts.PluginUpgradeStatusReady
+			}
+		}
+		return nil
+	}
+
+	// 同一张表上的插件在一个事务内升级，任一写入失败则全部回滚
+	err = table.Transaction(ctx, func(ctx context.Context) error {
+		for _, tablePlugin := range tablePlugins {
+			update, ok := updates[tablePlugin.Id]
+			if !ok {
+				continue
+			}
+
+			ok, err := table.UpgradeTablePluginVersion(ctx, tablePlugin.Id, tablePlugin.PluginVersion, update)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				return errs.Newf(errs.RetWebParamEmpty,
+					"table plugin [%d] has been modified, please refresh and retry", tablePlugin.Id)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, tablePlugin := range tablePlugins {
+		if _, ok := updates[tablePlugin.Id]; ok {
+			results[i].Status = consts.PluginUpgradeStatusUpgraded
+		}
+	}
+
+	return nil
+}
+
+// checkConfigKeyMapping 映射的目标 key 需存在于新版本配置定义中，且不能有多个旧 key 映射到同一个新 key
+func checkConfigKeyMapping(newConfigs []*st.TblPluginConfig, keyMapping map[string]string) error {
+	newKeys := map[string]bool{}
+	for _, c := range newConfigs {
+		newKeys[c.Key] = true
+	}
+
+	sources := map[string]string{}
+	oldKeys := lo.Keys(keyMapping)
+	sort.Strings(oldKeys)
+
+	for _, oldKey := range oldKeys {
+		newKey := keyMapping[oldKey]
+		if newKey == "" {
+			continue
+		}
+
+		if !newKeys[newKey] {
+			return errs.Newf(errs.RetWebParamEmpty, "key_mapping [%s] target key [%s] is not defined", oldKey, newKey)
+		}
+
+		if sources[newKey] != "" {
+			return errs.Newf(errs.RetWebParamEmpty, "key_mapping [%s] and [%s] map to the same key [%s]",
+				sources[newKey], oldKey, newKey)
+		}
+
+		sources[newKey] = oldKey
+	}
+
+	return nil
+}
+
+// mapPluginConfigValues 将表插件配置按 key 映射迁移到新版本的配置定义，返回迁移后的配置与配置差异
+func mapPluginConfigValues(values map[string]interface{}, newConfigs []*st.TblPluginConfig,
+	keyMapping map[string]string) (map[string]interface{}, []*pb.ConfigDiff) {
+	sources := map[string]string{} // 新 key => 旧 key
+	for oldKey, newKey := range keyMapping {
+		if newKey != "" {
+			sources[newKey] = oldKey
+		}
+	}
+
+	ret := map[string]interface{}{}
+	diff := []*pb.ConfigDiff{}
+	moved := map[string]bool{}
+
+	for _, c := range newConfigs {
+		oldKey, renamed := sources[c.Key]
+		if !renamed {
+			if _, mapped := keyMapping[c.Key]; !mapped { // 同名旧 key 未被映射到别处
+				oldKey = c.Key
+			}
+		}
+
+		if value, ok := values[oldKey]; ok && oldKey != "" {
+			action := consts.ConfigDiffKeep
+			if renamed && oldKey != c.Key {
+				action = consts.ConfigDiffRename
+			}
+
+			ret[c.Key] = value
+			moved[oldKey] = true
+			diff = append(diff, &pb.ConfigDiff{
+				Key: c.Key, OldKey: oldKey, Action: action, OldValue: value, NewValue: value})
+			continue
+		}
+
+		if c.NotNull == consts.PluginConfigNotNull && c.Default != "" {
+			var value interface{}
+			if json.Api.Unmarshal([]byte(c.Default), &value) != nil {
+				value = c.Default // 默认值不是 json 时作为字符串
+			}
+
+			ret[c.Key] = value
+			diff = append(diff, &pb.ConfigDiff{Key: c.Key, Action: consts.ConfigDiffDefault, NewValue: value})
+		}
+	}
+
+	for _, oldKey := range sortedKeys(values) {
+		if !moved[oldKey] {
+			diff = append(diff, &pb.ConfigDiff{
+				Key: oldKey, OldKey: oldKey, Action: consts.ConfigDiffRemove, OldValue: values[oldKey]})
+		}
+	}
+
+	return ret, diff
+}
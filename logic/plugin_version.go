@@ -0,0 +1,290 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/common/types"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	st "github.com/horm-database/server/model/table"
+	"github.com/samber/lo"
+)
+
+// publishVersionRetry 发布版本时修改插件支持版本的最大尝试次数
+const publishVersionRetry = 3
+
+// PublishPluginVersion 发布插件新版本，版本号为当前最大版本加 1
+func PublishPluginVersion(ctx context.Context, userid uint64,
+	req *pb.PublishPluginVersionRequest) (*pb.PublishPluginVersionResponse, error) {
+	plugin, err := isPluginManager(ctx, userid, req.PluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	pluginVersions, err := table.GetPluginVersions(ctx, req.PluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	last := 0
+	for _, v := range types.SplitInt(plugin.Version, ",") {
+		last = lo.Max([]int{last, v})
+	}
+
+	for _, v := range pluginVersions {
+		last = lo.Max([]int{last, v.Version})
+	}
+
+	version := last + 1
+
+	pluginVersion := table.TblPluginVersion{
+		PluginID:  req.PluginID,
+		Version:   version,
+		Changelog: req.Changelog,
+		Status:    consts.PluginVersionStatusNormal,
+		Publisher: userid,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	_, err = table.AddPluginVersion(ctx, &pluginVersion)
+	if errs.Code(err) == 1062 {
+		return nil, errs.Newf(errs.RetWebParamEmpty,
+			"plugin %s version %d is being published by others, please retry", plugin.Name, version)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = publishPluginVersion(ctx, req.PluginID, last, version, req.CopyConfigs)
+	if err != nil {
+		// 发布失败时清理已复制的配置定义与发布记录，配置清理失败时保留发布记录，以免版本号被复用到残留的配置上
+		if e := table.DelPluginConfigs(ctx, req.PluginID, version); e != nil {
+			log.Errorf(ctx, errs.ErrSystem, "plugin [%d] clean configs of version %d error: %v", req.PluginID, version, e)
+		} else if e = table.DelPluginVersion(ctx, req.PluginID, version); e != nil {
+			log.Errorf(ctx, errs.ErrSystem, "plugin [%d] clean version %d error: %v", req.PluginID, version, e)
+		}
+		return nil, err
+	}
+
+	return &pb.PublishPluginVersionResponse{Version: version}, nil
+}
+
+// DeprecatePluginVersion 废弃插件版本，已挂载该版本的表不受影响，但不能再新挂载
+func DeprecatePluginVersion(ctx context.Context, userid uint64, req *pb.DeprecatePluginVersionRequest) error {
+	plugin, err := isPluginManager(ctx, userid, req.PluginID)
+	if err != nil {
+		return err
+	}
+
+	if lo.IndexOf(types.SplitInt(plugin.Version, ","), req.Version) == -1 {
+		return errs.Newf(errs.RetWebNotFindPlugin, "plugin %s has no version %d", plugin.Name, req.Version)
+	}
+
+	isNil, pluginVersion, err := table.GetPluginVersion(ctx, req.PluginID, req.Version)
+	if err != nil {
+		return err
+	}
+
+	if !isNil && pluginVersion.Status == consts.PluginVersionStatusDeprecated {
+		return nil
+	}
+
+	if isNil { // 发布记录之前已存在的版本
+		_, err = table.AddPluginVersion(ctx, &table.TblPluginVersion{
+			PluginID:        req.PluginID,
+			Version:         req.Version,
+			Status:          consts.PluginVersionStatusDeprecated,
+			DeprecateReason: req.Reason,
+			DeprecatedBy:    userid,
+			DeprecatedAt:    time.Now().Unix(),
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		})
+		return err
+	}
+
+	update := horm.Map{
+		"status":           consts.PluginVersionStatusDeprecated,
+		"deprecate_reason": req.Reason,
+		"deprecated_by":    userid,
+		"deprecated_at":    time.Now().Unix(),
+	}
+
+	return table.UpdatePluginVersion(ctx, req.PluginID, req.Version, update)
+}
+
+// PluginVersionList 插件版本列表
+func PluginVersionList(ctx context.Context, pluginID int) (*pb.PluginVersionListResponse, error) {
+	isNil, plugin, err := table.GetPluginByID(ctx, pluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNil {
+		return nil, errs.Newf(errs.RetWebNotFindPlugin, "not find plugin %d", pluginID)
+	}
+
+	pluginVersions, err := table.GetPluginVersions(ctx, pluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	pluginVersionMap := map[int]*table.TblPluginVersion{}
+	var userIds []uint64
+	for _, v := range pluginVersions {
+		pluginVersionMap[v.Version] = v
+		userIds = append(userIds, v.Publisher, v.DeprecatedBy)
+	}
+
+	userMaps, err := table.GetUserBasesMapByIds(ctx, userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := types.SplitInt(plugin.Version, ",")
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	ret := pb.PluginVersionListResponse{Versions: []*pb.PluginVersion{}}
+
+	for _, version := range versions {
+		item := pb.PluginVersion{Version: version, Status: consts.PluginVersionStatusNormal}
+
+		// 发布记录之前已存在的版本没有变更说明
+		if v := pluginVersionMap[version]; v != nil {
+			item.Changelog = v.Changelog
+			item.Status = v.Status
+			item.DeprecateReason = v.DeprecateReason
+			item.Publisher = userMaps[v.Publisher]
+			item.DeprecatedBy = userMaps[v.DeprecatedBy]
+			item.PublishedAt = v.CreatedAt.Unix()
+			item.DeprecatedAt = v.DeprecatedAt
+		}
+
+		ret.Versions = append(ret.Versions, &item)
+	}
+
+	return &ret, nil
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// publishPluginVersion 复制上一版本的配置定义，再将新版本加入插件支持的版本，插件被并发修改时重新获取后重试
+func publishPluginVersion(ctx context.Context, pluginID, last, version int, copyConfigs bool) error {
+	if copyConfigs && last > 0 {
+		pluginConfigs, err := table.GetPluginConfigs(ctx, pluginID, last)
+		if err != nil {
+			return err
+		}
+
+		for _, pluginConfig := range pluginConfigs {
+			pluginConfig.Id = 0
+			pluginConfig.PluginVersion = version
+
+			err = table.ReplacePluginConfig(ctx, pluginConfig)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := 0; i < publishVersionRetry; i++ {
+		isNil, plugin, err := table.GetPluginByID(ctx, pluginID)
+		if err != nil {
+			return err
+		}
+
+		if isNil {
+			return errs.Newf(errs.RetWebNotFindPlugin, "not find plugin %d", pluginID)
+		}
+
+		versions := append(types.SplitInt(plugin.Version, ","), version)
+
+		ok, err := table.UpdatePluginSupportVersion(ctx, pluginID, plugin.Version, joinInts(lo.Uniq(versions), ","))
+		if err != nil || ok {
+			return err
+		}
+	}
+
+	return errs.Newf(errs.RetWebParamEmpty, "plugin %d is being modified by others, please retry", pluginID)
+}
+
+// isPluginManager 用户需为插件管理员
+func isPluginManager(ctx context.Context, userid uint64, pluginID int) (*st.TblPlugin, error) {
+	isNil, plugin, err := table.GetPluginByID(ctx, pluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNil {
+		return nil, errs.Newf(errs.RetWebNotFindPlugin, "not find plugin %d", pluginID)
+	}
+
+	if lo.IndexOf(GetUserIds(plugin.Manager), userid) == -1 {
+		return nil, errs.Newf(errs.RetWebMemberNotManager, "user is not manager of plugin [%s]", plugin.Name)
+	}
+
+	return plugin, nil
+}
+
+// checkPluginVersionDeprecated 已废弃的插件版本不能再挂载到表上
+func checkPluginVersionDeprecated(ctx context.Context, plugin *st.TblPlugin, version int) error {
+	isNil, pluginVersion, err := table.GetPluginVersion(ctx, plugin.Id, version)
+	if err != nil {
+		return err
+	}
+
+	if !isNil && pluginVersion.Status == consts.PluginVersionStatusDeprecated {
+		return errs.Newf(errs.RetWebNotFindPlugin, "plugin %s version %d is deprecated", plugin.Name, version)
+	}
+
+	return nil
+}
+
+// deprecatedPluginVersions 获取插件已废弃的版本，key 为 plugin_id_version
+func deprecatedPluginVersions(ctx context.Context, pluginIDs []int) (map[string]*table.TblPluginVersion, error) {
+	pluginVersions, err := table.GetPluginVersionsByStatus(ctx,
+		lo.Uniq(pluginIDs), consts.PluginVersionStatusDeprecated)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string]*table.TblPluginVersion{}
+	for _, v := range pluginVersions {
+		ret[fmt.Sprintf("%d_%d", v.PluginID, v.Version)] = v
+	}
+
+	return ret, nil
+}
+
+func joinInts(s []int, sep string) string {
+	var ret []string
+	for _, v := range s {
+		ret = append(ret, strconv.Itoa(v))
+	}
+	return strings.Join(ret, sep)
+}
@@ -16,7 +16,6 @@ package logic
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/horm-database/common/errs"
@@ -29,95 +28,54 @@ import (
 	"github.com/samber/lo"
 )
 
-func AddProduct(ctx context.Context, userid uint64, req *pb.AddProductRequest) (*pb.AddProductResponse, error) {
+func AddProduct(ctx context.Context, userid uint64,
+	workspaceID int, req *pb.AddProductRequest) (*pb.AddProductResponse, error) {
 	if lo.IndexOf(req.Manager, userid) == -1 {
 		req.Manager = append(req.Manager, userid)
 	}
 
-	userMap, err := table.GetUserBasesMapByIds(ctx, req.Manager)
-	if err != nil {
-		return nil, err
-	}
-
 	product := table.TblProduct{
-		Name:    req.Name,
-		Intro:   req.Intro,
-		Creator: userid,
-		Manager: types.JoinUint64(req.Manager, ","),
-		Status:  consts.StatusOnline,
+		WorkspaceID: workspaceID,
+		Name:        req.Name,
+		Intro:       req.Intro,
+		Creator:     userid,
+		Manager:     types.JoinUint64(req.Manager, ","),
+		Status:      consts.StatusOnline,
 	}
 
-	id, err := table.AddProduct(ctx, &product)
+	var id int
+	err := table.Transaction(ctx, func(ctx context.Context) (err error) {
+		id, err = table.AddProduct(ctx, &product)
+		if err != nil {
+			return err
+		}
 
-	if errs.Code(err) == 1062 {
-		return nil, errs.Newf(errs.RetWebDuplicateProductName, "product name is duplicated")
-	}
+		member := table.TblProductMember{
+			ProductID:  id,
+			UserID:     userid,
+			Role:       consts.ProductRoleDeveloper,
+			Status:     consts.ProductMemberStatusJoined,
+			JoinTime:   time.Now().Unix(),
+			ExpireType: consts.ExpireTypePermanent,
+			ExpireTime: 0,
+		}
 
-	if err != nil {
-		return nil, err
-	}
+		_, err = table.InsertProductMember(ctx, &member)
+		if err != nil {
+			return err
+		}
+
+		return RefreshSearchIndex(ctx, consts.SearchTypeProduct, id)
+	})
 
-	member := table.TblProductMember{
-		ProductID:  id,
-		UserID:     userid,
-		Role:       consts.ProductRoleDeveloper,
-		Status:     consts.ProductMemberStatusJoined,
-		JoinTime:   time.Now().Unix(),
-		ExpireType: consts.ExpireTypePermanent,
-		ExpireTime: 0,
+	if errs.Code(err) == 1062 {
+		return nil, errs.Newf(errs.RetWebDuplicateProductName, "product name is duplicated")
 	}
 
-	_, err = table.InsertProductMember(ctx, &member)
 	if err != nil {
 		return nil, err
 	}
 
-	// 写入检索信息
-	searchs := []*table.TblSearchKeyword{}
-	searchs = append(searchs, &table.TblSearchKeyword{
-		Type:     consts.SearchTypeProduct,
-		Sid:      id,
-		SName:    req.Name,
-		Field:    "name",
-		SContent: req.Name})
-
-	if req.Intro != "" {
-		searchs = append(searchs, &table.TblSearchKeyword{
-			Type:     consts.SearchTypeProduct,
-			Sid:      id,
-			SName:    req.Name,
-			Field:    "intro",
-			SContent: req.Intro})
-	}
-
-	u, ok := userMap[userid]
-	if ok {
-		name := fmt.Sprintf("%s(%s)", u.Nickname, u.Account)
-		searchs = append(searchs, &table.TblSearchKeyword{
-			Type:     consts.SearchTypeProduct,
-			Sid:      id,
-			SName:    req.Name,
-			Field:    "creator",
-			SKey:     fmt.Sprint(userid),
-			SContent: name})
-	}
-
-	for _, uid := range req.Manager {
-		u, ok = userMap[uid]
-		if ok {
-			name := fmt.Sprintf("%s(%s)", u.Nickname, u.Account)
-			searchs = append(searchs, &table.TblSearchKeyword{
-				Type:     consts.SearchTypeProduct,
-				Sid:      id,
-				SName:    req.Name,
-				Field:    "manager",
-				SKey:     fmt.Sprint(userid),
-				SContent: name})
-		}
-	}
-
-	table.AddSearchKeywords(ctx, searchs)
-
 	return &pb.AddProductResponse{ID: id}, nil
 }
 
@@ -136,7 +94,14 @@ func UpdateProduct(ctx context.Context, userid uint64, req *pb.UpdateProductRequ
 		"intro": req.Intro,
 	}
 
-	return table.UpdateProductByID(ctx, req.ProductID, update)
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		err := table.UpdateProductByID(ctx, req.ProductID, update)
+		if err != nil {
+			return err
+		}
+
+		return RefreshSearchIndex(ctx, consts.SearchTypeProduct, req.ProductID)
+	})
 }
 
 func MaintainProductManager(ctx context.Context, userid uint64, req *pb.MaintainProductManagerRequest) error {
@@ -172,12 +137,14 @@ func MaintainProductManager(ctx context.Context, userid uint64, req *pb.Maintain
 		"manager": types.JoinUint64(managerUids, ","),
 	}
 
-	err = table.UpdateProductByID(ctx, req.ProductID, update)
-	if err != nil {
-		return err
-	}
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		err := table.UpdateProductByID(ctx, req.ProductID, update)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		return RefreshSearchIndex(ctx, consts.SearchTypeProduct, req.ProductID)
+	})
 }
 
 func UpdateProductStatus(ctx context.Context, userid uint64, req *pb.UpdateProductStatusRequest) error {
@@ -357,188 +324,251 @@ func ProductMemberList(ctx context.Context, userid uint64,
 
 // ProductJoinApply 申请加入产品 / 续期
 func ProductJoinApply(ctx context.Context, userid uint64, req *pb.ProductJoinApplyRequest) error {
-	isNil, member, err := table.GetProductMemberByUser(ctx, req.ProductID, userid)
-	if err != nil {
-		return err
-	}
-
-	if isNil { // 新成员申请加入产品
-		if req.Role != consts.ProductRoleDeveloper && req.Role != consts.ProductRoleOperator {
-			return errs.Newf(errs.RetWebParamEmpty, "input param [role] is invalid")
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		isNil, member, err := table.GetProductMemberByUser(ctx, req.ProductID, userid)
+		if err != nil {
+			return err
 		}
 
-		newMember := table.TblProductMember{
-			ProductID:  req.ProductID,
-			UserID:     userid,
-			Role:       req.Role,
-			Status:     consts.ProductMemberStatusApproval,
-			JoinTime:   time.Now().Unix(),
-			ExpireType: req.ExpireType,
-			CreatedAt:  time.Now(),
-			UpdatedAt:  time.Now(),
-		}
-		_, err = table.InsertProductMember(ctx, &newMember)
-		return err
-	} else if GetProductRole(member) == consts.ProductRoleNotJoin { // 重新申请加入产品
-		if member.Status == consts.ProductMemberStatusApproval ||
-			member.Status == consts.ProductMemberStatusRenewal {
-			return errs.Newf(errs.RetWebMemberUnderApproval, "under approval, please do not apply repeatedly")
-		}
-
-		if req.Role != consts.ProductRoleDeveloper && req.Role != consts.ProductRoleOperator {
-			return errs.Newf(errs.RetWebParamEmpty, "input param [role] is invalid")
-		}
-
-		replace := horm.Map{
-			"id":          member.Id,
-			"product_id":  member.ProductID,
-			"userid":      member.UserID,
-			"role":        req.Role,
-			"status":      consts.ProductMemberStatusApproval,
-			"join_time":   time.Now().Unix(),
-			"expire_type": req.ExpireType,
-			"expire_time": 0,
-			"out_time":    0,
-			"updated_at":  time.Now(),
-		}
-		return table.ReplaceProductMember(ctx, replace)
-	} else { // 申请续期
-		if member.ExpireTime == 0 || int64(member.ExpireTime)-time.Now().Unix() > 7*86400 { // 只有7天内过期的用户才允许续期
-			return errs.Newf(errs.RetWebIsMember, "user is already member of product")
-		} else {
+		if isNil { // 新成员申请加入产品
+			if req.Role != consts.ProductRoleDeveloper && req.Role != consts.ProductRoleOperator {
+				return errs.Newf(errs.RetWebParamEmpty, "input param [role] is invalid")
+			}
+
+			newMember := table.TblProductMember{
+				ProductID:  req.ProductID,
+				UserID:     userid,
+				Role:       req.Role,
+				Status:     consts.ProductMemberStatusApproval,
+				JoinTime:   time.Now().Unix(),
+				ExpireType: req.ExpireType,
+				CreatedAt:  time.Now(),
+				UpdatedAt:  time.Now(),
+			}
+			memberID, err := table.InsertProductMember(ctx, &newMember)
+			if err != nil {
+				return err
+			}
+
+			return notifyProductApply(ctx, userid, req.ProductID, memberID, consts.NotifyKindProductJoin, req.Role, req.Reason)
+		} else if GetProductRole(member) == consts.ProductRoleNotJoin { // 重新申请加入产品
 			if member.Status == consts.ProductMemberStatusApproval ||
 				member.Status == consts.ProductMemberStatusRenewal {
 				return errs.Newf(errs.RetWebMemberUnderApproval, "under approval, please do not apply repeatedly")
 			}
 
-			if req.Role != member.Role { // 续期不能改变角色
-				return errs.Newf(errs.RetWebParamEmpty, "renewal input param [role] must be empty")
+			if req.Role != consts.ProductRoleDeveloper && req.Role != consts.ProductRoleOperator {
+				return errs.Newf(errs.RetWebParamEmpty, "input param [role] is invalid")
 			}
 
-			update := horm.Map{
-				"status":      consts.ProductMemberStatusRenewal,
+			replace := horm.Map{
+				"id":          member.Id,
+				"product_id":  member.ProductID,
+				"userid":      member.UserID,
+				"role":        req.Role,
+				"status":      consts.ProductMemberStatusApproval,
+				"join_time":   time.Now().Unix(),
 				"expire_type": req.ExpireType,
+				"expire_time": 0,
 				"out_time":    0,
+				"updated_at":  time.Now(),
 			}
 
-			return table.UpdateProductMemberByID(ctx, member.Id, update)
+			err = table.ReplaceProductMember(ctx, replace)
+			if err != nil {
+				return err
+			}
+
+			return notifyProductApply(ctx, userid, req.ProductID, member.Id, consts.NotifyKindProductJoin, req.Role, req.Reason)
+		} else { // 申请续期
+			if member.ExpireTime == 0 || int64(member.ExpireTime)-time.Now().Unix() > consts.MemberRenewDays*86400 { // 只有7天内过期的用户才允许续期
+				return errs.Newf(errs.RetWebIsMember, "user is already member of product")
+			} else {
+				if member.Status == consts.ProductMemberStatusApproval ||
+					member.Status == consts.ProductMemberStatusRenewal {
+					return errs.Newf(errs.RetWebMemberUnderApproval, "under approval, please do not apply repeatedly")
+				}
+
+				if req.Role != member.Role { // 续期不能改变角色
+					return errs.Newf(errs.RetWebParamEmpty, "renewal input param [role] must be empty")
+				}
+
+				update := horm.Map{
+					"status":      consts.ProductMemberStatusRenewal,
+					"expire_type": req.ExpireType,
+					"out_time":    0,
+				}
+
+				err = table.UpdateProductMemberByID(ctx, member.Id, update)
+				if err != nil {
+					return err
+				}
+
+				return notifyProductApply(ctx, userid, req.ProductID, member.Id, consts.NotifyKindProductRenewal, member.Role, req.Reason)
+			}
 		}
-	}
+	})
 }
 
 // ProductApproval 产品权限审批
 func ProductApproval(ctx context.Context, userid uint64, req *pb.ProductApprovalRequest) error {
-	myRole, _, err := GetUserProductRole(ctx, userid, req.ProductID)
-	if err != nil {
-		return err
-	}
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		myRole, _, err := GetUserProductRole(ctx, userid, req.ProductID)
+		if err != nil {
+			return err
+		}
 
-	if myRole != consts.ProductRoleManager {
-		return errs.New(errs.RetWebMemberNotManager, "not product manager")
-	}
+		if myRole != consts.ProductRoleManager {
+			return errs.New(errs.RetWebMemberNotManager, "not product manager")
+		}
 
-	isNil, member, err := table.GetProductMemberByUser(ctx, req.ProductID, req.Userid)
-	if err != nil {
-		return err
-	}
+		isNil, member, err := table.GetProductMemberByUser(ctx, req.ProductID, req.Userid)
+		if err != nil {
+			return err
+		}
 
-	if isNil {
-		return errs.Newf(errs.RetWebIsNotApply, "user has not applied for product permissions")
-	}
+		if isNil {
+			return errs.Newf(errs.RetWebIsNotApply, "user has not applied for product permissions")
+		}
 
-	if member.Status != consts.ProductMemberStatusApproval && member.Status != consts.ProductMemberStatusRenewal {
-		return errs.Newf(errs.RetWebMemberNotUnderApproval, "user is not in approval status")
-	}
+		if member.Status != consts.ProductMemberStatusApproval && member.Status != consts.ProductMemberStatusRenewal {
+			return errs.Newf(errs.RetWebMemberNotUnderApproval, "user is not in approval status")
+		}
 
-	var update horm.Map
-	if req.Status == consts.ApprovalAccess {
-		update = horm.Map{
-			"status":      consts.ProductMemberStatusJoined,
-			"expire_time": GetExpireTime(int64(member.ExpireTime), member.ExpireType),
+		var update horm.Map
+		if req.Status == consts.ApprovalAccess {
+			update = horm.Map{
+				"status":      consts.ProductMemberStatusJoined,
+				"expire_time": GetExpireTime(int64(member.ExpireTime), member.ExpireType),
+				"remind_time": 0,
+			}
+
+			if member.Status == consts.ProductMemberStatusApproval {
+				update["join_time"] = time.Now().Unix()
+			}
+		} else {
+			update = horm.Map{
+				"status": consts.ProductMemberStatusReject,
+			}
 		}
 
-		if member.Status == consts.ProductMemberStatusApproval {
-			update["join_time"] = time.Now().Unix()
+		err = table.UpdateProductMemberByID(ctx, member.Id, update)
+		if err != nil {
+			return err
 		}
-	} else {
-		update = horm.Map{
-			"status": consts.ProductMemberStatusReject,
+
+		kind := consts.NotifyKindProductJoin
+		if member.Status == consts.ProductMemberStatusRenewal {
+			kind = consts.NotifyKindProductRenewal
 		}
+
+		return notifyProductResult(ctx, userid, member, kind, member.Role, req)
+	})
+}
+
+// ProductBatchApproval 产品权限批量审批，逐条校验权限并返回每条的审批结果
+func ProductBatchApproval(ctx context.Context, userid uint64, req *pb.ProductBatchApprovalRequest) *pb.BatchApprovalResponse {
+	ret := pb.BatchApprovalResponse{Results: []*pb.BatchApprovalResult{}}
+
+	for _, uid := range lo.Uniq(req.Userids) {
+		err := ProductApproval(ctx, userid, &pb.ProductApprovalRequest{
+			ProductID: req.ProductID,
+			Userid:    uid,
+			Status:    req.Status,
+			Reason:    req.Reason,
+		})
+
+		addBatchApprovalResult(&ret, &pb.BatchApprovalResult{Userid: uid}, err)
 	}
 
-	return table.UpdateProductMemberByID(ctx, member.Id, update)
+	return &ret
 }
 
 // ProductChangeRoleApply 申请变更角色
 func ProductChangeRoleApply(ctx context.Context, userid uint64, req *pb.ProductChangeRoleApplyRequest) error {
-	_, member, err := table.GetProductMemberByUser(ctx, req.ProductID, userid)
-	if err != nil {
-		return err
-	}
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		_, member, err := table.GetProductMemberByUser(ctx, req.ProductID, userid)
+		if err != nil {
+			return err
+		}
 
-	role := GetProductRole(member)
-	if role == consts.ProductRoleNotJoin {
-		return errs.Newf(errs.RetWebIsNotMember, "user is not member of product")
-	}
+		role := GetProductRole(member)
+		if role == consts.ProductRoleNotJoin {
+			return errs.Newf(errs.RetWebIsNotMember, "user is not member of product")
+		}
 
-	if role == consts.ProductRoleExpired {
-		return errs.Newf(errs.RetWebMemberExpired, "product member permission has expired, please renewal first")
-	}
+		if role == consts.ProductRoleExpired {
+			return errs.Newf(errs.RetWebMemberExpired, "product member permission has expired, please renewal first")
+		}
 
-	if member.Status == consts.ProductMemberStatusRenewal {
-		return errs.Newf(errs.RetWebMemberUnderApproval, "please complete the renewal approval first")
-	}
+		if member.Status == consts.ProductMemberStatusRenewal {
+			return errs.Newf(errs.RetWebMemberUnderApproval, "please complete the renewal approval first")
+		}
 
-	if member.Status != consts.ProductMemberStatusJoined {
-		return errs.Newf(errs.RetWebIsNotMember, "user is not member of product")
-	}
+		if member.Status != consts.ProductMemberStatusJoined {
+			return errs.Newf(errs.RetWebIsNotMember, "user is not member of product")
+		}
 
-	if req.Role == member.Role {
-		return nil
-	}
+		if req.Role == member.Role {
+			return nil
+		}
 
-	update := horm.Map{
-		"status":      consts.ProductMemberStatusChangeRole,
-		"change_role": req.Role,
-	}
+		update := horm.Map{
+			"status":      consts.ProductMemberStatusChangeRole,
+			"change_role": req.Role,
+		}
 
-	return table.UpdateProductMemberByID(ctx, member.Id, update)
+		err = table.UpdateProductMemberByID(ctx, member.Id, update)
+		if err != nil {
+			return err
+		}
+
+		return notifyProductApply(ctx, userid, req.ProductID, member.Id, consts.NotifyKindProductChangeRole, req.Role, req.Reason)
+	})
 }
 
 // ProductChangeRoleApproval 产品角色变更审批
 func ProductChangeRoleApproval(ctx context.Context, userid uint64, req *pb.ProductApprovalRequest) error {
-	myRole, _, err := GetUserProductRole(ctx, userid, req.ProductID)
-	if err != nil {
-		return err
-	}
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		myRole, _, err := GetUserProductRole(ctx, userid, req.ProductID)
+		if err != nil {
+			return err
+		}
 
-	if myRole != consts.ProductRoleManager {
-		return errs.New(errs.RetWebMemberNotManager, "not product manager")
-	}
+		if myRole != consts.ProductRoleManager {
+			return errs.New(errs.RetWebMemberNotManager, "not product manager")
+		}
 
-	_, member, err := table.GetProductMemberByUser(ctx, req.ProductID, req.Userid)
-	if err != nil {
-		return err
-	}
+		_, member, err := table.GetProductMemberByUser(ctx, req.ProductID, req.Userid)
+		if err != nil {
+			return err
+		}
 
-	role := GetProductRole(member)
-	if role == consts.ProductRoleNotJoin {
-		return errs.Newf(errs.RetWebIsNotMember, "user is not member of product")
-	}
+		role := GetProductRole(member)
+		if role == consts.ProductRoleNotJoin {
+			return errs.Newf(errs.RetWebIsNotMember, "user is not member of product")
+		}
 
-	if member.Status != consts.ProductMemberStatusChangeRole {
-		return errs.Newf(errs.RetWebMemberNotUnderApproval, "user is not in role change approval status")
-	}
+		if member.Status != consts.ProductMemberStatusChangeRole {
+			return errs.Newf(errs.RetWebMemberNotUnderApproval, "user is not in role change approval status")
+		}
 
-	var update horm.Map
-	update["status"] = consts.ProductMemberStatusJoined
-	update["change_role"] = 0
+		update := horm.Map{
+			"status":      consts.ProductMemberStatusJoined,
+			"change_role": 0,
+		}
 
-	if req.Status == consts.ApprovalAccess {
-		update["role"] = member.ChangeRole
-	}
+		if req.Status == consts.ApprovalAccess {
+			update["role"] = member.ChangeRole
+		}
 
-	return table.UpdateProductMemberByID(ctx, member.Id, update)
+		err = table.UpdateProductMemberByID(ctx, member.Id, update)
+		if err != nil {
+			return err
+		}
+
+		return notifyProductResult(ctx, userid, member, consts.NotifyKindProductChangeRole, member.ChangeRole, req)
+	})
 }
 
 // ProductMemberRemove 将指定用户移出产品
@@ -701,6 +731,36 @@ func GetProductAndManagers(ctx context.Context, userid uint64,
 	return myRole, product, managers, nil
 }
 
+// notifyProductApply 通知产品管理员审批
+func notifyProductApply(ctx context.Context, userid uint64,
+	productID, memberID int, kind string, role int8, reason string) error {
+	return notify(ctx, &Notification{
+		Event:     consts.NotifyEventApply,
+		Kind:      kind,
+		Applicant: userid,
+		ProductID: productID,
+		MemberID:  memberID,
+		Role:      role,
+		Reason:    reason,
+	}, productApprovers(ctx, productID))
+}
+
+// notifyProductResult 通知申请人产品审批结果
+func notifyProductResult(ctx context.Context, userid uint64,
+	member *table.TblProductMember, kind string, role int8, req *pb.ProductApprovalRequest) error {
+	return notify(ctx, &Notification{
+		Event:     consts.NotifyEventResult,
+		Kind:      kind,
+		Applicant: member.UserID,
+		Operator:  userid,
+		ProductID: member.ProductID,
+		MemberID:  member.Id,
+		Role:      role,
+		Approved:  req.Status == consts.ApprovalAccess,
+		Reason:    req.Reason,
+	}, []uint64{member.UserID})
+}
+
 func GetUseridFromProductMember(members []*table.TblProductMember) []uint64 {
 	ret := []uint64{}
 	for _, member := range members {
@@ -0,0 +1,204 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/cache"
+	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/manage/srv"
+	"github.com/horm-database/manage/srv/schedule"
+	"github.com/samber/lo"
+)
+
+// 后台定时任务名，可在配置 schedule.jobs 中覆盖默认执行计划
+const (
+	JobMemberExpire   = "member_expire"    // 过期的空间、产品成员置为已过期
+	JobMemberRemind   = "member_remind"    // 成员权限过期前 MemberRenewDays 天提醒续期
+	JobEmailCodePurge = "email_code_purge" // 清理过期的邮箱验证码
+	JobAccessExpire   = "access_expire"    // 过期的应用接入权限下线，并提醒即将过期的权限
+)
+
+func init() {
+	schedule.Register(JobMemberExpire, "@every 5m", expireMembers)
+	schedule.Register(JobMemberRemind, "@every 1h", remindMembers)
+	schedule.Register(JobEmailCodePurge, "@every 10m", purgeEmailCodes)
+	schedule.Register(JobAccessExpire, "@every 5m", sweepAccessExpire)
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// expireMembers 将已过期的空间、产品成员置为已过期（未审批的续期申请一并失效），并通知成员本人
+func expireMembers(ctx context.Context) error {
+	now := time.Now().Unix()
+	limit := srv.Config().Schedule.BatchSize
+
+	workspaceMembers, err := table.GetExpiredWorkspaceMembers(ctx, now, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range workspaceMembers {
+		err := table.Transaction(ctx, func(ctx context.Context) error {
+			ok, err := table.ExpireWorkspaceMember(ctx, member)
+			if err != nil || !ok {
+				return err
+			}
+
+			return notify(ctx, workspaceExpireNotification(consts.NotifyEventExpired, member), []uint64{member.UserID})
+		})
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "expire workspace member [%d] error: %v", member.Id, err)
+		}
+	}
+
+	productMembers, err := table.GetExpiredProductMembers(ctx, now, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range productMembers {
+		err := table.Transaction(ctx, func(ctx context.Context) error {
+			ok, err := table.ExpireProductMember(ctx, member)
+			if err != nil || !ok {
+				return err
+			}
+
+			return notify(ctx, productExpireNotification(consts.NotifyEventExpired, member), []uint64{member.UserID})
+		})
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "expire product member [%d] error: %v", member.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// remindMembers 提醒 MemberRenewDays 天内过期的空间、产品成员申请续期，同一过期时间只提醒一次
+func remindMembers(ctx context.Context) error {
+	now := time.Now().Unix()
+	before := now + consts.MemberRenewDays*86400
+	limit := srv.Config().Schedule.BatchSize
+
+	workspaceMembers, err := table.GetExpiringWorkspaceMembers(ctx, now, before, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range workspaceMembers {
+		err := table.Transaction(ctx, func(ctx context.Context) error {
+			ok, err := table.ClaimWorkspaceMemberRemind(ctx, member, now)
+			if err != nil || !ok {
+				return err
+			}
+
+			return notify(ctx, workspaceExpireNotification(consts.NotifyEventExpiring, member), []uint64{member.UserID})
+		})
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "remind workspace member [%d] error: %v", member.Id, err)
+		}
+	}
+
+	productMembers, err := table.GetExpiringProductMembers(ctx, now, before, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range productMembers {
+		err := table.Transaction(ctx, func(ctx context.Context) error {
+			ok, err := table.ClaimProductMemberRemind(ctx, member, now)
+			if err != nil || !ok {
+				return err
+			}
+
+			return notify(ctx, productExpireNotification(consts.NotifyEventExpiring, member), []uint64{member.UserID})
+		})
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "remind product member [%d] error: %v", member.Id, err)
+		}
+	}
+
+	return nil
+}
+
+// purgeEmailCodes 清理已过期的邮箱验证码。验证码写入时设置了过期时间，正常由 redis 自动删除，
+// 这里清理过期时间丢失（如 redis 数据迁移、恢复）而一直有效的验证码，及验证码索引
+func purgeEmailCodes(ctx context.Context) error {
+	accounts := []string{}
+
+	err := cache.ZRangeCacheByScore(ctx, consts.CacheEmailCodeIndex,
+		time.Now().Unix(), srv.Config().Schedule.BatchSize, &accounts)
+	if err != nil || len(accounts) == 0 {
+		return err
+	}
+
+	purged := make([]string, 0, len(accounts))
+
+	for _, account := range accounts {
+		key := consts.CachePreEmailCode + account
+
+		ttl, err := cache.TTLCacheByKey(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if ttl > 0 { // 读取索引之后重新发送了验证码，索引已更新为新的过期时间
+			continue
+		}
+
+		if ttl == -1 { // 没有过期时间
+			err = cache.DelCacheByKey(ctx, key)
+			if err != nil {
+				return err
+			}
+		}
+
+		purged = append(purged, account)
+	}
+
+	if len(purged) == 0 {
+		return nil
+	}
+
+	return cache.ZRemCacheByKey(ctx, consts.CacheEmailCodeIndex, lo.ToAnySlice(purged)...)
+}
+
+// workspaceExpireNotification 空间成员权限到期通知
+func workspaceExpireNotification(event string, member *table.TblWorkspaceMember) *Notification {
+	return &Notification{
+		Event:       event,
+		Kind:        consts.NotifyKindWorkspaceJoin,
+		WorkspaceID: member.WorkspaceID,
+		MemberID:    member.Id,
+		ExpireTime:  int64(member.ExpireTime),
+	}
+}
+
+// productExpireNotification 产品成员权限到期通知
+func productExpireNotification(event string, member *table.TblProductMember) *Notification {
+	return &Notification{
+		Event:      event,
+		Kind:       consts.NotifyKindProductJoin,
+		ProductID:  member.ProductID,
+		MemberID:   member.Id,
+		Role:       member.Role,
+		ExpireTime: int64(member.ExpireTime),
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	sc "github.com/horm-database/server/consts"
+	"github.com/horm-database/server/plugin/conf"
+	"github.com/samber/lo"
+)
+
+// normalizeScheduleConfig 未设置的调度配置以默认值填充，灰度比例 0 为合法值，不做填充，
+// 未设置 gray_scale 时的默认值需在解码前预填（见 pb.WorkspaceTablePlugin.UnmarshalJSON）
+func normalizeScheduleConfig(cfg *conf.ScheduleConfig) *conf.ScheduleConfig {
+	def := GetDefaultScheduleConfig()
+	if cfg == nil {
+		return def
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = def.Timeout
+	}
+
+	if len(cfg.RequestSource) == 0 {
+		cfg.RequestSource = def.RequestSource
+	}
+	cfg.RequestSource = lo.Uniq(cfg.RequestSource)
+
+	if len(cfg.OpType) == 0 {
+		cfg.OpType = def.OpType
+	}
+	cfg.OpType = lo.Uniq(cfg.OpType)
+
+	if cfg.AppRule == nil {
+		cfg.AppRule = def.AppRule
+	}
+
+	if cfg.AppRule.ActType == 0 {
+		cfg.AppRule.ActType = def.AppRule.ActType
+	}
+
+	if cfg.AppRule.AppIDs == nil {
+		cfg.AppRule.AppIDs = []uint64{}
+	}
+	cfg.AppRule.AppIDs = lo.Uniq(cfg.AppRule.AppIDs)
+
+	if cfg.CustomRule == nil {
+		cfg.CustomRule = def.CustomRule
+	}
+
+	if cfg.CustomRule.ActType == 0 {
+		cfg.CustomRule.ActType = def.CustomRule.ActType
+	}
+
+	if cfg.CustomRule.RuleType == 0 {
+		cfg.CustomRule.RuleType = def.CustomRule.RuleType
+	}
+
+	if cfg.CustomRule.Rules == nil {
+		cfg.CustomRule.Rules = []*conf.Rule{}
+	}
+
+	for _, rule := range cfg.CustomRule.Rules {
+		if rule != nil && rule.CondType == 0 {
+			rule.CondType = sc.CondTypeAny
+		}
+	}
+
+	return cfg
+}
+
+// checkScheduleConfig 校验插件调度配置，tableID 为 0 时（空间默认插件）不校验 app 是否有表权限
+func checkScheduleConfig(ctx context.Context, tableID int, cfg *conf.ScheduleConfig) error {
+	if cfg.Timeout < 0 {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [schedule_config.timeout] is invalid")
+	}
+
+	if cfg.GrayScale < 0 || cfg.GrayScale > 100 {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [schedule_config.gray_scale] must be between 0 and 100")
+	}
+
+	for _, source := range cfg.RequestSource {
+		if source != consts.ScheduleRequestSourceAPI && source != consts.ScheduleRequestSourceWeb {
+			return errs.Newf(errs.RetWebParamEmpty, "schedule_config request_source [%s] is invalid", source)
+		}
+	}
+
+	for _, opType := range cfg.OpType {
+		if opType != consts.ScheduleOpTypeRead && opType != consts.ScheduleOpTypeMod &&
+			opType != consts.ScheduleOpTypeDel {
+			return errs.Newf(errs.RetWebParamEmpty, "schedule_config op_type [%s] is invalid", opType)
+		}
+	}
+
+	err := checkAppRule(ctx, tableID, cfg.AppRule)
+	if err != nil {
+		return err
+	}
+
+	return checkCustomRule(cfg.CustomRule)
+}
+
+// checkAppRule 校验 app 规则，app 须存在于当前空间，并且有表的访问权限
+func checkAppRule(ctx context.Context, tableID int, rule *conf.AppRule) error {
+	if rule.ActType != sc.ActionTypeExec && rule.ActType != sc.ActionTypeSkip {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [schedule_config.app_rule.act_type] is invalid")
+	}
+
+	if len(rule.AppIDs) == 0 {
+		return nil
+	}
+
+	apps, err := table.GetAppListByAppids(ctx, rule.AppIDs)
+	if err != nil {
+		return err
+	}
+
+	exists := map[uint64]bool{}
+	for _, app := range apps {
+		exists[app.Appid] = true
+	}
+
+	for _, appid := range rule.AppIDs {
+		if !exists[appid] {
+			return errs.Newf(errs.RetWebNotFindApp, "schedule_config app_rule app [%d] not exists", appid)
+		}
+	}
+
+	if tableID == 0 {
+		return nil
+	}
+
+	isNil, tableInfo, err := table.GetTableByID(ctx, tableID)
+	if err != nil {
+		return err
+	}
+
+	if isNil {
+		return errs.Newf(errs.RetWebNotFindTable, "not find table %d", tableID)
+	}
+
+	accessTables, err := table.GetAppAccessTables(ctx, rule.AppIDs, tableID)
+	if err != nil {
+		return err
+	}
+
+	accessDBs, err := table.GetAppAccessDBs(ctx, rule.AppIDs, tableInfo.DB)
+	if err != nil {
+		return err
+	}
+
+	access := map[uint64]bool{}
+	for _, v := range accessTables {
+		if v.Status == sc.AuthStatusNormal {
+			access[v.Appid] = true
+		}
+	}
+
+	for _, v := range accessDBs {
+		if v.Status == sc.AuthStatusNormal && v.Root != sc.DBRootNone {
+			access[v.Appid] = true
+		}
+	}
+
+	for _, appid := range rule.AppIDs {
+		if !access[appid] {
+			return errs.Newf(errs.RetWebAccessPermissionDeny,
+				"schedule_config app_rule app [%d] has no access to table %d", appid, tableID)
+		}
+	}
+
+	return nil
+}
+
+// checkCustomRule 校验自定义规则语法：动作、规则类型，每条规则至少一个条件，条件的 key、操作符与值
+func checkCustomRule(rule *conf.CustomRule) error {
+	if rule.ActType != sc.ActionTypeExec && rule.ActType != sc.ActionTypeSkip {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [schedule_config.custom_rule.act_type] is invalid")
+	}
+
+	if rule.RuleType != sc.CondTypeAny && rule.RuleType != sc.CondTypeAll {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [schedule_config.custom_rule.rule_type] is invalid")
+	}
+
+	for i, v := range rule.Rules {
+		if v == nil {
+			return errs.Newf(errs.RetWebParamEmpty, "schedule_config custom_rule rules[%d] is empty", i)
+		}
+
+		if v.CondType != sc.CondTypeAny && v.CondType != sc.CondTypeAll {
+			return errs.Newf(errs.RetWebParamEmpty, "schedule_config custom_rule [%s] cond_type is invalid", v.Name)
+		}
+
+		if len(v.Cond) == 0 {
+			return errs.Newf(errs.RetWebParamEmpty, "schedule_config custom_rule [%s] has no condition", v.Name)
+		}
+
+		for j, cond := range v.Cond {
+			if cond == nil || cond.Key == "" {
+				return errs.Newf(errs.RetWebParamEmpty,
+					"schedule_config custom_rule [%s] cond[%d] key is empty", v.Name, j)
+			}
+
+			if cond.Op < consts.ConditionOpEq || cond.Op > consts.ConditionOpNotIn {
+				return errs.Newf(errs.RetWebParamEmpty,
+					"schedule_config custom_rule [%s] cond [%s] op %d is invalid", v.Name, cond.Key, cond.Op)
+			}
+
+			// 等于、不等于可以与空值比较，其余操作符必须有值
+			if cond.Value == "" && cond.Op != consts.ConditionOpEq && cond.Op != consts.ConditionOpNe {
+				return errs.Newf(errs.RetWebParamEmpty,
+					"schedule_config custom_rule [%s] cond [%s] value is empty", v.Name, cond.Key)
+			}
+		}
+	}
+
+	return nil
+}
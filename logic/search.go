@@ -0,0 +1,243 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+)
+
+// searchMaxMatch 单次检索最多读取的关键词记录数（按 id 倒序，即最新的记录），相关度排序及分页在内存中进行，
+// 命中记录超过上限时返回 truncated，各类型总数只统计已读取的记录
+const searchMaxMatch = 2000
+
+// searchFieldWeight 命中字段的相关度权重
+var searchFieldWeight = map[string]int{
+	"name":    100,
+	"manager": 40,
+	"creator": 30,
+	"intro":   20,
+}
+
+var searchTypes = []int8{consts.SearchTypeProduct, consts.SearchTypeDB, consts.SearchTypeTable}
+
+// searchHit 同一检索对象的命中信息
+type searchHit struct {
+	typ        int8
+	sid        int
+	name       string
+	score      int
+	highlights []*pb.SearchHighlight
+}
+
+// Search 全局检索，按类型分组，组内按相关度倒序分页
+func Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	// 多读取一条用于判断是否超过上限
+	sks, err := table.SearchKeywords(ctx, req.Keyword, req.Type, searchMaxMatch+1)
+	if err != nil {
+		return nil, err
+	}
+
+	truncated := len(sks) > searchMaxMatch
+	if truncated {
+		sks = sks[:searchMaxMatch]
+	}
+
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(req.Keyword))
+
+	hitMap := map[int8]map[int]*searchHit{}
+	for _, sk := range sks {
+		locs := re.FindAllStringIndex(sk.SContent, -1)
+		if len(locs) == 0 {
+			continue
+		}
+
+		if hitMap[sk.Type] == nil {
+			hitMap[sk.Type] = map[int]*searchHit{}
+		}
+
+		hit := hitMap[sk.Type][sk.Sid]
+		if hit == nil {
+			hit = &searchHit{typ: sk.Type, sid: sk.Sid, name: sk.SName}
+			hitMap[sk.Type][sk.Sid] = hit
+		}
+
+		hit.score += searchScore(sk.Field, sk.SContent, req.Keyword, locs)
+		hit.highlights = append(hit.highlights, &pb.SearchHighlight{
+			Field:   sk.Field,
+			Content: highlight(sk.SContent, locs),
+		})
+	}
+
+	ret := pb.SearchResponse{Groups: []*pb.SearchGroup{}, Truncated: truncated}
+
+	for _, typ := range searchTypes {
+		if req.Type != 0 && req.Type != typ {
+			continue
+		}
+
+		hits := []*searchHit{}
+		for _, hit := range hitMap[typ] {
+			hits = append(hits, hit)
+		}
+
+		sort.Slice(hits, func(i, j int) bool {
+			if hits[i].score != hits[j].score {
+				return hits[i].score > hits[j].score
+			}
+			return hits[i].sid > hits[j].sid
+		})
+
+		group := pb.SearchGroup{
+			Type:      typ,
+			Total:     uint64(len(hits)),
+			TotalPage: uint32(math.Ceil(float64(len(hits)) / float64(req.Size))),
+			Page:      req.Page,
+			Size:      req.Size,
+			Items:     []*pb.SearchItem{},
+		}
+
+		start := (req.Page - 1) * req.Size
+		if start < len(hits) {
+			end := start + req.Size
+			if end > len(hits) {
+				end = len(hits)
+			}
+
+			group.Items, err = getSearchItems(ctx, typ, hits[start:end])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		ret.Groups = append(ret.Groups, &group)
+	}
+
+	return &ret, nil
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// getSearchItems 补充检索对象的基础信息，已删除的对象不返回
+func getSearchItems(ctx context.Context, typ int8, hits []*searchHit) ([]*pb.SearchItem, error) {
+	ids := make([]int, len(hits))
+	for k, hit := range hits {
+		ids[k] = hit.sid
+	}
+
+	products := map[int]*pb.ProductBase{}
+	dbs := map[int]*pb.DBBase{}
+	tables := map[int]*pb.TableBase{}
+
+	switch typ {
+	case consts.SearchTypeProduct:
+		list, err := table.GetProductByIds(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range list {
+			products[v.Id] = &pb.ProductBase{
+				Id:        v.Id,
+				Name:      v.Name,
+				Intro:     v.Intro,
+				Status:    v.Status,
+				CreatedAt: v.CreatedAt.Unix(),
+			}
+		}
+	case consts.SearchTypeDB:
+		list, err := table.GetDBByIds(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range list {
+			dbs[v.Id] = GetDBBase(v)
+		}
+	case consts.SearchTypeTable:
+		list, err := table.GetTableByIds(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range list {
+			tables[v.Id] = GetTableBase(v)
+		}
+	}
+
+	items := []*pb.SearchItem{}
+	for _, hit := range hits {
+		item := pb.SearchItem{
+			Sid:        hit.sid,
+			Name:       hit.name,
+			Score:      hit.score,
+			Highlights: hit.highlights,
+			Product:    products[hit.sid],
+			DB:         dbs[hit.sid],
+			Table:      tables[hit.sid],
+		}
+
+		if item.Product == nil && item.DB == nil && item.Table == nil {
+			continue
+		}
+
+		items = append(items, &item)
+	}
+
+	return items, nil
+}
+
+// searchScore 字段相关度，完全匹配 > 前缀匹配 > 包含，命中次数越多相关度越高
+func searchScore(field, content, keyword string, locs [][]int) int {
+	weight, ok := searchFieldWeight[field]
+	if !ok {
+		weight = 10
+	}
+
+	switch {
+	case strings.EqualFold(content, keyword):
+		weight *= 4
+	case locs[0][0] == 0:
+		weight *= 2
+	}
+
+	return weight + len(locs) - 1
+}
+
+// highlight 对内容做 html 转义，并以 <em></em> 标记命中的关键词
+func highlight(content string, locs [][]int) string {
+	var b strings.Builder
+
+	last := 0
+	for _, loc := range locs {
+		b.WriteString(html.EscapeString(content[last:loc[0]]))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(content[loc[0]:loc[1]]))
+		b.WriteString("</em>")
+		last = loc[1]
+	}
+
+	b.WriteString(html.EscapeString(content[last:]))
+
+	return b.String()
+}
@@ -0,0 +1,367 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/outbox"
+	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/orm/obj"
+)
+
+// rebuildBatchSize 重建检索信息时每批读取的记录数
+const rebuildBatchSize = 200
+
+// TopicSearchIndex 刷新检索信息的异步任务
+const TopicSearchIndex = "search_index"
+
+// searchIndexJob 刷新检索信息的任务参数
+type searchIndexJob struct {
+	Type int8 `json:"type"` // 检索类型 1-product 2-db 3-table
+	Sid  int  `json:"sid"`  // 检索id
+}
+
+func init() {
+	outbox.Register(TopicSearchIndex, func(ctx context.Context, payload []byte) error {
+		job := searchIndexJob{}
+		if err := json.Api.Unmarshal(payload, &job); err != nil {
+			return errs.Newf(errs.ErrServerDecode, "decode search index job error: %v", err)
+		}
+
+		return IndexSearchKeywords(ctx, job.Type, job.Sid)
+	})
+}
+
+// RefreshSearchIndex 异步刷新 product/db/table 的检索信息，须与新增、修改在同一个事务（table.Transaction）中调用
+func RefreshSearchIndex(ctx context.Context, typ int8, sid int) error {
+	return outbox.Publish(ctx, TopicSearchIndex, &searchIndexJob{Type: typ, Sid: sid})
+}
+
+// IndexSearchKeywords 根据源表重新生成 product/db/table 的检索信息，并删除过期的检索信息，源数据已删除时删除全部检索信息
+func IndexSearchKeywords(ctx context.Context, typ int8, sid int) error {
+	var sks []*table.TblSearchKeyword
+
+	switch typ {
+	case consts.SearchTypeProduct:
+		isNil, product, err := table.GetProductByID(ctx, sid)
+		if err != nil {
+			return err
+		}
+
+		if !isNil {
+			sks, err = productSearchKeywords(ctx, product)
+			if err != nil {
+				return err
+			}
+		}
+	case consts.SearchTypeDB:
+		isNil, db, err := table.GetDBByID(ctx, sid)
+		if err != nil {
+			return err
+		}
+
+		if !isNil {
+			sks, err = dbSearchKeywords(ctx, db)
+			if err != nil {
+				return err
+			}
+		}
+	case consts.SearchTypeTable:
+		isNil, tbl, err := table.GetTableByID(ctx, sid)
+		if err != nil {
+			return err
+		}
+
+		if !isNil {
+			sks, err = tableSearchKeywords(ctx, tbl)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return errs.Newf(errs.ErrSystem, "unknown search type [%d]", typ)
+	}
+
+	return syncSearchKeywords(ctx, typ, sid, sks)
+}
+
+// RebuildSearchIndex 根据源表重建全部检索信息，仅空间管理员可操作
+func RebuildSearchIndex(ctx context.Context, userid uint64, workspaceID int) (*pb.RebuildSearchIndexResponse, error) {
+	myRole, _, err := GetUserWorkspaceRole(ctx, userid, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if myRole != consts.WorkspaceMemberManager {
+		return nil, errs.New(errs.RetWebMemberNotManager, "not workspace manager")
+	}
+
+	ret := pb.RebuildSearchIndexResponse{}
+
+	alive := map[int8]map[int]bool{
+		consts.SearchTypeProduct: {},
+		consts.SearchTypeDB:      {},
+		consts.SearchTypeTable:   {},
+	}
+
+	for lastID := 0; ; {
+		products, err := table.GetProductsAfterID(ctx, lastID, rebuildBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range products {
+			sks, err := productSearchKeywords(ctx, v)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = syncSearchKeywords(ctx, consts.SearchTypeProduct, v.Id, sks); err != nil {
+				return nil, err
+			}
+
+			alive[consts.SearchTypeProduct][v.Id] = true
+			lastID = v.Id
+		}
+
+		ret.Products += len(products)
+		if len(products) < rebuildBatchSize {
+			break
+		}
+	}
+
+	for lastID := 0; ; {
+		dbs, err := table.GetDBsAfterID(ctx, lastID, rebuildBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range dbs {
+			sks, err := dbSearchKeywords(ctx, v)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = syncSearchKeywords(ctx, consts.SearchTypeDB, v.Id, sks); err != nil {
+				return nil, err
+			}
+
+			alive[consts.SearchTypeDB][v.Id] = true
+			lastID = v.Id
+		}
+
+		ret.DBs += len(dbs)
+		if len(dbs) < rebuildBatchSize {
+			break
+		}
+	}
+
+	for lastID := 0; ; {
+		tables, err := table.GetTablesAfterID(ctx, lastID, rebuildBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range tables {
+			sks, err := tableSearchKeywords(ctx, v)
+			if err != nil {
+				return nil, err
+			}
+
+			if err = syncSearchKeywords(ctx, consts.SearchTypeTable, v.Id, sks); err != nil {
+				return nil, err
+			}
+
+			alive[consts.SearchTypeTable][v.Id] = true
+			lastID = v.Id
+		}
+
+		ret.Tables += len(tables)
+		if len(tables) < rebuildBatchSize {
+			break
+		}
+	}
+
+	// 删除源数据已不存在的检索信息
+	for lastID := 0; ; {
+		sks, err := table.GetSearchKeywordsAfterID(ctx, lastID, rebuildBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		delIds := []int{}
+		for _, sk := range sks {
+			if !alive[sk.Type][sk.Sid] {
+				delIds = append(delIds, sk.Id)
+			}
+			lastID = sk.Id
+		}
+
+		if err = table.DelSearchKeywordsByIds(ctx, delIds); err != nil {
+			return nil, err
+		}
+
+		ret.Removed += len(delIds)
+		if len(sks) < rebuildBatchSize {
+			break
+		}
+	}
+
+	return &ret, nil
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// syncSearchKeywords 覆盖写入检索信息，并删除该对象已不再需要的检索信息
+func syncSearchKeywords(ctx context.Context, typ int8, sid int, sks []*table.TblSearchKeyword) error {
+	olds, err := table.GetSearchKeywordsBySid(ctx, typ, sid)
+	if err != nil {
+		return err
+	}
+
+	err = table.ReplaceSearchKeywords(ctx, sks)
+	if err != nil {
+		return err
+	}
+
+	keep := map[string]bool{}
+	for _, sk := range sks {
+		keep[searchKeywordUniq(sk)] = true
+	}
+
+	delIds := []int{}
+	for _, old := range olds {
+		if !keep[searchKeywordUniq(old)] {
+			delIds = append(delIds, old.Id)
+		}
+	}
+
+	return table.DelSearchKeywordsByIds(ctx, delIds)
+}
+
+// searchKeywordUniq 检索信息唯一键 field + skey，skey 为空时库中默认值为 0
+func searchKeywordUniq(sk *table.TblSearchKeyword) string {
+	skey := sk.SKey
+	if skey == "" {
+		skey = "0"
+	}
+
+	return sk.Field + "|" + skey
+}
+
+func productSearchKeywords(ctx context.Context, product *table.TblProduct) ([]*table.TblSearchKeyword, error) {
+	return buildSearchKeywords(ctx, product.WorkspaceID, consts.SearchTypeProduct, product.Id, product.Name,
+		product.Intro, product.Creator, GetUserIds(product.Manager))
+}
+
+// dbSearchKeywords 所属产品已删除时不生成检索信息，已有的检索信息会被删除
+func dbSearchKeywords(ctx context.Context, db *obj.TblDB) ([]*table.TblSearchKeyword, error) {
+	isNil, workspaceID, err := productWorkspaceID(ctx, db.ProductID)
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	return buildSearchKeywords(ctx, workspaceID, consts.SearchTypeDB, db.Id, db.Name,
+		db.Intro, db.Creator, GetUserIds(db.Manager))
+}
+
+// tableSearchKeywords 表没有单独的管理员（由所属数据库管理员管理），只检索名称、简介、创建者，
+// 所属数据库或产品已删除时不生成检索信息，已有的检索信息会被删除
+func tableSearchKeywords(ctx context.Context, tbl *obj.TblTable) ([]*table.TblSearchKeyword, error) {
+	isNil, db, err := table.GetDBByID(ctx, tbl.DB)
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	isNil, workspaceID, err := productWorkspaceID(ctx, db.ProductID)
+	if err != nil || isNil {
+		return nil, err
+	}
+
+	return buildSearchKeywords(ctx, workspaceID, consts.SearchTypeTable, tbl.Id, tbl.Name,
+		tbl.Intro, tbl.Creator, nil)
+}
+
+// productWorkspaceID 产品所属空间，db/table 的检索信息归属其所在产品的空间，产品不存在时 isNil 为 true
+func productWorkspaceID(ctx context.Context, productID int) (isNil bool, workspaceID int, err error) {
+	isNil, product, err := table.GetProductByID(ctx, productID)
+	if err != nil || isNil {
+		return isNil, 0, err
+	}
+
+	return false, product.WorkspaceID, nil
+}
+
+// buildSearchKeywords 生成名称、简介、创建者、管理员的检索信息
+func buildSearchKeywords(ctx context.Context, workspaceID int, typ int8, sid int, name, intro string,
+	creator uint64, managers []uint64) ([]*table.TblSearchKeyword, error) {
+	userMap, err := table.GetUserBasesMapByIds(ctx, GetUserIds(creator, managers))
+	if err != nil {
+		return nil, err
+	}
+
+	sks := []*table.TblSearchKeyword{}
+	sks = append(sks, &table.TblSearchKeyword{
+		WorkspaceID: workspaceID,
+		Type:        typ,
+		Sid:         sid,
+		SName:       name,
+		Field:       "name",
+		SContent:    name})
+
+	if intro != "" {
+		sks = append(sks, &table.TblSearchKeyword{
+			WorkspaceID: workspaceID,
+			Type:        typ,
+			Sid:         sid,
+			SName:       name,
+			Field:       "intro",
+			SContent:    intro})
+	}
+
+	u, ok := userMap[creator]
+	if ok {
+		sks = append(sks, &table.TblSearchKeyword{
+			WorkspaceID: workspaceID,
+			Type:        typ,
+			Sid:         sid,
+			SName:       name,
+			Field:       "creator",
+			SKey:        fmt.Sprint(creator),
+			SContent:    fmt.Sprintf("%s(%s)", u.Nickname, u.Account)})
+	}
+
+	for _, uid := range managers {
+		u, ok = userMap[uid]
+		if ok {
+			sks = append(sks, &table.TblSearchKeyword{
+				WorkspaceID: workspaceID,
+				Type:        typ,
+				Sid:         sid,
+				SName:       name,
+				Field:       "manager",
+				SKey:        fmt.Sprint(uid),
+				SContent:    fmt.Sprintf("%s(%s)", u.Nickname, u.Account)})
+		}
+	}
+
+	return sks, nil
+}
@@ -19,16 +19,19 @@ import (
 	"time"
 
 	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/log"
 	"github.com/horm-database/go-horm/horm"
 	"github.com/horm-database/manage/api/pb"
 	cc "github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/schema"
 	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/manage/util/codegen"
 	"github.com/horm-database/orm/obj"
 	"github.com/samber/lo"
 )
 
-// AddTable 新增表
-func AddTable(ctx context.Context, userid uint64, req *pb.AddTableRequest) (*pb.AddTableResponse, error) {
+// AddTable 新增表，并添加空间设置的默认插件链
+func AddTable(ctx context.Context, userid uint64, workspaceID int, req *pb.AddTableRequest) (*pb.AddTableResponse, error) {
 	_, err := IsDBManager(ctx, userid, req.DB)
 	if err != nil {
 		return nil, err
@@ -46,11 +49,21 @@ func AddTable(ctx context.Context, userid uint64, req *pb.AddTableRequest) (*pb.
 		UpdatedAt:   time.Now(),
 	}
 
-	id, err := table.AddTable(ctx, &data)
+	var id int
+	err = table.Transaction(ctx, func(ctx context.Context) (err error) {
+		id, err = table.AddTable(ctx, &data)
+		if err != nil {
+			return err
+		}
+
+		return RefreshSearchIndex(ctx, cc.SearchTypeTable, id)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	addDefaultTablePlugins(ctx, workspaceID, id)
+
 	return &pb.AddTableResponse{ID: id}, nil
 }
 
@@ -65,7 +78,14 @@ func UpdateTableBase(ctx context.Context, userid uint64, req *pb.UpdateTableBase
 		"desc":  req.Desc,
 	}
 
-	return table.UpdateTableByID(ctx, req.TableID, update)
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		err := table.UpdateTableByID(ctx, req.TableID, update)
+		if err != nil {
+			return err
+		}
+
+		return RefreshSearchIndex(ctx, cc.SearchTypeTable, req.TableID)
+	})
 }
 
 func UpdateTableStatus(ctx context.Context, userid uint64, req *pb.UpdateTableStatusRequest) error {
@@ -142,106 +162,20 @@ func TableDetail(ctx context.Context, userid uint64, tableID int) (*pb.TableDeta
 		ret.IsManager = true
 	}
 
-	ret.TableFields = []*pb.TableField{
-		{
-			Field:     "id",
-			Type:      3,
-			Len:       "",
-			Empty:     false,
-			Status:    1,
-			Default:   "",
-			IsPrimary: true,
-			IsIndex:   true,
-			Comment:   "primary id",
-			More:      "{}",
-		},
-		{
-			Field:     "name",
-			Type:      2,
-			Len:       "64",
-			Empty:     false,
-			Status:    1,
-			Default:   "",
-			IsPrimary: false,
-			IsIndex:   false,
-			Comment:   "用户姓名",
-			More:      "{}",
-		},
-		{
-			Field:     "gender",
-			Type:      16,
-			Len:       "",
-			Empty:     false,
-			Status:    1,
-			Default:   "1",
-			IsPrimary: false,
-			IsIndex:   false,
-			Comment:   "用户性别 1-male 2-female",
-			More:      `[{"id":1, "name":"male"},{"id":2, "name":"female"}]`,
-		},
-		{
-			Field:     "readme",
-			Type:      15,
-			Len:       "64KB",
-			Empty:     true,
-			Status:    1,
-			Default:   "1",
-			IsPrimary: false,
-			IsIndex:   false,
-			Comment:   "自我介绍",
-			More:      ``,
-		},
-		{
-			Field:     "created_at",
-			Type:      19,
-			Len:       "",
-			Empty:     false,
-			Status:    1,
-			Default:   "CURRENT_TIMESTAMP",
-			IsPrimary: false,
-			IsIndex:   false,
-			Comment:   "记录创建时间",
-			More:      ``,
-		},
-		{
-			Field:     "updated_at",
-			Type:      19,
-			Len:       "",
-			Empty:     false,
-			Status:    1,
-			Default:   "CURRENT_TIMESTAMP",
-			IsPrimary: false,
-			IsIndex:   false,
-			Comment:   "记录最后修改时间",
-			More:      ``,
-		},
-	}
-
-	ret.TableIndexs = []*pb.TableIndex{
-		{
-			Name:   "PRIMARY",
-			Type:   "PRIMARYKEY",
-			Fields: "id",
-		},
-		{
-			Name:   "name",
-			Type:   "UNIQUEKEY",
-			Fields: "name",
-		},
-	}
-
-	ret.LangStructs = map[string]*pb.LangStruct{
-		"go": {
-			Language: "go",
-			Struct:   "type Student struct {\n\tId        int       `orm:\"id,int,omitempty\" json:\"id,omitempty\"`           // primary id\n\tName      string    `orm:\"name,string,omitempty\" json:\"name,omitempty\"`    // 用户姓名\n\tGender    int8      `orm:\"gender,int8,omitempty\" json:\"gender,omitempty\"`  // 用户性别 1-male 2-female\n\tReadme    []byte    `orm:\"readme,bytes,omitempty\" json:\"readme,omitempty\"` // 自我介绍\n\tCreatedAt time.Time `orm:\"created_at,time,omitempty\" json:\"created_at\"`    // 记录创建时间\n\tUpdatedAt time.Time `orm:\"updated_at,time,omitempty\" json:\"updated_at\"`    // 记录最后修改时间\n}",
-		},
+	tableSchema, err := schema.Describe(ctx, db, GetPhysicalTableName(tableInfo))
+	if err != nil { // 数据库连不上时不影响表详情页展示
+		log.Errorf(ctx, errs.ErrSystem, "table [%d] schema describe error: %v", tableID, err)
+	} else {
+		ret.TableFields = tableSchema.Fields
+		ret.TableIndexs = tableSchema.Indexs
+		ret.LangStructs = codegen.Generate(tableInfo.Name, tableSchema.Fields)
 	}
 
 	return &ret, nil
 }
 
 func TableAdvanceConfig(ctx context.Context, userid uint64, tableID int) (*pb.TableAdvanceConfigResponse, error) {
-	tableInfo, _, err := IsTableManager(ctx, userid, tableID)
+	tableInfo, db, err := IsTableManager(ctx, userid, tableID)
 	if err != nil {
 		return nil, err
 	}
@@ -250,8 +184,13 @@ func TableAdvanceConfig(ctx context.Context, userid uint64, tableID int) (*pb.Ta
 	ret.Definition = tableInfo.Definition
 	ret.TableVerify = tableInfo.TableVerify
 
-	ret.Definition = "CREATE TABLE `tbl_user` (\n  `id` bigint NOT NULL COMMENT '用户id',\n  `account` varchar(128) NOT NULL DEFAULT '' COMMENT '账号，可以是 admin，邮箱等。。。',\n  `nickname` varchar(128) NOT NULL DEFAULT '' COMMENT '昵称',\n  `password` varchar(64) NOT NULL DEFAULT '' COMMENT '密码',\n  `mobile` varchar(64) NOT NULL DEFAULT '' COMMENT '手机号',\n  `token` varchar(64) NOT NULL DEFAULT '' COMMENT 'token',\n  `avatar_url` varchar(512) NOT NULL DEFAULT '' COMMENT '头像',\n  `gender` tinyint NOT NULL DEFAULT '1' COMMENT '性别 1-男  2-女',\n  `company` varchar(128) NOT NULL DEFAULT '' COMMENT '公司',\n  `department` varchar(128) NOT NULL DEFAULT '' COMMENT '部门',\n  `city` varchar(128) NOT NULL DEFAULT '' COMMENT '城市',\n  `province` varchar(128) NOT NULL DEFAULT '' COMMENT '省份',\n  `country` varchar(128) NOT NULL DEFAULT '' COMMENT '国家',\n  `last_login_time` int NOT NULL DEFAULT '0' COMMENT '上次登录时间',\n  `last_login_ip` varchar(32) NOT NULL DEFAULT '' COMMENT '上次登录ip',\n  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',\n  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录最后修改时间',\n  PRIMARY KEY (`id`),\n  UNIQUE KEY `account` (`account`,`mobile`),\n  KEY `mobile` (`mobile`),\n  KEY `nickname` (`nickname`)\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='账号信息'"
-	ret.TableVerify = "tbl_user_*"
+	tableSchema, err := schema.Describe(ctx, db, GetPhysicalTableName(tableInfo))
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "table [%d] schema describe error: %v", tableID, err)
+	} else {
+		ret.Definition = tableSchema.Definition
+	}
+
 	return &ret, nil
 }
 
@@ -293,6 +232,14 @@ func GetTableAndDBByTableID(ctx context.Context, tableID int) (*obj.TblTable, *o
 	return tableInfo, dbInfo, nil
 }
 
+// GetPhysicalTableName 获取表在数据库中的真实表名，table_verify 不为空时只允许访问 table_verify 表
+func GetPhysicalTableName(v *obj.TblTable) string {
+	if v.TableVerify != "" {
+		return v.TableVerify
+	}
+	return v.Name
+}
+
 func GetTableBase(v *obj.TblTable) *pb.TableBase {
 	if v == nil {
 		return nil
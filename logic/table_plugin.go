@@ -21,6 +21,7 @@ import (
 
 	"github.com/horm-database/common/errs"
 	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/log"
 	"github.com/horm-database/common/types"
 	"github.com/horm-database/go-horm/horm"
 	"github.com/horm-database/manage/api/pb"
@@ -39,7 +40,7 @@ func TablePlugins(ctx context.Context, userid uint64, tableID int) (*pb.TablePlu
 		return nil, err
 	}
 
-	tablePlugins, err := table.GetTablePlugins(ctx, tableID)
+	chain, err := loadPluginChain(ctx, tableID)
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +49,15 @@ func TablePlugins(ctx context.Context, userid uint64, tableID int) (*pb.TablePlu
 		PrePlugins:   []*pb.TablePlugin{},
 		PostPlugins:  []*pb.TablePlugin{},
 		DeferPlugins: []*pb.TablePlugin{},
+		ChainVersion: chain.version,
+	}
+
+	// 按插件链执行顺序排列
+	var tablePlugins []*st.TblTablePlugin
+	for _, typ := range pluginChainTypes {
+		for _, id := range chain.orders[typ] {
+			tablePlugins = append(tablePlugins, chain.plugins[id])
+		}
 	}
 
 	if len(tablePlugins) == 0 {
@@ -79,6 +89,11 @@ func TablePlugins(ctx context.Context, userid uint64, tableID int) (*pb.TablePlu
 
 	pluginConfigMaps := PluginConfigsToMap(pluginConfigs)
 
+	deprecatedVersions, err := deprecatedPluginVersions(ctx, pluginIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	var userIds []uint64
 	for _, pluginInfo := range pluginInfos {
 		userIds = append(userIds, GetUserIds(pluginInfo.Creator, pluginInfo.Manager)...)
@@ -102,7 +117,7 @@ func TablePlugins(ctx context.Context, userid uint64, tableID int) (*pb.TablePlu
 			CreatedAt:      tablePlugin.CreatedAt.Unix(),
 			UpdatedAt:      tablePlugin.UpdatedAt.Unix(),
 			PluginConfigs:  []*pb.TablePluginConfig{},
-			ScheduleConfig: &conf.ScheduleConfig{},
+			ScheduleConfig: &conf.ScheduleConfig{GrayScale: cc.ScheduleDefaultGrayScale},
 		}
 
 		pluginInfo := pluginInfoMaps[tablePlugin.PluginID]
@@ -124,15 +139,30 @@ func TablePlugins(ctx context.Context, userid uint64, tableID int) (*pb.TablePlu
 			}
 		}
 
-		if tablePlugin.ScheduleConfig == "" {
-			plugin.ScheduleConfig = GetDefaultScheduleConfig()
-		} else {
-			_ = json.Api.Unmarshal([]byte(tablePlugin.ScheduleConfig), &plugin.ScheduleConfig)
+		deprecated := deprecatedVersions[fmt.Sprintf("%d_%d", tablePlugin.PluginID, tablePlugin.PluginVersion)]
+		if deprecated != nil {
+			plugin.Warning = fmt.Sprintf("plugin version %d is deprecated, please upgrade", tablePlugin.PluginVersion)
+			if deprecated.DeprecateReason != "" {
+				plugin.Warning += ": " + deprecated.DeprecateReason
+			}
 		}
 
+		if tablePlugin.ScheduleConfig != "" {
+			err = json.Api.Unmarshal([]byte(tablePlugin.ScheduleConfig), &plugin.ScheduleConfig)
+			if err != nil {
+				log.Errorf(ctx, errs.ErrServerDecode, "table plugin [%d] schedule_config is invalid, "+
+					"use default: %v", tablePlugin.Id, err)
+				plugin.ScheduleConfig = nil
+			}
+		}
+		plugin.ScheduleConfig = normalizeScheduleConfig(plugin.ScheduleConfig)
+
 		tablePluginConfigValues := map[string]interface{}{}
 		if tablePlugin.Config != "" {
-			_ = json.Api.Unmarshal([]byte(tablePlugin.Config), &tablePluginConfigValues)
+			err = json.Api.Unmarshal([]byte(tablePlugin.Config), &tablePluginConfigValues)
+			if err != nil {
+				log.Errorf(ctx, errs.ErrServerDecode, "table plugin [%d] config is invalid: %v", tablePlugin.Id, err)
+			}
 		}
 
 		configs := pluginConfigMaps[fmt.Sprintf("%d_%d", tablePlugin.PluginID, tablePlugin.PluginVersion)]
@@ -172,21 +202,6 @@ func TablePlugins(ctx context.Context, userid uint64, tableID int) (*pb.TablePlu
 		}
 	}
 
-	ret.PrePlugins, err = sortTablePlugins("table pre-plugin", ret.PrePlugins)
-	if err != nil {
-		return nil, err
-	}
-
-	ret.PostPlugins, err = sortTablePlugins("table post-plugin", ret.PostPlugins)
-	if err != nil {
-		return nil, err
-	}
-
-	ret.DeferPlugins, err = sortTablePlugins("table defer-plugin", ret.DeferPlugins)
-	if err != nil {
-		return nil, err
-	}
-
 	return &ret, nil
 }
 
@@ -198,50 +213,48 @@ func AddTablePlugin(ctx context.Context, userid uint64,
 		return nil, err
 	}
 
-	isNil, plugin, err := table.GetPluginByID(ctx, req.PluginID)
+	err = checkTablePlugin(ctx, req.PluginID, req.PluginVersion, req.Type)
 	if err != nil {
 		return nil, err
 	}
 
-	if isNil {
-		return nil, errs.Newf(errs.RetWebNotFindPlugin, "not find table %d", req.PluginID)
+	err = checkPluginConfigValues(ctx, req.PluginID, req.PluginVersion, req.PluginConfigs)
+	if err != nil {
+		return nil, err
 	}
 
-	supportVersions := types.SplitInt(plugin.Version, ",")
-	if lo.IndexOf(supportVersions, req.PluginVersion) == -1 {
-		return nil, errs.Newf(errs.RetWebNotFindPlugin,
-			"plugin %s not support version %d", plugin.Name, req.PluginVersion)
+	req.ScheduleConfig = normalizeScheduleConfig(req.ScheduleConfig)
+	err = checkScheduleConfig(ctx, req.TableId, req.ScheduleConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	if plugin.Online != cc.StatusOnline {
-		return nil, errs.Newf(errs.RetWebNotFindPlugin, "plugin %s is not online", plugin.Name)
+	chain, err := loadPluginChain(ctx, req.TableId)
+	if err != nil {
+		return nil, err
 	}
 
-	supportTypes := PluginTypes(plugin.SupportTypes)
-	if lo.IndexOf(supportTypes, req.Type) == -1 {
-		return nil, errs.Newf(errs.RetWebNotFindPlugin,
-			"plugin %s not support %s", plugin.Name, PluginTypeDesc(req.Type))
+	order := chain.orders[req.Type]
+	if len(order) == 0 && req.Front != 0 {
+		return nil, errs.Newf(errs.RetWebIsFirstPlugin, "this is first plugin, front must be zero")
 	}
 
-	tablePlugins, err := table.GetTablePlugins(ctx, req.TableId, req.Type)
+	// 校验 front 在插件链中，插入位置在写入插件链时确定
+	_, err = insertPluginID(order, req.Front, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(tablePlugins) == 0 && req.Front != 0 {
-		return nil, errs.Newf(errs.RetWebIsFirstPlugin, "this is first plugin, front must be zero")
-	}
-
+	// 先以游离状态写入，再与插件链的调整一起原子生效
 	insertTablePlugin := st.TblTablePlugin{
 		TableId:        req.TableId,
 		PluginID:       req.PluginID,
 		PluginVersion:  req.PluginVersion,
-		Type:           req.Type,
-		Front:          req.Front,
+		Type:           cc.TablePluginTypeDetached,
 		ScheduleConfig: json.MarshalToString(req.ScheduleConfig),
 		Config:         json.MarshalToString(req.PluginConfigs),
 		Desc:           req.Desc,
-		Status:         cc.StatusOnline,
+		Status:         cc.StatusOffline,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
@@ -251,12 +264,14 @@ func AddTablePlugin(ctx context.Context, userid uint64,
 		return nil, err
 	}
 
-	if len(tablePlugins) > 0 {
-		for _, tablePlugin := range tablePlugins {
-			if tablePlugin.Front == req.Front {
-				_ = table.UpdateTablePluginByID(ctx, tablePlugin.Id, horm.Map{"front": id})
-			}
+	order, _ = insertPluginID(order, req.Front, id)
+
+	err = chain.save(ctx, map[int8][]int{req.Type: order}, nil)
+	if err != nil {
+		if e := table.DelTablePlugin(ctx, id); e != nil {
+			log.Errorf(ctx, errs.ErrSystem, "delete detached table plugin [%d] error: %v", id, e)
 		}
+		return nil, err
 	}
 
 	return &pb.AddTablePluginResponse{ID: id}, nil
@@ -299,54 +314,57 @@ func UpdateTablePlugin(ctx context.Context, userid uint64, req *pb.UpdateTablePl
 			"plugin %s not support %s", plugin.Name, PluginTypeDesc(req.Type))
 	}
 
-	if req.Type != tablePlugin.Type || req.Front != tablePlugin.Front {
-		backTablePlugins, err := table.GetTableBackPlugin(ctx, tablePlugin.TableId, tablePlugin.Type, tablePlugin.Id)
-		if err != nil {
-			return err
-		}
-
-		tableTypePlugins, err := table.GetTablePlugins(ctx, tablePlugin.TableId, req.Type)
+	if req.PluginVersion != tablePlugin.PluginVersion {
+		err = checkPluginVersionDeprecated(ctx, plugin, req.PluginVersion)
 		if err != nil {
 			return err
 		}
+	}
 
-		// 先剔除自身
-		if len(backTablePlugins) > 0 {
-			var ids = getTablePluginsID(backTablePlugins)
-			err = table.UpdateTablePluginByIDs(ctx, ids, horm.Map{"front": tablePlugin.Front})
-			if err != nil {
-				return err
-			}
-		}
+	err = checkPluginConfigValues(ctx, tablePlugin.PluginID, req.PluginVersion, req.PluginConfigs)
+	if err != nil {
+		return err
+	}
 
-		// 再插入新位置
-		if len(tableTypePlugins) > 0 {
-			for _, tf := range tableTypePlugins {
-				if tf.Id != req.Id && tf.Front == req.Front {
-					err = table.UpdateTablePluginByID(ctx, tf.Id, horm.Map{"front": req.Id})
-					if err != nil {
-						return err
-					}
-				}
-			}
-		}
+	req.ScheduleConfig = normalizeScheduleConfig(req.ScheduleConfig)
+	err = checkScheduleConfig(ctx, tablePlugin.TableId, req.ScheduleConfig)
+	if err != nil {
+		return err
 	}
 
 	updateTablePlugin := horm.Map{
 		"plugin_version":  req.PluginVersion,
-		"type":            req.Type,
-		"front":           req.Front,
 		"schedule_config": json.MarshalToString(req.ScheduleConfig),
 		"config":          json.MarshalToString(req.PluginConfigs),
 		"desc":            req.Desc,
 	}
 
-	err = table.UpdateTablePluginByID(ctx, req.Id, updateTablePlugin)
+	if req.Type == tablePlugin.Type && req.Front == tablePlugin.Front {
+		return table.UpdateTablePluginByID(ctx, req.Id, updateTablePlugin)
+	}
+
+	if req.Front == req.Id {
+		return errs.Newf(errs.RetWebParamEmpty, "input param [front] is invalid")
+	}
+
+	chain, err := loadPluginChain(ctx, tablePlugin.TableId)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// 先剔除自身，再插入新位置
+	orders := map[int8][]int{tablePlugin.Type: lo.Without(chain.orders[tablePlugin.Type], req.Id)}
+	if req.Type != tablePlugin.Type {
+		orders[req.Type] = chain.orders[req.Type]
+	}
+
+	orders[req.Type], err = insertPluginID(orders[req.Type], req.Front, req.Id)
+	if err != nil {
+		return err
+	}
+
+	// 字段修改与位置调整在同一条语句内生效
+	return chain.save(ctx, orders, map[int]horm.Map{req.Id: updateTablePlugin})
 }
 
 // DelTablePlugin 删除表插件
@@ -364,76 +382,55 @@ func DelTablePlugin(ctx context.Context, userid uint64, id int) error {
 		return err
 	}
 
-	backTablePlugins, err := table.GetTableBackPlugin(ctx, tablePlugin.TableId, tablePlugin.Type, tablePlugin.Id)
+	chain, err := loadPluginChain(ctx, tablePlugin.TableId)
 	if err != nil {
 		return err
 	}
 
-	if len(backTablePlugins) > 0 {
-		var ids = getTablePluginsID(backTablePlugins)
-		err = table.UpdateTablePluginByIDs(ctx, ids, horm.Map{"front": tablePlugin.Front})
-		if err != nil {
-			return err
-		}
-	}
+	// 后继插件接到前驱之后，同时将自身移出插件链，再删除
+	order := lo.Without(chain.orders[tablePlugin.Type], id)
 
-	_ = table.DelTablePlugin(ctx, id)
+	err = chain.save(ctx, map[int8][]int{tablePlugin.Type: order}, nil, id)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return table.DelTablePlugin(ctx, id)
 }
 
 ///////////////////////////////// function /////////////////////////////////////////
 
-func sortTablePlugins(typ string, tablePlugins []*pb.TablePlugin) ([]*pb.TablePlugin, error) {
-	if len(tablePlugins) == 0 {
-		return []*pb.TablePlugin{}, nil
+// checkTablePlugin 校验插件存在、已上线，且支持指定的版本与插件类型
+func checkTablePlugin(ctx context.Context, pluginID, pluginVersion int, typ int8) error {
+	isNil, plugin, err := table.GetPluginByID(ctx, pluginID)
+	if err != nil {
+		return err
 	}
 
-	var head *pb.TablePlugin
-
-	for _, tablePlugin := range tablePlugins {
-		if tablePlugin.Front == 0 {
-			head = tablePlugin
-			break
-		}
+	if isNil {
+		return errs.Newf(errs.RetWebNotFindPlugin, "not find plugin %d", pluginID)
 	}
 
-	if head == nil {
-		return nil, errs.Newf(errs.ErrPrefixPluginNotFount,
-			"table_id %d not find head of %s", tablePlugins[0].TableId, typ)
+	supportVersions := types.SplitInt(plugin.Version, ",")
+	if lo.IndexOf(supportVersions, pluginVersion) == -1 {
+		return errs.Newf(errs.RetWebNotFindPlugin,
+			"plugin %s not support version %d", plugin.Name, pluginVersion)
 	}
 
-	ret := []*pb.TablePlugin{}
-	ret = append(ret, head)
-
-	currentTablePlugin := head
-	for i := 0; i < len(tablePlugins); i++ {
-		backTablePlugin := findBackTablePlugin(currentTablePlugin, tablePlugins)
-		if backTablePlugin == nil { // 最后一个
-			break
-		}
-
-		ret = append(ret, backTablePlugin)
-		currentTablePlugin = backTablePlugin
+	err = checkPluginVersionDeprecated(ctx, plugin, pluginVersion)
+	if err != nil {
+		return err
 	}
 
-	return ret, nil
-}
-
-func findBackTablePlugin(currentTablePlugin *pb.TablePlugin, tablePlugins []*pb.TablePlugin) *pb.TablePlugin {
-	for _, tablePlugin := range tablePlugins {
-		if tablePlugin.Front == currentTablePlugin.Id {
-			return tablePlugin
-		}
+	if plugin.Online != cc.StatusOnline {
+		return errs.Newf(errs.RetWebNotFindPlugin, "plugin %s is not online", plugin.Name)
 	}
-	return nil
-}
 
-func getTablePluginsID(tablePlugins []*st.TblTablePlugin) []int {
-	var ids []int
-	for _, v := range tablePlugins {
-		ids = append(ids, v.Id)
+	supportTypes := PluginTypes(plugin.SupportTypes)
+	if lo.IndexOf(supportTypes, typ) == -1 {
+		return errs.Newf(errs.RetWebNotFindPlugin,
+			"plugin %s not support %s", plugin.Name, PluginTypeDesc(typ))
 	}
 
-	return ids
+	return nil
 }
@@ -21,11 +21,12 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/horm-database/common/crypto"
 	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/log"
 	"github.com/horm-database/common/types"
 	"github.com/horm-database/go-horm/horm"
 	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/auth"
 	"github.com/horm-database/manage/consts"
 	"github.com/horm-database/manage/model/cache"
 	"github.com/horm-database/manage/model/mail"
@@ -49,18 +50,17 @@ func SendEmailCode(ctx context.Context, req *pb.SendEmailCodeRequest) error {
 
 	code := 1000 + rand.Intn(8888)
 
-	var subject, body string
-	if req.Type == 0 {
-		subject = "聚码数据—邮箱身份验证"
+	if req.Type != 0 {
+		return errs.Newf(errs.RetWebParamEmpty, "unknown email type %d", req.Type)
+	}
 
-		now := time.Now()
-		body = fmt.Sprintf(`亲爱的用户：<br><br>
-	您好，感谢使用聚码服务，您正在进行邮箱验证，<br><br>
-	本次请求的验证码为 <font size="4" style="color:#FFA500;"><b>%d</b></font><font style="color:#989898;">（为了保证您的账号安全，请在 5 分钟内完成验证）</font>，如非本人操作请忽略，切勿将此验证码泄露给他人，以免给您账号下的数据带来损失。<br><br>
-	聚码数据团队<br>%d年%02d月%02d日<br>`, code, now.Year(), now.Month(), now.Day())
+	data := map[string]interface{}{
+		"Code":          code,
+		"ExpireMinutes": consts.CacheEmailCodeExpire / 60,
+		"Now":           time.Now(),
 	}
 
-	err = mail.SendMail([]string{req.Account}, nil, []byte(subject), []byte(body))
+	err = mail.SendTemplate(ctx, []string{req.Account}, nil, mail.TemplateEmailCode, req.Locale, data)
 	if err != nil {
 		return err
 	}
@@ -70,10 +70,40 @@ func SendEmailCode(ctx context.Context, req *pb.SendEmailCodeRequest) error {
 		return err
 	}
 
+	// 记录验证码过期时间，由定时任务清理残留的验证码
+	err = cache.ZAddCacheByKey(ctx, consts.CacheEmailCodeIndex,
+		req.Account, time.Now().Unix()+consts.CacheEmailCodeExpire)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "add email code index [%s] error: %v", req.Account, err)
+	}
+
 	return nil
 }
 
-func Register(ctx context.Context, req *pb.RegisterRequest) error {
+// Register 注册，指定空间时校验空间允许的邮箱域名，空间开启自动通过时注册后直接加入空间
+func Register(ctx context.Context, workspaceID int, req *pb.RegisterRequest) error {
+	var setting *table.TblWorkspaceSetting
+	if workspaceID != 0 {
+		workspace, err := table.GetWorkspaceByID(ctx, workspaceID)
+		if err != nil {
+			return err
+		}
+
+		if workspace == nil || workspace.Id == 0 {
+			return errs.New(errs.RetWebWorkspaceNotExists, "workspace not exists")
+		}
+
+		setting, err = getWorkspaceSetting(ctx, workspaceID)
+		if err != nil {
+			return err
+		}
+
+		err = checkEmailDomain(setting, req.Account)
+		if err != nil {
+			return err
+		}
+	}
+
 	key := fmt.Sprintf("%s%s", consts.CachePreEmailCode, req.Account)
 
 	var saveCode string
@@ -102,12 +132,35 @@ func Register(ctx context.Context, req *pb.RegisterRequest) error {
 
 	tblUser.Account = req.Account
 	tblUser.Nickname = req.Nickname
-	tblUser.Password = crypto.MD5Str(req.Password)
+	tblUser.Password, err = auth.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
 	err = table.InsertUser(ctx, tblUser)
 
 	// 验证码被用掉之后不可重复利用
 	_ = cache.DelCacheByKey(ctx, key)
 
+	if err != nil {
+		return err
+	}
+
+	if setting != nil && setting.AutoApprove == consts.WorkspaceAutoApproveOn {
+		member := table.TblWorkspaceMember{
+			WorkspaceID: workspaceID,
+			UserID:      tblUser.Id,
+			Status:      consts.WorkspaceMemberStatusJoined,
+			JoinTime:    time.Now().Unix(),
+			ExpireType:  setting.DefaultExpireType,
+			ExpireTime:  int(GetExpireTime(0, setting.DefaultExpireType)),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+
+		_, err = table.InsertWorkspaceMember(ctx, &member)
+	}
+
 	return err
 }
 
@@ -121,28 +174,38 @@ func Login(ctx context.Context, head *head.WebReqHeader, req *pb.LoginRequest) (
 		return nil, errs.Newf(errs.RetWebAccountNotExists, "account is not exists")
 	}
 
-	if tblUser.Password != crypto.MD5Str(req.Password) {
+	ok, needRehash := auth.VerifyPassword(req.Password, tblUser.Password)
+	if !ok {
 		return nil, errs.Newf(errs.RetWebPasswordIncorrect, "password verification failed")
 	}
 
-	loginToken := crypto.MD5Str(fmt.Sprintf("%d%d%d", tblUser.Id, time.Now().Unix(), rand.Intn(10000)))
-
 	update := horm.Map{}
 	update["last_login_time"] = time.Now().Unix()
 	update["last_login_ip"] = head.Ip
-	update["token"] = loginToken
+
+	if needRehash { // 历史 md5 密码登录成功后升级为当前算法
+		update["password"], err = auth.HashPassword(req.Password)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	err = table.UpdateUserByID(ctx, tblUser.Id, update)
 	if err != nil {
 		return nil, err
 	}
 
+	session, err := auth.NewSession(ctx, head, tblUser.Id)
+	if err != nil {
+		return nil, err
+	}
+
 	ret := pb.LoginResponse{
 		UserID:     tblUser.Id,
 		Account:    tblUser.Account,
 		Mobile:     tblUser.Mobile,
 		Nickname:   tblUser.Nickname,
-		Token:      loginToken,
+		Token:      session.Token,
 		Gender:     tblUser.Gender,
 		Company:    tblUser.Company,
 		Department: tblUser.Department,
@@ -176,15 +239,68 @@ func ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) error {
 		return errs.Newf(errs.RetWebAccountExists, "not find user")
 	}
 
+	password, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+
 	update := horm.Map{}
-	update["password"] = crypto.MD5Str(req.Password)
+	update["password"] = password
 
 	err = table.UpdateUserByID(ctx, tblUser.Id, update)
+	if err != nil {
+		return err
+	}
 
 	// 验证码被用掉之后不可重复利用
 	_ = cache.DelCacheByKey(ctx, key)
 
-	return err
+	// 重设密码后所有设备需重新登录
+	return table.LogoutUserSessions(ctx, tblUser.Id)
+}
+
+// Logout 退出登录，sessionID 为 0 时退出当前会话
+func Logout(ctx context.Context, current *table.TblUserSession, sessionID int) error {
+	if sessionID == 0 {
+		sessionID = current.Id
+	}
+
+	return table.LogoutUserSessions(ctx, current.UserID, sessionID)
+}
+
+// LogoutAllSessions 退出所有设备上的登录
+func LogoutAllSessions(ctx context.Context, userid uint64) error {
+	return table.LogoutUserSessions(ctx, userid)
+}
+
+// ListMySessions 我的登录会话列表
+func ListMySessions(ctx context.Context, current *table.TblUserSession) (*pb.ListMySessionsResponse, error) {
+	sessions, err := table.GetUserOnlineSessions(ctx, current.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+
+	ret := pb.ListMySessionsResponse{Sessions: []*pb.UserSession{}}
+	for _, v := range sessions {
+		expireTime := auth.SessionExpireTime(v)
+		if expireTime <= now {
+			continue
+		}
+
+		ret.Sessions = append(ret.Sessions, &pb.UserSession{
+			SessionID:      v.Id,
+			IP:             v.IP,
+			UserAgent:      v.UserAgent,
+			LoginTime:      v.LoginTime,
+			LastActiveTime: v.LastActiveTime,
+			ExpireTime:     expireTime,
+			IsCurrent:      v.Id == current.Id,
+		})
+	}
+
+	return &ret, nil
 }
 
 func FindUser(ctx context.Context, keyword string) (*pb.FindUserResponse, error) {
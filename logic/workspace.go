@@ -16,6 +16,9 @@ package logic
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"time"
 
 	"github.com/horm-database/common/errs"
@@ -29,6 +32,120 @@ import (
 	"github.com/samber/lo"
 )
 
+// CreateWorkspace 创建空间，创建者成为空间管理员
+func CreateWorkspace(ctx context.Context, userid uint64,
+	req *pb.CreateWorkspaceRequest) (*pb.CreateWorkspaceResponse, error) {
+	exists, err := table.GetWorkspace(ctx, req.Workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists != nil && exists.Id != 0 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "workspace [%s] is already used", req.Workspace)
+	}
+
+	if req.Settings != nil {
+		err = verifyWorkspaceSettings(ctx, req.Settings)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := GenerateWorkspaceToken()
+	if err != nil {
+		return nil, err
+	}
+
+	workspace := tb.TblWorkspace{
+		Workspace:  req.Workspace,
+		Name:       req.Name,
+		Intro:      req.Intro,
+		Company:    req.Company,
+		Department: req.Department,
+		Token:      token,
+		Creator:    userid,
+		Manager:    fmt.Sprint(userid),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	workspaceID, err := table.InsertWorkspace(ctx, &workspace)
+	if errs.Code(err) == 1062 {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "workspace [%s] is already used", req.Workspace)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	member := table.TblWorkspaceMember{
+		WorkspaceID: workspaceID,
+		UserID:      userid,
+		Status:      consts.WorkspaceMemberStatusJoined,
+		JoinTime:    time.Now().Unix(),
+		ExpireType:  consts.ExpireTypePermanent,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	_, err = table.InsertWorkspaceMember(ctx, &member)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Settings != nil {
+		err = saveWorkspaceSettings(ctx, workspaceID, req.Settings)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &pb.CreateWorkspaceResponse{Id: workspaceID}, nil
+}
+
+// MyWorkspaceList 我加入/申请的空间列表
+func MyWorkspaceList(ctx context.Context, userid uint64) (*pb.MyWorkspaceListResponse, error) {
+	members, err := table.GetWorkspaceMembersByUser(ctx, userid)
+	if err != nil {
+		return nil, err
+	}
+
+	memberMap := map[int]*table.TblWorkspaceMember{}
+	workspaceIDs := []int{}
+	for _, member := range members {
+		memberMap[member.WorkspaceID] = member
+		workspaceIDs = append(workspaceIDs, member.WorkspaceID)
+	}
+
+	workspaces, err := table.GetWorkspaceByIds(ctx, workspaceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := pb.MyWorkspaceListResponse{Workspaces: []*pb.MyWorkspace{}}
+
+	for _, workspace := range workspaces {
+		member := memberMap[workspace.Id]
+
+		role, status := GetWorkspaceRealRoleStatus(member, workspace)
+		if role == consts.WorkspaceMemberNotJoin && status == consts.WorkspaceMemberStatusNotApply {
+			continue
+		}
+
+		ret.Workspaces = append(ret.Workspaces, &pb.MyWorkspace{
+			Id:         workspace.Id,
+			Workspace:  workspace.Workspace,
+			Name:       workspace.Name,
+			Intro:      workspace.Intro,
+			Role:       role,
+			Status:     status,
+			ExpireTime: member.ExpireTime,
+		})
+	}
+
+	return &ret, nil
+}
+
 // WorkspaceBaseInfo 工作空间基础信息
 func WorkspaceBaseInfo(ctx context.Context, userid uint64,
 	req *pb.WorkspaceBaseInfoRequest) (*pb.WorkspaceBaseInfoResponse, error) {
@@ -79,102 +196,222 @@ func WorkspaceBaseInfo(ctx context.Context, userid uint64,
 	return &ret, nil
 }
 
-// WorkspaceJoinApply 申请加入空间 / 续期
+// WorkspaceJoinApply 申请加入空间 / 续期，空间开启自动通过时直接加入/续期
 func WorkspaceJoinApply(ctx context.Context, userid uint64, workspaceID int, req *pb.WorkspaceJoinApplyRequest) error {
-	isNil, member, err := table.GetWorkspaceMemberByUser(ctx, workspaceID, userid)
-	if err != nil {
-		return err
-	}
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		workspace, err := table.GetWorkspaceByID(ctx, workspaceID)
+		if err != nil {
+			return err
+		}
 
-	if isNil { // 新成员申请加入空间
-		newMember := table.TblWorkspaceMember{
-			WorkspaceID: workspaceID,
-			UserID:      userid,
-			Status:      consts.WorkspaceMemberStatusApproval,
-			JoinTime:    time.Now().Unix(),
-			ExpireType:  req.ExpireType,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+		if workspace == nil || workspace.Id == 0 {
+			return errs.New(errs.RetWebWorkspaceNotExists, "workspace not exists")
 		}
-		return table.InsertWorkspaceMember(ctx, &newMember)
-	} else if GetWorkspaceRole(member) == consts.WorkspaceMemberNotJoin { // 重新申请加入空间
-		if member.Status == consts.WorkspaceMemberStatusApproval ||
-			member.Status == consts.WorkspaceMemberStatusRenewal {
-			return errs.Newf(errs.RetWebMemberUnderApproval, "under approval, please do not apply repeatedly")
+
+		setting, err := getWorkspaceSetting(ctx, workspaceID)
+		if err != nil {
+			return err
 		}
 
-		replace := horm.Map{
-			"id":           member.Id,
-			"workspace_id": member.WorkspaceID,
-			"userid":       member.UserID,
-			"status":       consts.WorkspaceMemberStatusApproval,
-			"join_time":    time.Now().Unix(),
-			"expire_type":  req.ExpireType,
-			"expire_time":  0,
-			"out_time":     0,
-			"updated_at":   time.Now(),
+		_, user, err := table.GetUserByID(ctx, userid)
+		if err != nil {
+			return err
 		}
-		return table.ReplaceWorkspaceMember(ctx, replace)
-	} else { // 申请续期
-		if member.ExpireTime == 0 || int64(member.ExpireTime)-time.Now().Unix() > 7*86400 { // 只有7天内过期的用户才允许续期
-			return errs.Newf(errs.RetWebIsMember, "user is already member of workspace")
-		} else {
+
+		err = checkEmailDomain(setting, user.Account)
+		if err != nil {
+			return err
+		}
+
+		autoApprove := setting.AutoApprove == consts.WorkspaceAutoApproveOn
+
+		// 自动通过时不经过管理员审批，只能使用空间配置的默认有效期
+		expireType := setting.DefaultExpireType
+		if req.ExpireType != nil && !autoApprove {
+			expireType = *req.ExpireType
+		}
+
+		isNil, member, err := table.GetWorkspaceMemberByUser(ctx, workspaceID, userid)
+		if err != nil {
+			return err
+		}
+
+		if isNil { // 新成员申请加入空间
+			newMember := table.TblWorkspaceMember{
+				WorkspaceID: workspaceID,
+				UserID:      userid,
+				Status:      consts.WorkspaceMemberStatusApproval,
+				JoinTime:    time.Now().Unix(),
+				ExpireType:  expireType,
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			}
+
+			if autoApprove {
+				newMember.Status = consts.WorkspaceMemberStatusJoined
+				newMember.ExpireTime = int(GetExpireTime(0, expireType))
+			}
+
+			memberID, err := table.InsertWorkspaceMember(ctx, &newMember)
+			if err != nil {
+				return err
+			}
+
+			if autoApprove {
+				return nil
+			}
+
+			return notifyWorkspaceApply(ctx, userid, workspaceID, memberID, consts.NotifyKindWorkspaceJoin, req.Reason)
+		} else if GetWorkspaceRole(member) == consts.WorkspaceMemberNotJoin { // 重新申请加入空间
 			if member.Status == consts.WorkspaceMemberStatusApproval ||
 				member.Status == consts.WorkspaceMemberStatusRenewal {
 				return errs.Newf(errs.RetWebMemberUnderApproval, "under approval, please do not apply repeatedly")
 			}
 
-			update := horm.Map{
-				"status":      consts.WorkspaceMemberStatusRenewal,
-				"expire_type": req.ExpireType,
-				"out_time":    0,
+			replace := horm.Map{
+				"id":           member.Id,
+				"workspace_id": member.WorkspaceID,
+				"userid":       member.UserID,
+				"status":       consts.WorkspaceMemberStatusApproval,
+				"join_time":    time.Now().Unix(),
+				"expire_type":  expireType,
+				"expire_time":  0,
+				"out_time":     0,
+				"updated_at":   time.Now(),
+			}
+
+			if autoApprove {
+				replace["status"] = consts.WorkspaceMemberStatusJoined
+				replace["expire_time"] = GetExpireTime(0, expireType)
+			}
+
+			err = table.ReplaceWorkspaceMember(ctx, replace)
+			if err != nil {
+				return err
+			}
+
+			if autoApprove {
+				return nil
+			}
+
+			return notifyWorkspaceApply(ctx, userid, workspaceID, member.Id, consts.NotifyKindWorkspaceJoin, req.Reason)
+		} else { // 申请续期
+			if member.ExpireTime == 0 || int64(member.ExpireTime)-time.Now().Unix() > consts.MemberRenewDays*86400 { // 只有7天内过期的用户才允许续期
+				return errs.Newf(errs.RetWebIsMember, "user is already member of workspace")
+			} else {
+				if member.Status == consts.WorkspaceMemberStatusApproval ||
+					member.Status == consts.WorkspaceMemberStatusRenewal {
+					return errs.Newf(errs.RetWebMemberUnderApproval, "under approval, please do not apply repeatedly")
+				}
+
+				update := horm.Map{
+					"status":      consts.WorkspaceMemberStatusRenewal,
+					"expire_type": expireType,
+					"out_time":    0,
+				}
+
+				if autoApprove {
+					update["status"] = consts.WorkspaceMemberStatusJoined
+					update["expire_time"] = GetExpireTime(int64(member.ExpireTime), expireType)
+					update["remind_time"] = 0
+				}
+
+				err = table.UpdateWorkspaceMemberByID(ctx, member.Id, update)
+				if err != nil {
+					return err
+				}
+
+				if autoApprove {
+					return nil
+				}
+
+				return notifyWorkspaceApply(ctx, userid, workspaceID, member.Id, consts.NotifyKindWorkspaceRenewal, req.Reason)
 			}
-			return table.UpdateWorkspaceMemberByID(ctx, member.Id, update)
 		}
-	}
+	})
 }
 
 // WorkspaceApproval 空间权限审批
 func WorkspaceApproval(ctx context.Context, userid uint64, workspaceID int, req *pb.WorkspaceApprovalRequest) error {
-	myRole, _, err := GetUserWorkspaceRole(ctx, userid, workspaceID)
-	if err != nil {
-		return err
-	}
+	return table.Transaction(ctx, func(ctx context.Context) error {
+		myRole, _, err := GetUserWorkspaceRole(ctx, userid, workspaceID)
+		if err != nil {
+			return err
+		}
 
-	if myRole != consts.WorkspaceMemberManager {
-		return errs.New(errs.RetWebMemberNotManager, "not workspace manager")
-	}
+		if myRole != consts.WorkspaceMemberManager {
+			return errs.New(errs.RetWebMemberNotManager, "not workspace manager")
+		}
 
-	isNil, member, err := table.GetWorkspaceMemberByUser(ctx, workspaceID, req.Userid)
-	if err != nil {
-		return err
-	}
+		isNil, member, err := table.GetWorkspaceMemberByUser(ctx, workspaceID, req.Userid)
+		if err != nil {
+			return err
+		}
 
-	if isNil {
-		return errs.Newf(errs.RetWebIsNotApply, "user has not applied for workspace permissions")
-	}
+		if isNil {
+			return errs.Newf(errs.RetWebIsNotApply, "user has not applied for workspace permissions")
+		}
 
-	if member.Status != consts.WorkspaceMemberStatusApproval && member.Status != consts.WorkspaceMemberStatusRenewal {
-		return errs.Newf(errs.RetWebMemberNotUnderApproval, "user is not in approval status")
-	}
+		if member.Status != consts.WorkspaceMemberStatusApproval && member.Status != consts.WorkspaceMemberStatusRenewal {
+			return errs.Newf(errs.RetWebMemberNotUnderApproval, "user is not in approval status")
+		}
+
+		var update horm.Map
+		if req.Status == consts.ApprovalAccess {
+			update = horm.Map{
+				"status":      consts.WorkspaceMemberStatusJoined,
+				"expire_time": GetExpireTime(int64(member.ExpireTime), member.ExpireType),
+				"remind_time": 0,
+			}
 
-	var update horm.Map
-	if req.Status == consts.ApprovalAccess {
-		update = horm.Map{
-			"status":      consts.WorkspaceMemberStatusJoined,
-			"expire_time": GetExpireTime(int64(member.ExpireTime), member.ExpireType),
+			if member.Status == consts.WorkspaceMemberStatusApproval {
+				update["join_time"] = time.Now().Unix()
+			}
+		} else {
+			update = horm.Map{
+				"status": consts.WorkspaceMemberStatusReject,
+			}
 		}
 
-		if member.Status == consts.WorkspaceMemberStatusApproval {
-			update["join_time"] = time.Now().Unix()
+		err = table.UpdateWorkspaceMemberByID(ctx, member.Id, update)
+		if err != nil {
+			return err
 		}
-	} else {
-		update = horm.Map{
-			"status": consts.WorkspaceMemberStatusReject,
+
+		kind := consts.NotifyKindWorkspaceJoin
+		if member.Status == consts.WorkspaceMemberStatusRenewal {
+			kind = consts.NotifyKindWorkspaceRenewal
 		}
+
+		return notify(ctx, &Notification{
+			Event:       consts.NotifyEventResult,
+			Kind:        kind,
+			Applicant:   member.UserID,
+			Operator:    userid,
+			WorkspaceID: workspaceID,
+			MemberID:    member.Id,
+			Approved:    req.Status == consts.ApprovalAccess,
+			Reason:      req.Reason,
+		}, []uint64{member.UserID})
+	})
+}
+
+// WorkspaceBatchApproval 空间权限批量审批，逐条校验权限并返回每条的审批结果
+func WorkspaceBatchApproval(ctx context.Context, userid uint64,
+	workspaceID int, req *pb.WorkspaceBatchApprovalRequest) *pb.BatchApprovalResponse {
+	ret := pb.BatchApprovalResponse{Results: []*pb.BatchApprovalResult{}}
+
+	for _, uid := range lo.Uniq(req.Userids) {
+		err := WorkspaceApproval(ctx, userid, workspaceID, &pb.WorkspaceApprovalRequest{
+			Userid: uid,
+			Status: req.Status,
+			Reason: req.Reason,
+		})
+
+		addBatchApprovalResult(&ret, &pb.BatchApprovalResult{Userid: uid}, err)
 	}
 
-	return table.UpdateWorkspaceMemberByID(ctx, member.Id, update)
+	return &ret
 }
 
 // WorkspaceMemberInvite 管理员邀请用户加入空间
@@ -205,7 +442,8 @@ func WorkspaceMemberInvite(ctx context.Context, userid uint64,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		}
-		return table.InsertWorkspaceMember(ctx, &newMember)
+		_, err = table.InsertWorkspaceMember(ctx, &newMember)
+		return err
 	} else if GetWorkspaceRole(member) == consts.WorkspaceMemberNotJoin { // 邀请重新加入空间
 		replace := horm.Map{
 			"id":           member.Id,
@@ -220,13 +458,14 @@ func WorkspaceMemberInvite(ctx context.Context, userid uint64,
 		}
 		return table.ReplaceWorkspaceMember(ctx, replace)
 	} else { // 邀请续期
-		if member.ExpireTime == 0 || int64(member.ExpireTime)-time.Now().Unix() > 7*86400 { // 只有7天内过期的用户才允许续期
+		if member.ExpireTime == 0 || int64(member.ExpireTime)-time.Now().Unix() > consts.MemberRenewDays*86400 { // 只有7天内过期的用户才允许续期
 			return errs.Newf(errs.RetWebIsMember, "user is already member of workspace")
 		} else {
 			update := horm.Map{
 				"status":      consts.WorkspaceMemberStatusJoined,
 				"expire_type": req.ExpireType,
 				"expire_time": GetExpireTime(int64(member.ExpireTime), req.ExpireType),
+				"remind_time": 0,
 				"out_time":    0,
 			}
 			return table.UpdateWorkspaceMemberByID(ctx, member.Id, update)
@@ -456,6 +695,30 @@ func GetWorkspaceRealRoleStatus(member *table.TblWorkspaceMember, workspace ...*
 	}
 }
 
+// notifyWorkspaceApply 通知空间管理员审批
+func notifyWorkspaceApply(ctx context.Context, userid uint64, workspaceID, memberID int, kind, reason string) error {
+	return notify(ctx, &Notification{
+		Event:       consts.NotifyEventApply,
+		Kind:        kind,
+		Applicant:   userid,
+		WorkspaceID: workspaceID,
+		MemberID:    memberID,
+		Reason:      reason,
+	}, workspaceApprovers(ctx, workspaceID))
+}
+
+// GenerateWorkspaceToken 生成空间 token，128 位密码学安全随机数
+func GenerateWorkspaceToken() (string, error) {
+	buf := make([]byte, 16)
+
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", errs.Newf(errs.ErrSystem, "generate workspace token error: %v", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
 func GetUseridFromWorkspaceMember(members []*table.TblWorkspaceMember) []uint64 {
 	ret := []uint64{}
 	for _, member := range members {
@@ -0,0 +1,312 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logic
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	st "github.com/horm-database/server/model/table"
+	"github.com/samber/lo"
+)
+
+// UpdateWorkspace 修改空间基础信息，仅空间管理员可操作
+func UpdateWorkspace(ctx context.Context, userid uint64, workspaceID int, req *pb.UpdateWorkspaceRequest) error {
+	myRole, _, err := GetUserWorkspaceRole(ctx, userid, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if myRole != consts.WorkspaceMemberManager {
+		return errs.New(errs.RetWebMemberNotManager, "not workspace manager")
+	}
+
+	update := horm.Map{
+		"name":       req.Name,
+		"intro":      req.Intro,
+		"company":    req.Company,
+		"department": req.Department,
+	}
+
+	return table.UpdateWorkspaceByID(ctx, workspaceID, update)
+}
+
+// WorkspaceSettings 空间设置
+func WorkspaceSettings(ctx context.Context, workspaceID int) (*pb.WorkspaceSettings, error) {
+	setting, err := getWorkspaceSetting(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins, err := workspaceDefaultPlugins(setting)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := pb.WorkspaceSettings{
+		DefaultExpireType: setting.DefaultExpireType,
+		AutoApprove:       setting.AutoApprove == consts.WorkspaceAutoApproveOn,
+		EmailDomains:      []string{},
+		DefaultPlugins:    plugins,
+	}
+
+	if setting.EmailDomains != "" {
+		ret.EmailDomains = strings.Split(setting.EmailDomains, ",")
+	}
+
+	return &ret, nil
+}
+
+// UpdateWorkspaceSettings 修改空间设置，仅空间管理员可操作
+func UpdateWorkspaceSettings(ctx context.Context, userid uint64, workspaceID int, req *pb.WorkspaceSettings) error {
+	myRole, _, err := GetUserWorkspaceRole(ctx, userid, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if myRole != consts.WorkspaceMemberManager {
+		return errs.New(errs.RetWebMemberNotManager, "not workspace manager")
+	}
+
+	err = verifyWorkspaceSettings(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return saveWorkspaceSettings(ctx, workspaceID, req)
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// getWorkspaceSetting 获取空间设置，未设置时返回默认设置
+func getWorkspaceSetting(ctx context.Context, workspaceID int) (*table.TblWorkspaceSetting, error) {
+	isNil, setting, err := table.GetWorkspaceSetting(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isNil {
+		return &table.TblWorkspaceSetting{
+			WorkspaceID:       workspaceID,
+			DefaultExpireType: consts.ExpireTypePermanent,
+			AutoApprove:       consts.WorkspaceAutoApproveOff,
+		}, nil
+	}
+
+	return setting, nil
+}
+
+// verifyWorkspaceSettings 校验默认插件链中的插件可用
+func verifyWorkspaceSettings(ctx context.Context, req *pb.WorkspaceSettings) error {
+	for _, v := range req.DefaultPlugins {
+		err := checkTablePlugin(ctx, v.PluginID, v.PluginVersion, v.Type)
+		if err != nil {
+			return err
+		}
+
+		err = checkPluginConfigValues(ctx, v.PluginID, v.PluginVersion, v.PluginConfigs)
+		if err != nil {
+			return err
+		}
+
+		v.ScheduleConfig = normalizeScheduleConfig(v.ScheduleConfig)
+		err = checkScheduleConfig(ctx, 0, v.ScheduleConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveWorkspaceSettings 保存空间设置
+func saveWorkspaceSettings(ctx context.Context, workspaceID int, req *pb.WorkspaceSettings) error {
+	var autoApprove int8 = consts.WorkspaceAutoApproveOff
+	if req.AutoApprove {
+		autoApprove = consts.WorkspaceAutoApproveOn
+	}
+
+	domains := []string{}
+	for _, v := range req.EmailDomains {
+		if domain := normalizeEmailDomain(v); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+
+	plugins := req.DefaultPlugins
+	if plugins == nil {
+		plugins = []*pb.WorkspaceTablePlugin{}
+	}
+
+	isNil, setting, err := table.GetWorkspaceSetting(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	if isNil {
+		newSetting := table.TblWorkspaceSetting{
+			WorkspaceID:       workspaceID,
+			DefaultExpireType: req.DefaultExpireType,
+			AutoApprove:       autoApprove,
+			EmailDomains:      strings.Join(lo.Uniq(domains), ","),
+			DefaultPlugins:    json.MarshalToString(plugins),
+			CreatedAt:         time.Now(),
+			UpdatedAt:         time.Now(),
+		}
+		return table.InsertWorkspaceSetting(ctx, &newSetting)
+	}
+
+	update := horm.Map{
+		"default_expire_type": req.DefaultExpireType,
+		"auto_approve":        autoApprove,
+		"email_domains":       strings.Join(lo.Uniq(domains), ","),
+		"default_plugins":     json.MarshalToString(plugins),
+	}
+
+	return table.UpdateWorkspaceSettingByID(ctx, setting.Id, update)
+}
+
+// workspaceDefaultPlugins 解析空间设置的新建表默认插件链
+func workspaceDefaultPlugins(setting *table.TblWorkspaceSetting) ([]*pb.WorkspaceTablePlugin, error) {
+	plugins := []*pb.WorkspaceTablePlugin{}
+	if setting.DefaultPlugins == "" {
+		return plugins, nil
+	}
+
+	err := json.Api.Unmarshal([]byte(setting.DefaultPlugins), &plugins)
+	if err != nil {
+		return nil, errs.Newf(errs.ErrSystem, "workspace [%d] default_plugins [%s] unmarshal error: %v",
+			setting.WorkspaceID, setting.DefaultPlugins, err)
+	}
+
+	return plugins, nil
+}
+
+// checkEmailDomain 校验账号邮箱域名在空间允许的范围内
+func checkEmailDomain(setting *table.TblWorkspaceSetting, account string) error {
+	if setting.EmailDomains == "" {
+		return nil
+	}
+
+	domain := normalizeEmailDomain(account[strings.LastIndex(account, "@")+1:])
+	if lo.IndexOf(strings.Split(setting.EmailDomains, ","), domain) == -1 {
+		return errs.Newf(errs.RetWebAccessPermissionDeny,
+			"email domain of account [%s] is not allowed by workspace", account)
+	}
+
+	return nil
+}
+
+func normalizeEmailDomain(domain string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+}
+
+// addDefaultTablePlugins 为新建的表添加空间默认插件链，同类型插件按配置顺序串联，不可用的插件跳过
+func addDefaultTablePlugins(ctx context.Context, workspaceID, tableID int) {
+	setting, err := getWorkspaceSetting(ctx, workspaceID)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "get workspace [%d] setting error: %v", workspaceID, err)
+		return
+	}
+
+	plugins, err := workspaceDefaultPlugins(setting)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "%v", err)
+		return
+	}
+
+	if len(plugins) == 0 {
+		return
+	}
+
+	chain, err := loadPluginChain(ctx, tableID)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "table [%d] load plugin chain error: %v", tableID, err)
+		return
+	}
+
+	orders := map[int8][]int{}
+	for _, typ := range pluginChainTypes {
+		orders[typ] = chain.orders[typ]
+	}
+
+	var ids []int
+
+	for _, v := range plugins {
+		scheduleConfig := normalizeScheduleConfig(v.ScheduleConfig)
+
+		err = checkTablePlugin(ctx, v.PluginID, v.PluginVersion, v.Type)
+		if err == nil {
+			// 插件配置定义可能在保存空间设置之后有变更
+			err = checkPluginConfigValues(ctx, v.PluginID, v.PluginVersion, v.PluginConfigs)
+		}
+
+		if err == nil {
+			// 保存空间设置时无法校验应用对具体表的权限
+			err = checkScheduleConfig(ctx, tableID, scheduleConfig)
+		}
+
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "table [%d] skip default plugin [%d]: %v", tableID, v.PluginID, err)
+			continue
+		}
+
+		// 先以游离状态写入，全部写入后再一次性串联
+		tablePlugin := st.TblTablePlugin{
+			TableId:        tableID,
+			PluginID:       v.PluginID,
+			PluginVersion:  v.PluginVersion,
+			Type:           consts.TablePluginTypeDetached,
+			ScheduleConfig: json.MarshalToString(scheduleConfig),
+			Config:         json.MarshalToString(v.PluginConfigs),
+			Desc:           v.Desc,
+			Status:         consts.StatusOffline,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		}
+
+		id, err := table.InsertTablePlugin(ctx, &tablePlugin)
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "table [%d] add default plugin [%d] error: %v", tableID, v.PluginID, err)
+			break
+		}
+
+		ids = append(ids, id)
+		orders[v.Type] = append(orders[v.Type], id)
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	err = chain.save(ctx, orders, nil)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "table [%d] save default plugin chain error: %v", tableID, err)
+
+		for _, id := range ids {
+			if e := table.DelTablePlugin(ctx, id); e != nil {
+				log.Errorf(ctx, errs.ErrSystem, "delete detached table plugin [%d] error: %v", id, e)
+			}
+		}
+	}
+}
@@ -15,23 +15,50 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+
 	"github.com/horm-database/common/errs"
 	"github.com/horm-database/common/log"
 	"github.com/horm-database/manage/api"
-	"github.com/horm-database/manage/auth"
+	"github.com/horm-database/manage/model/cache"
+	"github.com/horm-database/manage/model/migration"
+	"github.com/horm-database/manage/model/outbox"
 	"github.com/horm-database/manage/srv"
 	"github.com/horm-database/manage/srv/codec"
+	"github.com/horm-database/manage/srv/schedule"
 	_ "go.uber.org/automaxprocs"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 数据库表结构版本须与程序一致，否则拒绝启动
+	if err := migration.Check(context.Background()); err != nil {
+		fmt.Fprintln(os.Stderr, "check schema version fail:", err)
+		os.Exit(1)
+	}
+
 	server := srv.NewServer(api.ServerDesc)
 
-	err := auth.InitWorkspaceID(codec.GCtx)
+	outbox.Start(codec.GCtx)
+
+	err := schedule.Start(codec.GCtx, cache.SetNXCacheByKey)
 	if err != nil {
-		panic(errs.Newf(errs.ErrSystem, "init workspace id error: %v", err))
+		panic(errs.Newf(errs.ErrSystem, "start schedule error: %v", err))
 	}
 
+	// 先停止定时任务，其产生的通知任务再由 outbox 处理
+	server.AddCloseHook(schedule.Stop)
+	server.AddCloseHook(outbox.Stop)
+
 	if err := server.Serve(); err != nil {
 		log.Fatal(codec.GCtx, err)
 	}
@@ -50,3 +50,29 @@ func TTLCacheByKey(ctx context.Context, key string) (int, error) {
 
 	return ttl, err
 }
+
+// SetNXCacheByKey key 不存在时设置缓存，返回是否设置成功
+func SetNXCacheByKey(ctx context.Context, key string, value interface{}, expire int) (bool, error) {
+	var ok bool
+	_, err := GetCacheORM().Set(key, value, "EX", expire, "NX").Exec(ctx, &ok)
+
+	return ok, err
+}
+
+// ZAddCacheByKey 有序集合添加成员，成员已存在时更新分数
+func ZAddCacheByKey(ctx context.Context, key string, member interface{}, score int64) error {
+	_, err := GetCacheORM().ZAdd(key, member, score).Exec(ctx)
+	return err
+}
+
+// ZRangeCacheByScore 有序集合中分数不大于 max 的成员，按分数从小到大最多返回 limit 个
+func ZRangeCacheByScore(ctx context.Context, key string, max int64, limit int, ret interface{}) error {
+	_, err := GetCacheORM().ZRangeByScore(key, "-inf", max, false, 0, int64(limit)).Exec(ctx, ret)
+	return err
+}
+
+// ZRemCacheByKey 删除有序集合成员
+func ZRemCacheByKey(ctx context.Context, key string, members ...interface{}) error {
+	_, err := GetCacheORM().ZRem(key, members...).Exec(ctx)
+	return err
+}
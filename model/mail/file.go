@@ -0,0 +1,56 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/horm-database/manage/srv"
+)
+
+const defaultFileDir = "./mail"
+
+var fileSeq uint64
+
+// fileProvider 邮件写入本地目录，每封邮件一个 .eml 文件，不真正投递
+type fileProvider struct {
+	dir string
+}
+
+func newFileProvider() (Provider, error) {
+	dir := srv.Config().Mail.File.Dir
+	if dir == "" {
+		dir = defaultFileDir
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileProvider{dir: dir}, nil
+}
+
+func (p *fileProvider) Send(_ context.Context, msg *Message) error {
+	name := fmt.Sprintf("%s_%d_%d.eml",
+		time.Now().Format("20060102150405"), os.Getpid(), atomic.AddUint64(&fileSeq, 1))
+
+	return os.WriteFile(filepath.Join(p.dir, name), buildMIME(msg), 0644)
+}
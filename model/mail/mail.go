@@ -15,19 +15,134 @@
 package mail
 
 import (
-	"github.com/horm-database/manage/util"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/srv"
+)
+
+// 邮件发送方式，在 server.yaml 的 mail.provider 配置
+const (
+	ProviderSMTP    = "smtp"    // smtp 服务器
+	ProviderFile    = "file"    // 写入本地目录（.eml 文件），用于开发、测试环境
+	ProviderWebhook = "webhook" // 以 json 格式 POST 到 http 接口，由其他服务投递
+)
+
+// Message 邮件
+type Message struct {
+	From    string   // 发件人，格式如 "聚码数据 <noreply@example.com>"
+	To      []string // 收件人邮箱
+	Cc      []string // 抄送人邮箱
+	Subject string   // 邮件主题
+	Body    string   // 邮件内容（html）
+}
+
+// Provider 邮件发送方式
+type Provider interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+var (
+	providerOnce sync.Once
+	provider     Provider
+	providerErr  error
 )
 
-// SendMail 发送邮箱
+// SendMail 发送邮件
 // to 收件人邮箱
 // cc 抄送人邮箱
 // subject 邮件主题
-// msg 邮件内容
-func SendMail(to, cc []string, subject, msg []byte) error {
-	var user = "聚码数据"
-	var from = "649947921@qq.com"
-	var password = "lumwyaqngqycbffh"
-	var mailType = "Content-Type: text/html; charset=UTF-8"
-
-	return util.SendMail(from, user, password, to, cc, mailType, subject, msg)
+// body 邮件内容（html）
+func SendMail(ctx context.Context, to, cc []string, subject, body string) error {
+	p, err := getProvider()
+	if err != nil {
+		return err
+	}
+
+	cfg := srv.Config().Mail
+	from := (&mail.Address{Name: cfg.FromName, Address: cfg.FromAddr}).String()
+
+	err = p.Send(ctx, &Message{From: from, To: to, Cc: cc, Subject: subject, Body: body})
+	if err != nil {
+		return errs.Newf(errs.RetWebEmailSendFailed, "send email error: %v", err)
+	}
+
+	return nil
+}
+
+// SendTemplate 使用邮件模板发送邮件，locale 为空或者没有该语言的模板时使用默认语言
+func SendTemplate(ctx context.Context, to, cc []string, name, locale string, data interface{}) error {
+	subject, body, err := Render(name, locale, data)
+	if err != nil {
+		return err
+	}
+
+	return SendMail(ctx, to, cc, subject, body)
+}
+
+func getProvider() (Provider, error) {
+	providerOnce.Do(func() {
+		provider, providerErr = newProvider(srv.Config().Mail.Provider)
+	})
+
+	return provider, providerErr
+}
+
+func newProvider(name string) (Provider, error) {
+	switch name {
+	case ProviderSMTP:
+		return newSMTPProvider()
+	case ProviderFile:
+		return newFileProvider()
+	case ProviderWebhook:
+		return newWebhookProvider()
+	case "":
+		return nil, errs.New(errs.RetWebEmailSendFailed, "mail provider is not configured")
+	default:
+		return nil, errs.Newf(errs.RetWebEmailSendFailed, "unknown mail provider %s", name)
+	}
+}
+
+// buildMIME 生成 MIME 格式邮件，主题、内容均使用 base64 编码
+func buildMIME(msg *Message) []byte {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("From: %s\r\n", msg.From))
+	b.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(msg.To, ", ")))
+	if len(msg.Cc) > 0 {
+		b.WriteString(fmt.Sprintf("Cc: %s\r\n", strings.Join(msg.Cc, ", ")))
+	}
+	b.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject)))
+	b.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	body := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(body) > 76 {
+		b.WriteString(body[:76])
+		b.WriteString("\r\n")
+		body = body[76:]
+	}
+	b.WriteString(body)
+	b.WriteString("\r\n")
+
+	return []byte(b.String())
+}
+
+// parseAddress 从 "name <addr>" 格式中解析邮箱地址
+func parseAddress(addr string) (string, error) {
+	a, err := mail.ParseAddress(addr)
+	if err != nil {
+		return "", fmt.Errorf("parse mail address [%s] error: %v", addr, err)
+	}
+
+	return a.Address, nil
 }
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/horm-database/manage/srv"
+)
+
+// smtp 连接加密方式
+const (
+	smtpTLSNone     = "none"     // 不加密
+	smtpTLSStartTLS = "starttls" // 明文连接后通过 STARTTLS 升级，服务器不支持则发送失败
+	smtpTLSImplicit = "tls"      // 直接建立 tls 连接（通常为 465 端口）
+)
+
+const defaultSMTPTimeout = 10000 // 单位 ms
+
+type smtpProvider struct {
+	host     string
+	port     int
+	tls      string
+	user     string
+	password string
+	timeout  time.Duration
+}
+
+func newSMTPProvider() (Provider, error) {
+	cfg := srv.Config().Mail.SMTP
+
+	if cfg.Host == "" || cfg.Port == 0 {
+		return nil, fmt.Errorf("mail smtp host/port is not configured")
+	}
+
+	p := smtpProvider{
+		host:     cfg.Host,
+		port:     cfg.Port,
+		tls:      cfg.TLS,
+		user:     cfg.User,
+		password: os.ExpandEnv(cfg.Password), // 支持 ${ENV} 形式从环境变量读取密码，避免写入配置文件
+		timeout:  time.Duration(cfg.Timeout) * time.Millisecond,
+	}
+
+	switch p.tls {
+	case "":
+		p.tls = smtpTLSStartTLS
+	case smtpTLSNone, smtpTLSStartTLS, smtpTLSImplicit:
+	default:
+		return nil, fmt.Errorf("unknown mail smtp tls mode %s", p.tls)
+	}
+
+	if p.timeout <= 0 {
+		p.timeout = defaultSMTPTimeout * time.Millisecond
+	}
+
+	return &p, nil
+}
+
+func (p *smtpProvider) Send(ctx context.Context, msg *Message) error {
+	addr := net.JoinHostPort(p.host, strconv.Itoa(p.port))
+	tlsConfig := &tls.Config{ServerName: p.host}
+
+	dialer := net.Dialer{Timeout: p.timeout}
+
+	var conn net.Conn
+	var err error
+	if p.tls == smtpTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: &dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(p.timeout))
+
+	c, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if p.tls == smtpTLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %s not support STARTTLS", addr)
+		}
+
+		if err = c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if p.user != "" {
+		if err = c.Auth(smtp.PlainAuth("", p.user, p.password, p.host)); err != nil {
+			return err
+		}
+	}
+
+	from, err := parseAddress(msg.From)
+	if err != nil {
+		return err
+	}
+
+	if err = c.Mail(from); err != nil {
+		return err
+	}
+
+	for _, rcpt := range append(append([]string{}, msg.To...), msg.Cc...) {
+		if err = c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.Write(buildMIME(msg)); err != nil {
+		return err
+	}
+
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"html"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/srv"
+)
+
+// 邮件模板名
+const (
+	TemplateEmailCode = "email_code" // 邮箱验证码
+)
+
+const defaultLocale = "zh-CN"
+
+// localeRegexp BCP-47 语言标签，如 zh-CN、en，locale 会拼接进模板文件路径，不能包含路径字符
+var localeRegexp = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// ValidLocale locale 是否为合法的语言标签
+func ValidLocale(locale string) bool {
+	return localeRegexp.MatchString(locale)
+}
+
+// 邮件模板文件命名为 {模板名}.{语言}.html，文件中分别定义 subject、body 两个模板，
+// 优先读取 mail.template_dir 配置目录下的模板，不存在则使用内置模板。
+//
+//go:embed template/*.html
+var builtinTemplates embed.FS
+
+// Render 渲染邮件模板，返回邮件主题与内容
+func Render(name, locale string, data interface{}) (subject, body string, err error) {
+	tpl, err := loadTemplate(name, locale)
+	if err != nil {
+		return "", "", err
+	}
+
+	var b bytes.Buffer
+	if err = tpl.ExecuteTemplate(&b, "subject", data); err != nil {
+		return "", "", errs.Newf(errs.ErrSystem, "render mail template %s subject error: %v", name, err)
+	}
+	subject = strings.TrimSpace(html.UnescapeString(b.String()))
+
+	b.Reset()
+	if err = tpl.ExecuteTemplate(&b, "body", data); err != nil {
+		return "", "", errs.Newf(errs.ErrSystem, "render mail template %s body error: %v", name, err)
+	}
+
+	return subject, b.String(), nil
+}
+
+// loadTemplate 依次查找 locale、locale 的语言部分（如 en-US 的 en）、默认语言的模板
+func loadTemplate(name, locale string) (*template.Template, error) {
+	for _, l := range candidateLocales(locale) {
+		file := name + "." + l + ".html"
+
+		buf, err := readTemplateFile(file)
+		if err != nil {
+			return nil, errs.Newf(errs.ErrSystem, "read mail template %s error: %v", file, err)
+		}
+
+		if buf == nil {
+			continue
+		}
+
+		tpl, err := template.New(file).Parse(string(buf))
+		if err != nil {
+			return nil, errs.Newf(errs.ErrSystem, "parse mail template %s error: %v", file, err)
+		}
+
+		return tpl, nil
+	}
+
+	return nil, errs.Newf(errs.ErrSystem, "mail template %s not found", name)
+}
+
+// readTemplateFile 读取模板文件，不存在时返回 nil
+func readTemplateFile(file string) ([]byte, error) {
+	if dir := srv.Config().Mail.TemplateDir; dir != "" {
+		buf, err := os.ReadFile(filepath.Join(dir, file))
+		if err == nil {
+			return buf, nil
+		}
+
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	buf, err := builtinTemplates.ReadFile("template/" + file)
+	if err != nil {
+		return nil, nil
+	}
+
+	return buf, nil
+}
+
+func candidateLocales(locale string) []string {
+	ret := []string{}
+
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if ValidLocale(locale) {
+		ret = append(ret, locale)
+
+		if i := strings.Index(locale, "-"); i > 0 {
+			ret = append(ret, locale[:i])
+		}
+	}
+
+	def := srv.Config().Mail.DefaultLocale
+	if def == "" {
+		def = defaultLocale
+	}
+
+	return append(ret, def)
+}
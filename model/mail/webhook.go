@@ -0,0 +1,77 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/horm-database/manage/srv"
+	"github.com/horm-database/manage/util"
+)
+
+const defaultWebhookTimeout = 10000 // 单位 ms
+
+// webhookProvider 邮件以 json 格式 POST 到 http 接口，返回 2xx 即为发送成功
+type webhookProvider struct {
+	url     string
+	headers map[string]string
+	timeout time.Duration
+}
+
+// webhookMessage webhook 请求内容
+type webhookMessage struct {
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	Cc      []string `json:"cc,omitempty"`
+	Subject string   `json:"subject"`
+	Body    string   `json:"body"`
+}
+
+func newWebhookProvider() (Provider, error) {
+	cfg := srv.Config().Mail.Webhook
+
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("mail webhook url is not configured")
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	headers := map[string]string{}
+	for k, v := range cfg.Headers {
+		headers[k] = os.ExpandEnv(v) // 支持 ${ENV} 形式从环境变量读取 token
+	}
+
+	return &webhookProvider{
+		url:     cfg.URL,
+		headers: headers,
+		timeout: time.Duration(timeout) * time.Millisecond,
+	}, nil
+}
+
+func (p *webhookProvider) Send(ctx context.Context, msg *Message) error {
+	return util.PostJSON(ctx, p.url, &webhookMessage{
+		From:    msg.From,
+		To:      msg.To,
+		Cc:      msg.Cc,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+	}, p.headers, p.timeout)
+}
@@ -0,0 +1,386 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migration 管理端数据库表结构，以版本化迁移的方式内嵌在程序中，按版本号顺序执行。
+// 迁移文件名格式为 版本号_迁移名.sql，回滚文件为 版本号_迁移名.down.sql，
+// 已执行的迁移记录在 schema_migrations 表，并通过校验和防止迁移文件执行后被修改。
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/manage/model/table"
+)
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// Migration 迁移
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string // 迁移 sql
+	Down     string // 回滚 sql，为空不支持回滚
+	Checksum string // 迁移 sql 的 sha256
+}
+
+const createMigrationTable = "CREATE TABLE IF NOT EXISTS `schema_migrations` (" +
+	"`version` int NOT NULL COMMENT '迁移版本号'," +
+	"`name` varchar(128) NOT NULL DEFAULT '' COMMENT '迁移名'," +
+	"`checksum` varchar(64) NOT NULL DEFAULT '' COMMENT '迁移 sql 的 sha256'," +
+	"`applied_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '执行时间'," +
+	"PRIMARY KEY (`version`)" +
+	") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='表结构迁移记录'"
+
+// Migrations 获取所有内嵌的迁移，按版本号升序
+func Migrations() ([]*Migration, error) {
+	entries, err := migrationFS.ReadDir("migrations")
+	if err != nil {
+		return nil, errs.Newf(errs.ErrSystem, "read migrations error: %v", err)
+	}
+
+	ups := map[int]*Migration{}
+	downs := map[int]*Migration{}
+
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || path.Ext(fileName) != ".sql" {
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, ".sql")
+		isDown := strings.HasSuffix(base, ".down")
+		base = strings.TrimSuffix(base, ".down")
+
+		idx := strings.Index(base, "_")
+		if idx <= 0 {
+			return nil, errs.Newf(errs.ErrSystem, "migration [%s] name is invalid", fileName)
+		}
+
+		version, err := strconv.Atoi(base[:idx])
+		if err != nil || version <= 0 {
+			return nil, errs.Newf(errs.ErrSystem, "migration [%s] version is invalid", fileName)
+		}
+
+		content, err := migrationFS.ReadFile(path.Join("migrations", fileName))
+		if err != nil {
+			return nil, errs.Newf(errs.ErrSystem, "read migration [%s] error: %v", fileName, err)
+		}
+
+		files := ups
+		if isDown {
+			files = downs
+		}
+
+		if exists, ok := files[version]; ok {
+			return nil, errs.Newf(errs.ErrSystem, "migration [%s] and [%s] has same version", exists, fileName)
+		}
+
+		migration := &Migration{Version: version, Name: base[idx+1:]}
+		if isDown {
+			migration.Down = string(content)
+		} else {
+			migration.Up = string(content)
+			migration.Checksum = checksum(migration.Up)
+		}
+
+		files[version] = migration
+	}
+
+	for version, down := range downs {
+		up, ok := ups[version]
+		if !ok || up.Name != down.Name {
+			return nil, errs.Newf(errs.ErrSystem, "rollback [%s.down.sql] has no migration", down)
+		}
+
+		up.Down = down.Down
+	}
+
+	migrations := []*Migration{}
+	for _, migration := range ups {
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Version 程序期望的表结构版本，即最新迁移的版本号
+func Version() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// Migrate 按版本号顺序执行所有未执行的迁移，返回本次执行的迁移。
+// MySQL 的 DDL 无法回滚，迁移中途失败时需人工处理后再重新执行。
+func Migrate(ctx context.Context) ([]*Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	err = table.ExecSQL(ctx, createMigrationTable)
+	if err != nil {
+		return nil, errs.Newf(errs.ErrSystem, "create schema_migrations error: %v", err)
+	}
+
+	applied, err := table.GetSchemaMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verify(migrations, applied)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []*Migration{}
+	for _, migration := range pending(migrations, applied) {
+		err = execStatements(ctx, migration.Up)
+		if err != nil {
+			return done, errs.Newf(errs.ErrSystem, "migration [%s] error: %v", migration, err)
+		}
+
+		err = table.InsertSchemaMigration(ctx, &table.TblSchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now(),
+		})
+		if err != nil {
+			return done, err
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Rollback 按版本号倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func Rollback(ctx context.Context, steps int) ([]*Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := table.GetSchemaMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verify(migrations, applied)
+	if err != nil {
+		return nil, err
+	}
+
+	migrationMap := map[int]*Migration{}
+	for _, migration := range migrations {
+		migrationMap[migration.Version] = migration
+	}
+
+	done := []*Migration{}
+	for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := migrationMap[applied[i].Version]
+		if migration.Down == "" {
+			return done, errs.Newf(errs.ErrSystem, "migration [%s] not support rollback", migration)
+		}
+
+		err = execStatements(ctx, migration.Down)
+		if err != nil {
+			return done, errs.Newf(errs.ErrSystem, "rollback [%s] error: %v", migration, err)
+		}
+
+		err = table.DelSchemaMigration(ctx, migration.Version)
+		if err != nil {
+			return done, err
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Check 校验数据库表结构版本与程序一致：迁移均已执行、没有程序未知的迁移、迁移文件未被修改
+func Check(ctx context.Context) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	applied, err := table.GetSchemaMigrations(ctx)
+	if err != nil {
+		return errs.Newf(errs.ErrSystem, "get schema version error: %v, please run \"manage migrate\"", err)
+	}
+
+	err = verify(migrations, applied)
+	if err != nil {
+		return err
+	}
+
+	pendingMigrations := pending(migrations, applied)
+	if len(pendingMigrations) > 0 {
+		return errs.Newf(errs.ErrSystem, "schema version %d is behind binary version %d, "+
+			"please run \"manage migrate\"", currentVersion(applied), migrations[len(migrations)-1].Version)
+	}
+
+	return nil
+}
+
+// String 迁移文件名（不含后缀）
+func (m *Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// verify 校验已执行的迁移：程序中必须存在，且校验和一致
+func verify(migrations []*Migration, applied []*table.TblSchemaMigration) error {
+	migrationMap := map[int]*Migration{}
+	for _, migration := range migrations {
+		migrationMap[migration.Version] = migration
+	}
+
+	for _, v := range applied {
+		migration, ok := migrationMap[v.Version]
+		if !ok {
+			return errs.Newf(errs.ErrSystem, "schema version %d is newer than binary, "+
+				"migration %04d_%s is unknown", currentVersion(applied), v.Version, v.Name)
+		}
+
+		if migration.Checksum != v.Checksum {
+			return errs.Newf(errs.ErrSystem, "migration [%s] has been modified after applied, "+
+				"checksum %s != %s", migration, migration.Checksum, v.Checksum)
+		}
+	}
+
+	return nil
+}
+
+// pending 未执行的迁移
+func pending(migrations []*Migration, applied []*table.TblSchemaMigration) []*Migration {
+	appliedMap := map[int]bool{}
+	for _, v := range applied {
+		appliedMap[v.Version] = true
+	}
+
+	ret := []*Migration{}
+	for _, migration := range migrations {
+		if !appliedMap[migration.Version] {
+			ret = append(ret, migration)
+		}
+	}
+
+	return ret
+}
+
+// currentVersion 数据库当前表结构版本，即已执行的最大版本号
+func currentVersion(applied []*table.TblSchemaMigration) int {
+	if len(applied) == 0 {
+		return 0
+	}
+
+	return applied[len(applied)-1].Version
+}
+
+func checksum(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+	return hex.EncodeToString(sum[:])
+}
+
+func execStatements(ctx context.Context, sql string) error {
+	for _, statement := range splitStatements(sql) {
+		err := table.ExecSQL(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitStatements 将迁移拆分为单条语句（驱动不支持一次执行多条语句），语句以分号结束，
+// 引号（'、"、`）内的分号不作为语句结束，忽略引号外 -- 开始的注释
+func splitStatements(sql string) []string {
+	statements := []string{}
+	builder := strings.Builder{}
+
+	flush := func() {
+		if statement := strings.TrimSpace(builder.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		builder.Reset()
+	}
+
+	var quote byte // 当前所在引号，0 为不在引号内
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		switch {
+		case quote != 0:
+			builder.WriteByte(c)
+			// 反斜杠转义的字符不结束引号，连续两个引号（''）结束后由下一个引号重新进入
+			if c == '\\' && quote != '`' && i+1 < len(sql) {
+				i++
+				builder.WriteByte(sql[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			builder.WriteByte(c)
+		case isLineComment(sql[i:]):
+			for i+1 < len(sql) && sql[i+1] != '\n' {
+				i++
+			}
+		case c == ';':
+			flush()
+		default:
+			builder.WriteByte(c)
+		}
+	}
+
+	flush()
+
+	return statements
+}
+
+// isLineComment 是否为 -- 注释，与 MySQL 一致，-- 之后须为空白字符或结尾
+func isLineComment(s string) bool {
+	if !strings.HasPrefix(s, "--") {
+		return false
+	}
+
+	return len(s) == 2 || s[2] == ' ' || s[2] == '\t' || s[2] == '\n' || s[2] == '\r'
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package migration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "empty",
+			sql:  "\n-- only comment\n\n",
+			want: []string{},
+		},
+		{
+			name: "multi line statements",
+			sql:  "CREATE TABLE `a` (\n  `id` int\n);\n\nDROP TABLE `b`;\n",
+			want: []string{"CREATE TABLE `a` (\n  `id` int\n)", "DROP TABLE `b`"},
+		},
+		{
+			name: "last statement without semicolon",
+			sql:  "DROP TABLE `a`;\nDROP TABLE `b`",
+			want: []string{"DROP TABLE `a`", "DROP TABLE `b`"},
+		},
+		{
+			name: "statements on one line",
+			sql:  "DROP TABLE `a`; DROP TABLE `b`;",
+			want: []string{"DROP TABLE `a`", "DROP TABLE `b`"},
+		},
+		{
+			name: "comments",
+			sql:  "-- create a\nCREATE TABLE `a` (`id` int); -- trailing; comment\n--no space is not comment;\n",
+			want: []string{"CREATE TABLE `a` (`id` int)", "--no space is not comment"},
+		},
+		{
+			name: "semicolon in quotes",
+			sql:  "INSERT INTO `a` VALUES ('x;y', \"p;q\");\nALTER TABLE `a;b` COMMENT 'end;';\n",
+			want: []string{"INSERT INTO `a` VALUES ('x;y', \"p;q\")", "ALTER TABLE `a;b` COMMENT 'end;'"},
+		},
+		{
+			name: "escaped quotes",
+			sql:  "INSERT INTO `a` VALUES ('it''s;', 'back\\';slash', 'x -- y;');\nDROP TABLE `b`;",
+			want: []string{"INSERT INTO `a` VALUES ('it''s;', 'back\\';slash', 'x -- y;')", "DROP TABLE `b`"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitStatements(tt.sql)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitEmbeddedMigrations(t *testing.T) {
+	entries, err := migrationFS.ReadDir("migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		buf, err := migrationFS.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		statements := splitStatements(string(buf))
+		if len(statements) == 0 {
+			t.Errorf("%s has no statement", entry.Name())
+		}
+
+		for _, statement := range statements {
+			if strings.HasSuffix(statement, ";") || strings.HasPrefix(statement, "--") {
+				t.Errorf("%s split error: %q", entry.Name(), statement)
+			}
+		}
+	}
+}
@@ -0,0 +1,18 @@
+-- 删除所有基础表，数据将全部丢失
+
+DROP TABLE IF EXISTS `tbl_table_plugin`;
+DROP TABLE IF EXISTS `tbl_plugin_config`;
+DROP TABLE IF EXISTS `tbl_plugin`;
+DROP TABLE IF EXISTS `tbl_access_table`;
+DROP TABLE IF EXISTS `tbl_access_db`;
+DROP TABLE IF EXISTS `tbl_app_info`;
+DROP TABLE IF EXISTS `tbl_search_keyword`;
+DROP TABLE IF EXISTS `tbl_collect_table`;
+DROP TABLE IF EXISTS `tbl_table`;
+DROP TABLE IF EXISTS `tbl_db`;
+DROP TABLE IF EXISTS `tbl_product_member`;
+DROP TABLE IF EXISTS `tbl_product`;
+DROP TABLE IF EXISTS `tbl_workspace_member`;
+DROP TABLE IF EXISTS `tbl_workspace`;
+DROP TABLE IF EXISTS `tbl_sequence`;
+DROP TABLE IF EXISTS `tbl_user`;
@@ -0,0 +1,3 @@
+-- 回滚 0002_user_session
+
+DROP TABLE IF EXISTS `tbl_user_session`;
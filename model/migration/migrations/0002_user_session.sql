@@ -0,0 +1,18 @@
+-- 多设备登录会话
+
+CREATE TABLE IF NOT EXISTS `tbl_user_session` (
+    `id` int NOT NULL AUTO_INCREMENT COMMENT '会话id',
+    `userid` bigint NOT NULL DEFAULT '0' COMMENT '用户id',
+    `token` varchar(64) NOT NULL DEFAULT '' COMMENT '会话 token，用于请求签名',
+    `ip` varchar(64) NOT NULL DEFAULT '' COMMENT '最近访问 ip',
+    `user_agent` varchar(512) NOT NULL DEFAULT '' COMMENT '登录设备 User-Agent',
+    `status` tinyint NOT NULL DEFAULT '1' COMMENT '1-正常 2-已退出',
+    `login_time` int NOT NULL DEFAULT '0' COMMENT '登录时间',
+    `last_active_time` int NOT NULL DEFAULT '0' COMMENT '最近访问时间，超过空闲时间未访问则会话失效',
+    `expire_time` int NOT NULL DEFAULT '0' COMMENT '绝对过期时间',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录最后修改时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `token` (`token`),
+    KEY `userid` (`userid`,`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='登录会话';
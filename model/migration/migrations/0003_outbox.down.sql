@@ -0,0 +1,3 @@
+-- 回滚 0003_outbox
+
+DROP TABLE IF EXISTS `tbl_outbox`;
@@ -0,0 +1,15 @@
+-- 异步任务 outbox
+
+CREATE TABLE IF NOT EXISTS `tbl_outbox` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `topic` varchar(64) NOT NULL DEFAULT '' COMMENT '任务主题，决定由哪个 handler 处理',
+    `payload` text COMMENT '任务参数 json',
+    `status` tinyint NOT NULL DEFAULT '1' COMMENT '1-待处理 2-处理中 3-失败（超过最大重试次数）',
+    `retry_times` int NOT NULL DEFAULT '0' COMMENT '已失败次数',
+    `next_run_time` bigint NOT NULL DEFAULT '0' COMMENT '下次执行时间（毫秒），处理中时为处理超时时间',
+    `last_error` varchar(1024) NOT NULL DEFAULT '' COMMENT '最近一次失败原因',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录最后修改时间',
+    PRIMARY KEY (`id`),
+    KEY `status` (`status`,`next_run_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='异步任务';
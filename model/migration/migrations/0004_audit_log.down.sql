@@ -0,0 +1,3 @@
+-- 回滚 0004_audit_log
+
+DROP TABLE IF EXISTS `tbl_audit_log`;
@@ -0,0 +1,18 @@
+-- 管理操作审计日志
+
+CREATE TABLE IF NOT EXISTS `tbl_audit_log` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `userid` bigint NOT NULL DEFAULT '0' COMMENT '操作人，0 为系统任务',
+    `ip` varchar(64) NOT NULL DEFAULT '' COMMENT '操作人 ip',
+    `request_id` bigint unsigned NOT NULL DEFAULT '0' COMMENT '请求id',
+    `api` varchar(128) NOT NULL DEFAULT '' COMMENT '接口名',
+    `entity_type` varchar(64) NOT NULL DEFAULT '' COMMENT '对象类型，即表名去掉 tbl_ 前缀，如 db、access_table',
+    `entity_id` varchar(64) NOT NULL DEFAULT '' COMMENT '对象id',
+    `action` tinyint NOT NULL DEFAULT '0' COMMENT '1-新增 2-修改 3-删除',
+    `diff` mediumtext COMMENT '变更内容 json {"字段":{"before":修改前,"after":修改后}}',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    KEY `entity` (`entity_type`,`entity_id`),
+    KEY `userid` (`userid`),
+    KEY `created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='审计日志';
@@ -0,0 +1,3 @@
+-- 回滚 0005_notify_setting
+
+DROP TABLE IF EXISTS `tbl_notify_setting`;
@@ -0,0 +1,14 @@
+-- 审批通知个人设置
+
+CREATE TABLE IF NOT EXISTS `tbl_notify_setting` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `userid` bigint NOT NULL DEFAULT '0' COMMENT '用户id',
+    `email` tinyint NOT NULL DEFAULT '1' COMMENT '邮件通知 1-开启 2-关闭',
+    `webhook_url` varchar(512) NOT NULL DEFAULT '' COMMENT '通知推送的 webhook 地址，为空不推送',
+    `locale` varchar(16) NOT NULL DEFAULT '' COMMENT '通知语言，为空使用默认语言',
+    `mute_events` varchar(256) NOT NULL DEFAULT '' COMMENT '不接收通知的事件，多个逗号分隔',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录最后修改时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `userid` (`userid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='通知设置';
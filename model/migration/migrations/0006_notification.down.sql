@@ -0,0 +1,3 @@
+-- 回滚 0006_notification
+
+DROP TABLE IF EXISTS `tbl_notification`;
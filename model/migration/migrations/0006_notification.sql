@@ -0,0 +1,16 @@
+-- 站内通知
+
+CREATE TABLE IF NOT EXISTS `tbl_notification` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `userid` bigint NOT NULL DEFAULT '0' COMMENT '接收人',
+    `event` varchar(32) NOT NULL DEFAULT '' COMMENT '事件 apply-新的申请 withdraw-申请已撤销 result-审批结果 expiring-权限即将过期 expired-权限已过期',
+    `kind` varchar(32) NOT NULL DEFAULT '' COMMENT '申请类型，如 workspace_join、access_db',
+    `title` varchar(256) NOT NULL DEFAULT '' COMMENT '标题，按接收人语言生成',
+    `detail` text COMMENT '通知内容 json，包括申请人、审批人、申请记录id 等',
+    `status` tinyint NOT NULL DEFAULT '1' COMMENT '1-未读 2-已读',
+    `read_time` int NOT NULL DEFAULT '0' COMMENT '阅读时间',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录最后修改时间',
+    PRIMARY KEY (`id`),
+    KEY `userid` (`userid`,`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='站内通知';
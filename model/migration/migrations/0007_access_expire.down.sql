@@ -0,0 +1,3 @@
+-- 回滚 0007_access_expire
+
+DROP TABLE IF EXISTS `tbl_access_expire`;
@@ -0,0 +1,20 @@
+-- 应用接入权限有效期与续期
+
+CREATE TABLE IF NOT EXISTS `tbl_access_expire` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `access_type` tinyint NOT NULL DEFAULT '0' COMMENT '1-应用接入仓库 2-应用接入表数据',
+    `access_id` int NOT NULL DEFAULT '0' COMMENT 'tbl_access_db/tbl_access_table 的权限记录id',
+    `expire_type` tinyint NOT NULL DEFAULT '0' COMMENT '权限有效期 0-永久 1-一个月 2-三个月 3-半年 4-一年',
+    `expire_time` int NOT NULL DEFAULT '0' COMMENT '过期时间，0 为永久或未审批通过',
+    `remind_time` int NOT NULL DEFAULT '0' COMMENT '到期提醒时间，0 为未提醒',
+    `offline_time` int NOT NULL DEFAULT '0' COMMENT '到期下线时间，0 为未下线',
+    `renew_status` tinyint NOT NULL DEFAULT '0' COMMENT '续期状态 0-无 1-续期审批中',
+    `renew_expire_type` tinyint NOT NULL DEFAULT '0' COMMENT '续期申请的有效期',
+    `renew_user` bigint NOT NULL DEFAULT '0' COMMENT '续期申请人',
+    `renew_reason` varchar(512) NOT NULL DEFAULT '' COMMENT '续期理由',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录最后修改时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `access` (`access_type`,`access_id`),
+    KEY `expire_time` (`expire_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='应用接入权限有效期';
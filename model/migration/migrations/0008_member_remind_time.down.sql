@@ -0,0 +1,5 @@
+-- 回滚 0008_member_remind_time
+
+ALTER TABLE `tbl_workspace_member` DROP COLUMN `remind_time`;
+
+ALTER TABLE `tbl_product_member` DROP COLUMN `remind_time`;
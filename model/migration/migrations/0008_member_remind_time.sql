@@ -0,0 +1,5 @@
+-- 成员过期提醒
+
+ALTER TABLE `tbl_workspace_member` ADD COLUMN `remind_time` int NOT NULL DEFAULT '0' COMMENT '过期提醒时间，0-未提醒' AFTER `expire_time`;
+
+ALTER TABLE `tbl_product_member` ADD COLUMN `remind_time` int NOT NULL DEFAULT '0' COMMENT '过期提醒时间，0-未提醒' AFTER `expire_time`;
@@ -0,0 +1,17 @@
+-- 回滚 0009_multi_workspace，不同空间存在同名产品时需先处理重名才能恢复产品名唯一索引
+
+DELETE FROM `tbl_workspace_resource`;
+
+UPDATE `tbl_search_keyword` SET `workspace_id` = 0;
+
+UPDATE `tbl_product` SET `workspace_id` = 0;
+
+DROP TABLE IF EXISTS `tbl_workspace_resource`;
+
+ALTER TABLE `tbl_audit_log` DROP KEY `workspace_id`, DROP COLUMN `workspace_id`;
+
+ALTER TABLE `tbl_search_keyword` DROP KEY `workspace_id`, DROP COLUMN `workspace_id`;
+
+ALTER TABLE `tbl_product` DROP INDEX `workspace_name`, ADD UNIQUE KEY `name` (`name`);
+
+ALTER TABLE `tbl_product` DROP COLUMN `workspace_id`;
@@ -0,0 +1,31 @@
+-- 多空间：产品、检索关键字、审计日志归属空间，应用与插件通过 tbl_workspace_resource 归属空间
+-- 升级前只有一个空间，已有的产品、检索关键字、应用与非官方插件都归属到最早创建的空间
+
+ALTER TABLE `tbl_product` ADD COLUMN `workspace_id` int NOT NULL DEFAULT '0' COMMENT '所属空间' AFTER `id`;
+
+ALTER TABLE `tbl_product` DROP INDEX `name`, ADD UNIQUE KEY `workspace_name` (`workspace_id`,`name`);
+
+ALTER TABLE `tbl_search_keyword` ADD COLUMN `workspace_id` int NOT NULL DEFAULT '0' COMMENT '所属空间' AFTER `id`, ADD KEY `workspace_id` (`workspace_id`);
+
+ALTER TABLE `tbl_audit_log` ADD COLUMN `workspace_id` int NOT NULL DEFAULT '0' COMMENT '操作所在空间，0 为系统任务' AFTER `id`, ADD KEY `workspace_id` (`workspace_id`);
+
+CREATE TABLE IF NOT EXISTS `tbl_workspace_resource` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `workspace_id` int NOT NULL DEFAULT '0' COMMENT '空间id',
+    `res_type` tinyint NOT NULL DEFAULT '0' COMMENT '1-应用 2-插件',
+    `res_id` bigint NOT NULL DEFAULT '0' COMMENT '应用 appid 或插件 id',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `res` (`res_type`,`res_id`),
+    KEY `workspace_id` (`workspace_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='空间资源归属';
+
+UPDATE `tbl_product` p JOIN (SELECT MIN(`id`) AS `id` FROM `tbl_workspace`) w SET p.`workspace_id` = w.`id` WHERE p.`workspace_id` = 0 AND w.`id` IS NOT NULL;
+
+UPDATE `tbl_search_keyword` k JOIN (SELECT MIN(`id`) AS `id` FROM `tbl_workspace`) w SET k.`workspace_id` = w.`id` WHERE k.`workspace_id` = 0 AND w.`id` IS NOT NULL;
+
+-- res_type 1-应用
+INSERT IGNORE INTO `tbl_workspace_resource` (`workspace_id`, `res_type`, `res_id`) SELECT w.`id`, 1, a.`appid` FROM `tbl_app_info` a JOIN (SELECT MIN(`id`) AS `id` FROM `tbl_workspace`) w WHERE w.`id` IS NOT NULL;
+
+-- res_type 2-插件，官方插件（source = 1）对所有空间可见，无需归属
+INSERT IGNORE INTO `tbl_workspace_resource` (`workspace_id`, `res_type`, `res_id`) SELECT w.`id`, 2, p.`id` FROM `tbl_plugin` p JOIN (SELECT MIN(`id`) AS `id` FROM `tbl_workspace`) w WHERE w.`id` IS NOT NULL AND p.`source` <> 1;
@@ -0,0 +1,3 @@
+-- 回滚 0010_workspace_setting
+
+DROP TABLE IF EXISTS `tbl_workspace_setting`;
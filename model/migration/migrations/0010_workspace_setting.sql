@@ -0,0 +1,14 @@
+-- 空间设置
+
+CREATE TABLE IF NOT EXISTS `tbl_workspace_setting` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `workspace_id` int NOT NULL DEFAULT '0' COMMENT '空间id',
+    `default_expire_type` tinyint NOT NULL DEFAULT '0' COMMENT '成员默认有效期 0: 永久 1: 一个月 2: 三个月 3: 半年 4: 一年',
+    `auto_approve` tinyint NOT NULL DEFAULT '2' COMMENT '加入申请自动通过 1-开启 2-关闭',
+    `email_domains` varchar(1024) NOT NULL DEFAULT '' COMMENT '允许注册/加入的邮箱域名，多个逗号分隔，为空不限制',
+    `default_plugins` text COMMENT '新建表默认插件链，是一个 json',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录最后修改时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `workspace_id` (`workspace_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='空间设置';
@@ -0,0 +1,3 @@
+-- 回滚 0011_table_plugin_chain
+
+DROP TABLE IF EXISTS `tbl_table_plugin_chain`;
@@ -0,0 +1,11 @@
+-- 表插件链版本，用于插件链修改的乐观锁
+
+CREATE TABLE IF NOT EXISTS `tbl_table_plugin_chain` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `table_id` int NOT NULL DEFAULT '0' COMMENT '表id',
+    `version` int NOT NULL DEFAULT '0' COMMENT '插件链版本，插件链每次修改加 1',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '记录创建时间',
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录最后修改时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `table_id` (`table_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='表插件链版本';
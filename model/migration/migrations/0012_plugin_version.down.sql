@@ -0,0 +1,3 @@
+-- 回滚 0012_plugin_version
+
+DROP TABLE IF EXISTS `tbl_plugin_version`;
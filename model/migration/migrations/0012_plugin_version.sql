@@ -0,0 +1,17 @@
+-- 插件版本发布记录
+
+CREATE TABLE IF NOT EXISTS `tbl_plugin_version` (
+    `id` int NOT NULL AUTO_INCREMENT,
+    `plugin_id` int NOT NULL DEFAULT '0' COMMENT '插件id',
+    `version` int NOT NULL DEFAULT '0' COMMENT '插件版本',
+    `changelog` varchar(4096) NOT NULL DEFAULT '' COMMENT '版本变更说明',
+    `status` tinyint NOT NULL DEFAULT '1' COMMENT '状态 1-正常 2-已废弃',
+    `deprecate_reason` varchar(1024) NOT NULL DEFAULT '' COMMENT '废弃原因',
+    `publisher` bigint NOT NULL DEFAULT '0' COMMENT '发布人',
+    `deprecated_by` bigint NOT NULL DEFAULT '0' COMMENT '废弃人',
+    `deprecated_at` int NOT NULL DEFAULT '0' COMMENT '废弃时间，unix 时间戳',
+    `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '发布时间',
+    `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '记录最后修改时间',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `plugin_version` (`plugin_id`, `version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='插件版本';
@@ -0,0 +1,340 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package outbox 持久化的异步任务队列。业务写入时在同一个事务（table.Transaction）中通过 Publish 将需要异步执行的副作用
+// （如检索信息写入、通知）写入 tbl_outbox，与业务数据一起提交或回滚，由有限个 worker 消费，失败按指数退避重试，
+// 超过最大重试次数后置为失败，服务关闭时会处理完剩余到期任务。置为失败的任务可以通过 Requeue 重新入队。
+package outbox
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/model/table"
+	"github.com/horm-database/manage/srv"
+	"github.com/horm-database/manage/srv/codec"
+)
+
+// Handler 任务处理函数，返回 error 时任务会被重试
+type Handler func(ctx context.Context, payload []byte) error
+
+// Counter 本实例启动以来的任务处理计数
+type Counter struct {
+	Succeed int64 // 处理成功次数
+	Failed  int64 // 处理失败次数（包含之后重试成功的）
+	Dead    int64 // 超过最大重试次数被置为失败的任务数
+}
+
+// Stats 任务队列统计
+type Stats struct {
+	Pending    uint64              // 待处理任务数（包括等待重试的任务）
+	Processing uint64              // 处理中任务数
+	Failed     uint64              // 失败任务数
+	Topics     map[string]*Counter // 本实例各主题的处理计数
+}
+
+var (
+	handlersLock = new(sync.RWMutex)
+	handlers     = map[string]Handler{}
+	counters     = map[string]*Counter{}
+
+	wakeCh  = make(chan struct{}, 1)
+	stopCh  = make(chan struct{})
+	doneCh  = make(chan struct{})
+	started int32
+)
+
+// Register 注册主题的处理函数，需在 Start 之前调用
+func Register(topic string, handler Handler) {
+	handlersLock.Lock()
+	defer handlersLock.Unlock()
+
+	handlers[topic] = handler
+	counters[topic] = &Counter{}
+}
+
+// Publish 写入任务，ctx 在事务中时任务随事务提交，提交后尽快被异步处理
+func Publish(ctx context.Context, topic string, payload interface{}) error {
+	buf, err := json.Api.Marshal(payload)
+	if err != nil {
+		return errs.Newf(errs.ErrSystem, "outbox topic [%s] marshal payload error: %v", topic, err)
+	}
+
+	job := table.TblOutbox{
+		Topic:       topic,
+		Payload:     string(buf),
+		Status:      consts.OutboxStatusPending,
+		NextRunTime: time.Now().UnixMilli(),
+	}
+
+	_, err = table.InsertOutbox(ctx, &job)
+	if err != nil {
+		return err
+	}
+
+	table.OnCommit(ctx, wake)
+
+	return nil
+}
+
+// Requeue 将失败的任务重新入队，重试次数清零，topic 为空时重新入队全部主题，返回重新入队的任务数
+func Requeue(ctx context.Context, topic string) (int64, error) {
+	num, err := table.RequeueFailedOutbox(ctx, topic, time.Now().UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+
+	if num > 0 {
+		wake()
+	}
+
+	return num, nil
+}
+
+// Start 启动任务消费
+func Start(ctx context.Context) {
+	if !atomic.CompareAndSwapInt32(&started, 0, 1) {
+		return
+	}
+
+	cfg := srv.Config().Outbox
+
+	jobs := make(chan *table.TblOutbox, cfg.Workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				process(ctx, job)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(doneCh)
+
+		ticker := time.NewTicker(time.Duration(cfg.PollInterval) * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopCh:
+				drain(ctx, jobs)
+				close(jobs)
+				wg.Wait()
+				return
+			case <-ticker.C:
+			case <-wakeCh:
+			}
+
+			dispatch(ctx, jobs)
+		}
+	}()
+}
+
+// Stop 停止轮询，并在 drain_timeout 内处理完剩余到期任务，未处理的任务仍保留在库中，下次启动后继续处理
+func Stop() {
+	if !atomic.CompareAndSwapInt32(&started, 1, 2) {
+		return
+	}
+
+	close(stopCh)
+
+	select {
+	case <-doneCh:
+	case <-time.After(time.Duration(srv.Config().Outbox.DrainTimeout) * time.Millisecond):
+		log.Errorf(codec.GCtx, errs.ErrSystem, "outbox drain timeout, remaining jobs will be processed after restart")
+	}
+}
+
+// GetStats 获取任务队列统计
+func GetStats(ctx context.Context) (*Stats, error) {
+	var err error
+
+	ret := Stats{Topics: map[string]*Counter{}}
+
+	ret.Pending, err = table.CountOutboxByStatus(ctx, consts.OutboxStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Processing, err = table.CountOutboxByStatus(ctx, consts.OutboxStatusProcessing)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Failed, err = table.CountOutboxByStatus(ctx, consts.OutboxStatusFailed)
+	if err != nil {
+		return nil, err
+	}
+
+	handlersLock.RLock()
+	defer handlersLock.RUnlock()
+
+	for topic, c := range counters {
+		ret.Topics[topic] = &Counter{
+			Succeed: atomic.LoadInt64(&c.Succeed),
+			Failed:  atomic.LoadInt64(&c.Failed),
+			Dead:    atomic.LoadInt64(&c.Dead),
+		}
+	}
+
+	return &ret, nil
+}
+
+// dispatch 读取到期任务，抢占成功后交给 worker 处理，worker 都在忙时阻塞，返回分发的任务数
+func dispatch(ctx context.Context, jobs chan<- *table.TblOutbox) int {
+	cfg := srv.Config().Outbox
+
+	dues, err := table.GetDueOutbox(ctx, time.Now().UnixMilli(), cfg.BatchSize)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "outbox get due jobs error: %v", err)
+		return 0
+	}
+
+	num := 0
+	for _, job := range dues {
+		ok, err := table.ClaimOutbox(ctx, job, time.Now().UnixMilli()+int64(cfg.Lease))
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "outbox claim job [%d] error: %v", job.Id, err)
+			continue
+		}
+
+		if !ok { // 已被其他实例抢占
+			continue
+		}
+
+		jobs <- job
+		num++
+	}
+
+	return num
+}
+
+// drain 服务关闭时分发剩余的到期任务，直到没有到期任务
+func drain(ctx context.Context, jobs chan<- *table.TblOutbox) {
+	deadline := time.Now().Add(time.Duration(srv.Config().Outbox.DrainTimeout) * time.Millisecond)
+
+	for time.Now().Before(deadline) {
+		if dispatch(ctx, jobs) == 0 {
+			return
+		}
+	}
+}
+
+// wake 唤醒轮询，立即分发到期任务
+func wake() {
+	select {
+	case wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+func process(ctx context.Context, job *table.TblOutbox) {
+	handlersLock.RLock()
+	handler, counter := handlers[job.Topic], counters[job.Topic]
+	handlersLock.RUnlock()
+
+	var err error
+	if handler == nil {
+		err = errs.Newf(errs.ErrSystem, "outbox topic [%s] handler not registered", job.Topic)
+	} else {
+		err = call(ctx, handler, job)
+	}
+
+	if err == nil {
+		if counter != nil {
+			atomic.AddInt64(&counter.Succeed, 1)
+		}
+
+		if e := table.DelOutboxByID(ctx, job.Id); e != nil {
+			log.Errorf(ctx, errs.ErrSystem, "outbox delete finished job [%d] error: %v", job.Id, e)
+		}
+		return
+	}
+
+	cfg := srv.Config().Outbox
+
+	retryTimes := job.RetryTimes + 1
+
+	update := horm.Map{}
+	update["retry_times"] = retryTimes
+	update["last_error"] = truncate(err.Error(), 1024)
+
+	if retryTimes >= cfg.MaxRetry {
+		update["status"] = consts.OutboxStatusFailed
+		log.Errorf(ctx, errs.ErrSystem, "outbox job [%d] topic [%s] failed after %d times: %v",
+			job.Id, job.Topic, retryTimes, err)
+	} else {
+		update["status"] = consts.OutboxStatusPending
+		update["next_run_time"] = time.Now().UnixMilli() + backoff(retryTimes)
+	}
+
+	if counter != nil {
+		atomic.AddInt64(&counter.Failed, 1)
+		if retryTimes >= cfg.MaxRetry {
+			atomic.AddInt64(&counter.Dead, 1)
+		}
+	}
+
+	if e := table.UpdateOutboxByID(ctx, job.Id, update); e != nil {
+		log.Errorf(ctx, errs.ErrSystem, "outbox update failed job [%d] error: %v", job.Id, e)
+	}
+}
+
+// call 执行任务，处理时间不超过 lease，panic 视为处理失败
+func call(ctx context.Context, handler Handler, job *table.TblOutbox) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(srv.Config().Outbox.Lease)*time.Millisecond)
+	defer cancel()
+
+	defer func() {
+		if e := recover(); e != nil {
+			err = errs.Newf(errs.ErrSystem, "outbox job [%d] panic: %v", job.Id, e)
+		}
+	}()
+
+	return handler(ctx, []byte(job.Payload))
+}
+
+// backoff 第 n 次失败后的重试间隔（毫秒），backoff_base * 2^(n-1)，不超过 backoff_max，并加上 10% 以内的随机抖动
+func backoff(n int) int64 {
+	cfg := srv.Config().Outbox
+
+	interval := int64(cfg.BackoffMax)
+	if n <= 30 {
+		if v := int64(cfg.BackoffBase) << (n - 1); v < interval {
+			interval = v
+		}
+	}
+
+	return interval + rand.Int63n(interval/10+1)
+}
+
+// truncate 按字符截断
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
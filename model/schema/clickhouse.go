@@ -0,0 +1,233 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/util"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/orm/database/sql/client"
+)
+
+func describeClickHouse(ctx context.Context, addr *util.DBAddress, tableName string) (*Schema, error) {
+	cli, err := client.NewClient(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	dbName := addr.Conn.DB
+
+	if isPattern(tableName) {
+		tableName, err = findClickHouseTable(ctx, cli, dbName, tableName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := Schema{}
+
+	query := "SELECT name, type, default_expression, comment, is_in_primary_key, is_in_sorting_key " +
+		"FROM system.columns WHERE database = ? AND table = ? ORDER BY position"
+
+	err = cli.Query(ctx, func(rows *sql.Rows) error {
+		var name, columnType, def, comment string
+		var inPrimaryKey, inSortingKey uint8
+
+		e := rows.Scan(&name, &columnType, &def, &comment, &inPrimaryKey, &inSortingKey)
+		if e != nil {
+			return e
+		}
+
+		baseType, nullable := clickHouseBaseType(columnType)
+
+		field := newField(name, clickHouseFieldType(baseType), "", nullable, def, comment)
+		field.IsPrimary = inPrimaryKey == 1
+		field.IsIndex = inPrimaryKey == 1 || inSortingKey == 1
+
+		if field.Type == FieldTypeEnum {
+			field.More = clickHouseEnumValues(baseType)
+		} else if strings.HasPrefix(baseType, "FixedString") || strings.HasPrefix(baseType, "Decimal") {
+			field.Len = typeLen(baseType)
+		}
+
+		ret.Fields = append(ret.Fields, field)
+		return nil
+	}, query, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret.Fields) == 0 {
+		return nil, errs.Newf(errs.RetWebNotFindTable, "not find table [%s] in db [%s]", tableName, dbName)
+	}
+
+	query = "SELECT create_table_query, primary_key, sorting_key FROM system.tables WHERE database = ? AND name = ?"
+
+	err = cli.Query(ctx, func(rows *sql.Rows) error {
+		var primaryKey, sortingKey string
+
+		e := rows.Scan(&ret.Definition, &primaryKey, &sortingKey)
+		if e != nil {
+			return e
+		}
+
+		ret.Indexs = clickHouseKeyIndex(ret.Indexs, "PRIMARY", IndexTypePrimary, primaryKey)
+		if sortingKey != primaryKey {
+			ret.Indexs = clickHouseKeyIndex(ret.Indexs, "ORDER BY", IndexTypeKey, sortingKey)
+		}
+		return nil
+	}, query, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	// 跳数索引，低版本 clickhouse 没有 system.data_skipping_indices，忽略错误
+	query = "SELECT name, type, expr FROM system.data_skipping_indices WHERE database = ? AND table = ?"
+
+	_ = cli.Query(ctx, func(rows *sql.Rows) error {
+		var name, typ, expr string
+
+		e := rows.Scan(&name, &typ, &expr)
+		if e != nil {
+			return e
+		}
+
+		ret.Indexs = append(ret.Indexs, &pb.TableIndex{Name: name, Type: typ, Fields: expr})
+		return nil
+	}, query, dbName, tableName)
+
+	return &ret, nil
+}
+
+// findClickHouseTable 查找第一个匹配分表通配的表
+func findClickHouseTable(ctx context.Context, cli client.Client, dbName, pattern string) (string, error) {
+	var tableName string
+
+	query := "SELECT name FROM system.tables WHERE database = ? AND name LIKE ? ORDER BY name LIMIT 1"
+
+	err := cli.Query(ctx, func(rows *sql.Rows) error {
+		return rows.Scan(&tableName)
+	}, query, dbName, likePattern(pattern))
+	if err != nil {
+		return "", err
+	}
+
+	if tableName == "" {
+		return "", errs.Newf(errs.RetWebNotFindTable, "not find table like [%s] in db [%s]", pattern, dbName)
+	}
+
+	return tableName, nil
+}
+
+// clickHouseKeyIndex 将主键/排序键表达式转为索引
+func clickHouseKeyIndex(indexs []*pb.TableIndex, name, typ, key string) []*pb.TableIndex {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return indexs
+	}
+
+	fields := strings.Split(key, ",")
+	for k, v := range fields {
+		fields[k] = strings.TrimSpace(v)
+	}
+
+	return append(indexs, &pb.TableIndex{Name: name, Type: typ, Fields: strings.Join(fields, ",")})
+}
+
+// clickHouseBaseType 去掉 Nullable、LowCardinality 包装，返回基础类型及是否可空
+func clickHouseBaseType(columnType string) (string, bool) {
+	nullable := false
+
+	for {
+		switch {
+		case strings.HasPrefix(columnType, "Nullable("):
+			nullable = true
+			columnType = columnType[len("Nullable(") : len(columnType)-1]
+		case strings.HasPrefix(columnType, "LowCardinality("):
+			columnType = columnType[len("LowCardinality(") : len(columnType)-1]
+		default:
+			return columnType, nullable
+		}
+	}
+}
+
+// clickHouseFieldType clickhouse 字段类型转换
+func clickHouseFieldType(baseType string) int8 {
+	name := baseType
+	if i := strings.Index(name, "("); i != -1 {
+		name = name[:i]
+	}
+
+	switch name {
+	case "Bool":
+		return FieldTypeBool
+	case "Int8":
+		return FieldTypeInt8
+	case "Int16":
+		return FieldTypeInt16
+	case "Int32":
+		return FieldTypeInt32
+	case "Int64":
+		return FieldTypeInt64
+	case "UInt8":
+		return FieldTypeUint8
+	case "UInt16":
+		return FieldTypeUint16
+	case "UInt32":
+		return FieldTypeUint32
+	case "UInt64":
+		return FieldTypeUint64
+	case "Float32":
+		return FieldTypeFloat
+	case "Float64", "Decimal", "Decimal32", "Decimal64", "Decimal128", "Decimal256":
+		return FieldTypeFloat64
+	case "Enum8", "Enum16", "Enum":
+		return FieldTypeEnum
+	case "Date", "Date32":
+		return FieldTypeDate
+	case "DateTime", "DateTime64":
+		return FieldTypeDatetime
+	case "Array", "Map", "Tuple", "Nested", "JSON", "Object":
+		return FieldTypeJSON
+	default:
+		return FieldTypeString
+	}
+}
+
+// clickHouseEnumValues 解析 Enum8('male' = 1, 'female' = 2) 的枚举值
+func clickHouseEnumValues(baseType string) string {
+	type enumValue struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	values := []*enumValue{}
+
+	for _, v := range strings.Split(typeLen(baseType), ",") {
+		name, id, _ := strings.Cut(v, "=")
+		value := enumValue{Name: strings.Trim(strings.TrimSpace(name), "'")}
+		value.ID, _ = strconv.Atoi(strings.TrimSpace(id))
+		values = append(values, &value)
+	}
+
+	more, _ := json.Api.MarshalToString(values)
+	return more
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"context"
+	"sort"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/types"
+	"github.com/horm-database/common/util"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/orm/database/elastic"
+	"github.com/horm-database/orm/database/elastic/client"
+)
+
+func describeElastic(ctx context.Context, addr *util.DBAddress, index string) (*Schema, error) {
+	var mappings map[string]interface{}
+
+	if addr.Version == elastic.ElasticV6 {
+		cli, err := client.NewClientV6(true, addr)
+		if err != nil {
+			return nil, errs.NewDBError(errs.RetElastic, err.Error())
+		}
+
+		mappings, err = cli.GetMapping().Index(index).Do(ctx)
+		if err != nil {
+			return nil, errs.NewDBError(errs.RetElastic, err.Error())
+		}
+	} else {
+		cli, err := client.NewClientV7(true, addr)
+		if err != nil {
+			return nil, errs.NewDBError(errs.RetElastic, err.Error())
+		}
+
+		mappings, err = cli.GetMapping().Index(index).Do(ctx)
+		if err != nil {
+			return nil, errs.NewDBError(errs.RetElastic, err.Error())
+		}
+	}
+
+	if len(mappings) == 0 {
+		return nil, errs.Newf(errs.RetWebNotFindTable, "not find index [%s]", index)
+	}
+
+	// 通配时取第一个匹配的索引
+	indexNames := make([]string, 0, len(mappings))
+	for name := range mappings {
+		indexNames = append(indexNames, name)
+	}
+	sort.Strings(indexNames)
+
+	indexMapping, _ := mappings[indexNames[0]].(map[string]interface{})
+	mapping, _ := indexMapping["mappings"].(map[string]interface{})
+
+	ret := Schema{}
+	definition, _ := json.Api.MarshalIndent(mapping, "", "  ")
+	ret.Definition = string(definition)
+
+	// v6 mappings 下还有一层 type
+	if _, ok := mapping["properties"]; !ok {
+		for _, v := range mapping {
+			if typeMapping, ok := v.(map[string]interface{}); ok {
+				if _, ok = typeMapping["properties"]; ok {
+					mapping = typeMapping
+					break
+				}
+			}
+		}
+	}
+
+	properties, _ := mapping["properties"].(map[string]interface{})
+	ret.Fields = elasticFields(ret.Fields, "", properties)
+
+	return &ret, nil
+}
+
+// elasticFields 递归解析 mapping properties，object 子字段以 a.b 表示
+func elasticFields(fields []*pb.TableField, prefix string, properties map[string]interface{}) []*pb.TableField {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, _ := properties[name].(map[string]interface{})
+		typ := types.InterfaceToString(property["type"])
+
+		field := newField(prefix+name, elasticFieldType(typ), "", true, "", "")
+		field.IsIndex = property["index"] != false && typ != "object" && typ != "nested"
+
+		// more 记录 elastic 原始类型，例如 keyword、date(yyyy-MM-dd)
+		field.More = typ
+		if format := types.InterfaceToString(property["format"]); format != "" {
+			field.More += "(" + format + ")"
+		}
+
+		fields = append(fields, field)
+
+		if sub, ok := property["properties"].(map[string]interface{}); ok {
+			fields = elasticFields(fields, prefix+name+".", sub)
+		}
+	}
+
+	return fields
+}
+
+// elasticFieldType elastic 字段类型转换
+func elasticFieldType(typ string) int8 {
+	switch typ {
+	case "boolean":
+		return FieldTypeBool
+	case "byte":
+		return FieldTypeInt8
+	case "short":
+		return FieldTypeInt16
+	case "integer":
+		return FieldTypeInt32
+	case "long":
+		return FieldTypeInt64
+	case "unsigned_long":
+		return FieldTypeUint64
+	case "float", "half_float":
+		return FieldTypeFloat
+	case "double", "scaled_float":
+		return FieldTypeFloat64
+	case "binary":
+		return FieldTypeBytes
+	case "date", "date_nanos":
+		return FieldTypeDatetime
+	case "", "object", "nested", "flattened":
+		return FieldTypeJSON
+	default:
+		return FieldTypeString
+	}
+}
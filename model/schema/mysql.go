@@ -0,0 +1,207 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/util"
+	"github.com/horm-database/orm/database/sql/client"
+)
+
+func describeMySQL(ctx context.Context, addr *util.DBAddress, tableName string) (*Schema, error) {
+	cli, err := client.NewClient(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	dbName := addr.Conn.DB
+
+	if isPattern(tableName) {
+		tableName, err = findMySQLTable(ctx, cli, dbName, tableName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := Schema{}
+
+	query := "SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, COLUMN_COMMENT " +
+		"FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION"
+
+	err = cli.Query(ctx, func(rows *sql.Rows) error {
+		var name, dataType, columnType, nullable, comment string
+		var def sql.NullString
+
+		e := rows.Scan(&name, &dataType, &columnType, &nullable, &def, &comment)
+		if e != nil {
+			return e
+		}
+
+		field := newField(name, mysqlFieldType(dataType, columnType), "",
+			nullable == "YES", def.String, comment)
+
+		if field.Type == FieldTypeEnum {
+			field.More = enumValues(columnType)
+		} else if field.Type != FieldTypeBool {
+			field.Len = typeLen(columnType)
+		}
+
+		ret.Fields = append(ret.Fields, field)
+		return nil
+	}, query, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret.Fields) == 0 {
+		return nil, errs.Newf(errs.RetWebNotFindTable, "not find table [%s] in db [%s]", tableName, dbName)
+	}
+
+	query = "SELECT INDEX_NAME, NON_UNIQUE, INDEX_TYPE, COLUMN_NAME FROM information_schema.STATISTICS " +
+		"WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY INDEX_NAME, SEQ_IN_INDEX"
+
+	err = cli.Query(ctx, func(rows *sql.Rows) error {
+		var name, indexType, column string
+		var nonUnique int
+
+		e := rows.Scan(&name, &nonUnique, &indexType, &column)
+		if e != nil {
+			return e
+		}
+
+		typ := IndexTypeKey
+		if name == "PRIMARY" {
+			typ = IndexTypePrimary
+		} else if indexType == "FULLTEXT" {
+			typ = IndexTypeFullText
+		} else if nonUnique == 0 {
+			typ = IndexTypeUnique
+		}
+
+		ret.Indexs = addIndex(ret.Indexs, ret.Fields, name, typ, column)
+		return nil
+	}, query, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	query = fmt.Sprintf("SHOW CREATE TABLE `%s`", strings.ReplaceAll(tableName, "`", "``"))
+
+	err = cli.Query(ctx, func(rows *sql.Rows) error {
+		var name string
+		return rows.Scan(&name, &ret.Definition)
+	}, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
+
+// findMySQLTable 查找第一个匹配分表通配的表
+func findMySQLTable(ctx context.Context, cli client.Client, dbName, pattern string) (string, error) {
+	var tableName string
+
+	query := "SELECT TABLE_NAME FROM information_schema.TABLES " +
+		"WHERE TABLE_SCHEMA = ? AND TABLE_NAME LIKE ? ORDER BY TABLE_NAME LIMIT 1"
+
+	err := cli.Query(ctx, func(rows *sql.Rows) error {
+		return rows.Scan(&tableName)
+	}, query, dbName, likePattern(pattern))
+	if err != nil {
+		return "", err
+	}
+
+	if tableName == "" {
+		return "", errs.Newf(errs.RetWebNotFindTable, "not find table like [%s] in db [%s]", pattern, dbName)
+	}
+
+	return tableName, nil
+}
+
+// mysqlFieldType mysql 字段类型转换
+func mysqlFieldType(dataType, columnType string) int8 {
+	unsigned := strings.Contains(columnType, "unsigned")
+
+	switch strings.ToLower(dataType) {
+	case "tinyint":
+		if strings.HasPrefix(columnType, "tinyint(1)") {
+			return FieldTypeBool
+		}
+		if unsigned {
+			return FieldTypeUint8
+		}
+		return FieldTypeInt8
+	case "smallint":
+		if unsigned {
+			return FieldTypeUint16
+		}
+		return FieldTypeInt16
+	case "mediumint", "int", "integer":
+		if unsigned {
+			return FieldTypeUint
+		}
+		return FieldTypeInt
+	case "bigint":
+		if unsigned {
+			return FieldTypeUint64
+		}
+		return FieldTypeInt64
+	case "bit":
+		if columnType == "bit(1)" {
+			return FieldTypeBool
+		}
+		return FieldTypeBytes
+	case "float":
+		return FieldTypeFloat
+	case "double", "decimal", "numeric", "real":
+		return FieldTypeFloat64
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		return FieldTypeBytes
+	case "enum":
+		return FieldTypeEnum
+	case "json":
+		return FieldTypeJSON
+	case "date":
+		return FieldTypeDate
+	case "datetime", "timestamp":
+		return FieldTypeDatetime
+	case "year":
+		return FieldTypeInt
+	default:
+		return FieldTypeString
+	}
+}
+
+// enumValues 解析 enum('a','b') 的枚举值，返回 json 数组
+func enumValues(columnType string) string {
+	values := []string{}
+
+	for _, v := range strings.Split(typeLen(columnType), ",") {
+		v = strings.TrimSpace(v)
+		v = strings.TrimPrefix(v, "'")
+		v = strings.TrimSuffix(v, "'")
+		values = append(values, strings.ReplaceAll(v, "''", "'"))
+	}
+
+	more, _ := json.Api.MarshalToString(values)
+	return more
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/types"
+	"github.com/horm-database/common/util"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/orm/database/sql/client"
+)
+
+func describePostgreSQL(ctx context.Context, addr *util.DBAddress, tableName string) (*Schema, error) {
+	cli, err := client.NewClient(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// 支持 schema.table 格式，默认 public
+	schemaName := "public"
+	if found, s1, s2 := types.CutString(tableName, "."); found {
+		schemaName, tableName = s1, s2
+	}
+
+	if isPattern(tableName) {
+		tableName, err = findPostgreSQLTable(ctx, cli, schemaName, tableName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := Schema{}
+	columnTypes := map[string]string{}
+
+	query := "SELECT a.attname, format_type(a.atttypid, a.atttypmod), t.typname, t.typtype, NOT a.attnotnull, " +
+		"COALESCE(pg_get_expr(d.adbin, d.adrelid), ''), COALESCE(col_description(a.attrelid, a.attnum), ''), " +
+		"COALESCE((SELECT string_agg(e.enumlabel, ',' ORDER BY e.enumsortorder) FROM pg_enum e WHERE e.enumtypid = a.atttypid), '') " +
+		"FROM pg_attribute a " +
+		"JOIN pg_class c ON c.oid = a.attrelid " +
+		"JOIN pg_namespace n ON n.oid = c.relnamespace " +
+		"JOIN pg_type t ON t.oid = a.atttypid " +
+		"LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum " +
+		"WHERE n.nspname = $1 AND c.relname = $2 AND a.attnum > 0 AND NOT a.attisdropped " +
+		"ORDER BY a.attnum"
+
+	err = cli.Query(ctx, func(rows *sql.Rows) error {
+		var name, columnType, udtName, typType, def, comment, enums string
+		var nullable bool
+
+		e := rows.Scan(&name, &columnType, &udtName, &typType, &nullable, &def, &comment, &enums)
+		if e != nil {
+			return e
+		}
+
+		typ := postgreSQLFieldType(udtName)
+		if typType == "e" {
+			typ = FieldTypeEnum
+		}
+
+		field := newField(name, typ, typeLen(columnType), nullable, def, comment)
+		if typ == FieldTypeEnum {
+			field.More, _ = json.Api.MarshalToString(strings.Split(enums, ","))
+		}
+
+		columnTypes[name] = columnType
+		ret.Fields = append(ret.Fields, field)
+		return nil
+	}, query, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret.Fields) == 0 {
+		return nil, errs.Newf(errs.RetWebNotFindTable, "not find table [%s.%s]", schemaName, tableName)
+	}
+
+	var indexDefs []string
+
+	query = "SELECT i.relname, ix.indisprimary, ix.indisunique, a.attname, pg_get_indexdef(ix.indexrelid) " +
+		"FROM pg_index ix " +
+		"JOIN pg_class t ON t.oid = ix.indrelid " +
+		"JOIN pg_namespace n ON n.oid = t.relnamespace " +
+		"JOIN pg_class i ON i.oid = ix.indexrelid " +
+		"JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey) " +
+		"WHERE n.nspname = $1 AND t.relname = $2 " +
+		"ORDER BY i.relname, array_position(ix.indkey::int2[], a.attnum)"
+
+	err = cli.Query(ctx, func(rows *sql.Rows) error {
+		var name, column, indexDef string
+		var isPrimary, isUnique bool
+
+		e := rows.Scan(&name, &isPrimary, &isUnique, &column, &indexDef)
+		if e != nil {
+			return e
+		}
+
+		typ := IndexTypeKey
+		if isPrimary {
+			typ = IndexTypePrimary
+		} else if isUnique {
+			typ = IndexTypeUnique
+		}
+
+		if !isPrimary && !containsIndex(ret.Indexs, name) {
+			indexDefs = append(indexDefs, indexDef+";")
+		}
+
+		ret.Indexs = addIndex(ret.Indexs, ret.Fields, name, typ, column)
+		return nil
+	}, query, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.Definition = postgreSQLDefinition(schemaName, tableName, ret.Fields, ret.Indexs, columnTypes, indexDefs)
+	return &ret, nil
+}
+
+// findPostgreSQLTable 查找第一个匹配分表通配的表
+func findPostgreSQLTable(ctx context.Context, cli client.Client, schemaName, pattern string) (string, error) {
+	var tableName string
+
+	query := "SELECT table_name FROM information_schema.tables " +
+		"WHERE table_schema = $1 AND table_name LIKE $2 ORDER BY table_name LIMIT 1"
+
+	err := cli.Query(ctx, func(rows *sql.Rows) error {
+		return rows.Scan(&tableName)
+	}, query, schemaName, likePattern(pattern))
+	if err != nil {
+		return "", err
+	}
+
+	if tableName == "" {
+		return "", errs.Newf(errs.RetWebNotFindTable, "not find table like [%s.%s]", schemaName, pattern)
+	}
+
+	return tableName, nil
+}
+
+// postgreSQLDefinition postgresql 没有 show create table，根据字段和索引拼接建表语句
+func postgreSQLDefinition(schemaName, tableName string, fields []*pb.TableField,
+	indexs []*pb.TableIndex, columnTypes map[string]string, indexDefs []string) string {
+	lines := []string{}
+
+	for _, field := range fields {
+		line := fmt.Sprintf("  %q %s", field.Field, columnTypes[field.Field])
+		if !field.Empty {
+			line += " NOT NULL"
+		}
+		if field.Default != "" {
+			line += " DEFAULT " + field.Default
+		}
+		lines = append(lines, line)
+	}
+
+	for _, index := range indexs {
+		if index.Type == IndexTypePrimary {
+			lines = append(lines, fmt.Sprintf("  CONSTRAINT %q PRIMARY KEY (%s)", index.Name, index.Fields))
+		}
+	}
+
+	definition := fmt.Sprintf("CREATE TABLE %q.%q (\n%s\n);", schemaName, tableName, strings.Join(lines, ",\n"))
+
+	for _, indexDef := range indexDefs {
+		definition += "\n" + indexDef
+	}
+
+	for _, field := range fields {
+		if field.Comment != "" {
+			definition += fmt.Sprintf("\nCOMMENT ON COLUMN %q.%q.%q IS '%s';",
+				schemaName, tableName, field.Field, strings.ReplaceAll(field.Comment, "'", "''"))
+		}
+	}
+
+	return definition
+}
+
+// postgreSQLFieldType postgresql 字段类型转换
+func postgreSQLFieldType(udtName string) int8 {
+	if strings.HasPrefix(udtName, "_") { // 数组
+		return FieldTypeJSON
+	}
+
+	switch udtName {
+	case "bool":
+		return FieldTypeBool
+	case "int2":
+		return FieldTypeInt16
+	case "int4":
+		return FieldTypeInt32
+	case "int8":
+		return FieldTypeInt64
+	case "float4":
+		return FieldTypeFloat
+	case "float8", "numeric", "money":
+		return FieldTypeFloat64
+	case "bytea":
+		return FieldTypeBytes
+	case "json", "jsonb":
+		return FieldTypeJSON
+	case "date":
+		return FieldTypeDate
+	case "timestamp", "timestamptz":
+		return FieldTypeDatetime
+	default:
+		return FieldTypeString
+	}
+}
+
+func containsIndex(indexs []*pb.TableIndex, name string) bool {
+	for _, index := range indexs {
+		if index.Name == name {
+			return true
+		}
+	}
+	return false
+}
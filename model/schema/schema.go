@@ -0,0 +1,178 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema 连接表所属数据库，读取真实的表结构（字段、索引、建表语句）
+package schema
+
+import (
+	"context"
+	"strings"
+
+	"github.com/horm-database/common/consts"
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/util"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/orm/obj"
+)
+
+// 表字段类型，同 pb.TableField.Type
+const (
+	FieldTypeBool     int8 = 1
+	FieldTypeString   int8 = 2
+	FieldTypeInt      int8 = 3
+	FieldTypeInt8     int8 = 4
+	FieldTypeInt16    int8 = 5
+	FieldTypeInt32    int8 = 6
+	FieldTypeInt64    int8 = 7
+	FieldTypeUint     int8 = 8
+	FieldTypeUint8    int8 = 9
+	FieldTypeUint16   int8 = 10
+	FieldTypeUint32   int8 = 11
+	FieldTypeUint64   int8 = 12
+	FieldTypeFloat    int8 = 13
+	FieldTypeFloat64  int8 = 14
+	FieldTypeBytes    int8 = 15
+	FieldTypeEnum     int8 = 16
+	FieldTypeJSON     int8 = 17
+	FieldTypeDate     int8 = 18
+	FieldTypeDatetime int8 = 19
+)
+
+// 索引类型
+const (
+	IndexTypePrimary  = "PRIMARYKEY"
+	IndexTypeUnique   = "UNIQUEKEY"
+	IndexTypeKey      = "KEY"
+	IndexTypeFullText = "FULLTEXT"
+)
+
+// Schema 表结构
+type Schema struct {
+	Fields     []*pb.TableField // 表字段
+	Indexs     []*pb.TableIndex // 表索引
+	Definition string           // 表定义（建表语句或 mapping）
+}
+
+// Describe 读取 db 库中 tableName 表的真实结构，tableName 支持以 * 结尾的分表通配，取第一个匹配的表。
+func Describe(ctx context.Context, db *obj.TblDB, tableName string) (*Schema, error) {
+	if tableName == "" {
+		return nil, errs.Newf(errs.RetWebParamEmpty, "table name can`t be empty")
+	}
+
+	addr, err := GetDBAddress(db)
+	if err != nil {
+		return nil, err
+	}
+
+	switch addr.Type {
+	case consts.DBTypeMySQL:
+		return describeMySQL(ctx, addr, tableName)
+	case consts.DBTypePostgreSQL:
+		return describePostgreSQL(ctx, addr, tableName)
+	case consts.DBTypeClickHouse:
+		return describeClickHouse(ctx, addr, tableName)
+	case consts.DBTypeElastic:
+		return describeElastic(ctx, addr, tableName)
+	default:
+		return nil, errs.Newf(errs.RetOpNotSupport, "db type [%d] not support schema introspection", addr.Type)
+	}
+}
+
+// GetDBAddress 根据库信息生成连接地址
+func GetDBAddress(db *obj.TblDB) (*util.DBAddress, error) {
+	if db.Addr != nil && db.Addr.Conn != nil {
+		return db.Addr, nil
+	}
+
+	addr := &util.DBAddress{
+		Type:         db.Type,
+		Version:      db.Version,
+		Network:      db.Network,
+		Address:      db.Address,
+		WriteTimeout: db.WriteTimeoutTmp,
+		ReadTimeout:  db.ReadTimeoutTmp,
+		WarnTimeout:  db.WarnTimeoutTmp,
+		OmitError:    db.OmitErrorTmp,
+		Debug:        db.DebugTmp,
+	}
+
+	if addr.Network == "" {
+		addr.Network = "TCP"
+	}
+
+	err := util.ParseConnFromAddress(addr)
+	if err != nil {
+		return nil, errs.Newf(errs.RetDBAddressParseError, "db [%d] address parse error: %v", db.Id, err)
+	}
+
+	db.Addr = addr
+	return addr, nil
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// isPattern 是否分表通配
+func isPattern(tableName string) bool {
+	return strings.Contains(tableName, "*")
+}
+
+// likePattern 将通配 * 转为 sql like 语句
+func likePattern(tableName string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `*`, `%`)
+	return replacer.Replace(tableName)
+}
+
+// typeLen 获取类型括号中的长度，例如 varchar(64) 返回 64，decimal(10,2) 返回 10,2
+func typeLen(columnType string) string {
+	start := strings.Index(columnType, "(")
+	end := strings.LastIndex(columnType, ")")
+	if start == -1 || end <= start {
+		return ""
+	}
+
+	return strings.TrimSpace(columnType[start+1 : end])
+}
+
+// addIndex 将索引列归入索引，并标记字段索引属性
+func addIndex(indexs []*pb.TableIndex, fields []*pb.TableField, name, typ, column string) []*pb.TableIndex {
+	for _, field := range fields {
+		if field.Field == column {
+			field.IsIndex = true
+			if typ == IndexTypePrimary {
+				field.IsPrimary = true
+			}
+		}
+	}
+
+	for _, index := range indexs {
+		if index.Name == name {
+			index.Fields += "," + column
+			return indexs
+		}
+	}
+
+	return append(indexs, &pb.TableIndex{Name: name, Type: typ, Fields: column})
+}
+
+func newField(name string, typ int8, length string, empty bool, def, comment string) *pb.TableField {
+	return &pb.TableField{
+		Field:   name,
+		Type:    typ,
+		Len:     length,
+		Empty:   empty,
+		Status:  1,
+		Default: def,
+		Comment: comment,
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+
+	"github.com/horm-database/common/proto"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/consts"
+)
+
+// accessExpireAuditKey 权限有效期以 (access_type, access_id) 唯一确定
+const accessExpireAuditKey = "access_type,access_id"
+
+func InsertAccessExpire(ctx context.Context, expire *TblAccessExpire) (int, error) {
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_access_expire").Insert(expire).Exec(ctx, &modRet)
+	if err != nil {
+		return 0, err
+	}
+
+	auditCreate(ctx, "tbl_access_expire", accessExpireAuditKey, horm.Where{"id": modRet.ID.Int()})
+
+	return modRet.ID.Int(), nil
+}
+
+func UpdateAccessExpireByID(ctx context.Context, id int, update horm.Map) error {
+	return auditWrite(ctx, "tbl_access_expire", accessExpireAuditKey, horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_access_expire").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
+}
+
+// GetAccessExpire 权限记录的有效期
+func GetAccessExpire(ctx context.Context, accessType int8, accessID int) (bool, *TblAccessExpire, error) {
+	expire := TblAccessExpire{}
+
+	where := horm.Where{
+		"access_type": accessType,
+		"access_id":   accessID,
+	}
+
+	isNil, err := GetTableORM("tbl_access_expire").Find(where).Exec(ctx, &expire)
+
+	return isNil, &expire, err
+}
+
+// GetAccessExpires 批量获取权限记录的有效期
+func GetAccessExpires(ctx context.Context, accessType int8, accessIDs []int) ([]*TblAccessExpire, error) {
+	expires := []*TblAccessExpire{}
+
+	if len(accessIDs) == 0 {
+		return expires, nil
+	}
+
+	where := horm.Where{
+		"access_type": accessType,
+		"access_id":   accessIDs,
+	}
+
+	_, err := GetTableORM("tbl_access_expire").FindAll(where).Exec(ctx, &expires)
+
+	return expires, err
+}
+
+// GetRenewingAccessExpires 所有续期审批中的权限记录
+func GetRenewingAccessExpires(ctx context.Context, accessType int8) ([]*TblAccessExpire, error) {
+	expires := []*TblAccessExpire{}
+
+	where := horm.Where{
+		"access_type":  accessType,
+		"renew_status": consts.AccessRenewStatusApproval,
+	}
+
+	_, err := GetTableORM("tbl_access_expire").FindAll(where).Exec(ctx, &expires)
+
+	return expires, err
+}
+
+// GetExpiredAccessExpires 已过期但还未下线的权限记录
+func GetExpiredAccessExpires(ctx context.Context, now int64, limit int) ([]*TblAccessExpire, error) {
+	expires := []*TblAccessExpire{}
+
+	where := horm.Where{
+		"expire_time >":  0,
+		"expire_time <=": now,
+		"offline_time":   0,
+	}
+
+	_, err := GetTableORM("tbl_access_expire").FindAll(where).Order("expire_time").Limit(limit).Exec(ctx, &expires)
+
+	return expires, err
+}
+
+// GetExpiringAccessExpires 在 before 之前过期且还未提醒的权限记录
+func GetExpiringAccessExpires(ctx context.Context, now, before int64, limit int) ([]*TblAccessExpire, error) {
+	expires := []*TblAccessExpire{}
+
+	where := horm.Where{
+		"expire_time >":  now,
+		"expire_time <=": before,
+		"remind_time":    0,
+	}
+
+	_, err := GetTableORM("tbl_access_expire").FindAll(where).Order("expire_time").Limit(limit).Exec(ctx, &expires)
+
+	return expires, err
+}
+
+// ClaimAccessExpire 抢占到期处理，字段 field（remind_time/offline_time）在读取之后未被其他实例修改才能抢占成功，
+// 同一过期时间只会被提醒、下线一次。
+func ClaimAccessExpire(ctx context.Context, expire *TblAccessExpire, field string, now int64) (bool, error) {
+	where := horm.Where{
+		"id":          expire.Id,
+		"expire_time": expire.ExpireTime,
+		field:         0,
+	}
+
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_access_expire").Update(horm.Map{field: now}, where).Exec(ctx, &modRet)
+	if err != nil {
+		return false, err
+	}
+
+	return modRet.RowAffected > 0, nil
+}
+
+// ReleaseAccessExpire 处理失败时释放抢占，使下次扫描能重新处理
+func ReleaseAccessExpire(ctx context.Context, expire *TblAccessExpire, field string, claimed int64) error {
+	where := horm.Where{
+		"id":  expire.Id,
+		field: claimed,
+	}
+
+	_, err := GetTableORM("tbl_access_expire").Update(horm.Map{field: 0}, where).Exec(ctx)
+	return err
+}
@@ -26,12 +26,19 @@ import (
 
 func AddApp(ctx context.Context, appInfo *table.TblAppInfo) error {
 	_, err := GetTableORM("tbl_app_info").Insert(appInfo).Exec(ctx)
-	return err
+	if err != nil {
+		return err
+	}
+
+	auditCreate(ctx, "tbl_app_info", "appid", horm.Where{"appid": appInfo.Appid})
+	return nil
 }
 
 func UpdateAppByID(ctx context.Context, appid uint64, update horm.Map) error {
-	_, err := GetTableORM("tbl_app_info").Eq("appid", appid).Update(update).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_app_info", "appid", horm.Where{"appid": appid}, func() error {
+		_, err := GetTableORM("tbl_app_info").Eq("appid", appid).Update(update).Exec(ctx)
+		return err
+	})
 }
 
 func GetAppList(ctx context.Context, userid uint64, page, size int) (*proto.Detail, []*table.TblAppInfo, error) {
@@ -40,7 +47,7 @@ func GetAppList(ctx context.Context, userid uint64, page, size int) (*proto.Deta
 	apps := []*table.TblAppInfo{}
 
 	// 在线的应用和我管理的应用。
-	where := horm.Where{
+	where, ok, err := scopeApps(ctx, horm.Where{
 		"OR": horm.Where{
 			"status": consts.StatusOnline,
 			"AND": horm.Where{
@@ -48,9 +55,12 @@ func GetAppList(ctx context.Context, userid uint64, page, size int) (*proto.Deta
 				"manager ~": "%" + fmt.Sprint(userid) + "%",
 			},
 		},
+	})
+	if err != nil || !ok {
+		return &pageRet, apps, err
 	}
 
-	_, err := GetTableORM("tbl_app_info").FindAll(where).Order("-appid").Page(page, size).Exec(ctx, &pageRet, &apps)
+	_, err = GetTableORM("tbl_app_info").FindAll(where).Order("-appid").Page(page, size).Exec(ctx, &pageRet, &apps)
 
 	return &pageRet, apps, err
 }
@@ -58,7 +68,12 @@ func GetAppList(ctx context.Context, userid uint64, page, size int) (*proto.Deta
 func GetAppListByAppids(ctx context.Context, appids []uint64) ([]*table.TblAppInfo, error) {
 	apps := []*table.TblAppInfo{}
 
-	_, err := GetTableORM("tbl_app_info").FindAllBy("appid", appids).Exec(ctx, &apps)
+	where, ok, err := scopeApps(ctx, horm.Where{"appid": appids})
+	if err != nil || !ok {
+		return apps, err
+	}
+
+	_, err = GetTableORM("tbl_app_info").FindAll(where).Exec(ctx, &apps)
 
 	return apps, err
 }
@@ -82,7 +97,12 @@ func GetMyAppListByKeyword(ctx context.Context, userid uint64,
 		}
 	}
 
-	_, err := GetTableORM("tbl_app_info").FindAll(where).Order("-appid").Exec(ctx, &apps)
+	where, ok, err := scopeApps(ctx, where)
+	if err != nil || !ok {
+		return apps, err
+	}
+
+	_, err = GetTableORM("tbl_app_info").FindAll(where).Order("-appid").Exec(ctx, &apps)
 
 	return apps, err
 }
@@ -90,9 +110,12 @@ func GetMyAppListByKeyword(ctx context.Context, userid uint64,
 func GetAppDetail(ctx context.Context, appid uint64) (bool, *table.TblAppInfo, error) {
 	app := table.TblAppInfo{}
 
-	isNil, err := GetTableORM("tbl_app_info").
-		FindBy("appid", appid).
-		Exec(ctx, &app)
+	where, ok, err := scopeApps(ctx, horm.Where{"appid": appid})
+	if err != nil || !ok {
+		return true, &app, err
+	}
+
+	isNil, err := GetTableORM("tbl_app_info").Find(where).Exec(ctx, &app)
 
 	return isNil, &app, err
 }
@@ -29,12 +29,16 @@ func InsertAccessDB(ctx context.Context, accessDB *table.TblAccessDB) (int, erro
 		return 0, err
 	}
 
+	auditCreate(ctx, "tbl_access_db", "id", horm.Where{"id": modRet.ID.Int()})
+
 	return modRet.ID.Int(), nil
 }
 
 func UpdateAccessDBByID(ctx context.Context, id int, update horm.Map) error {
-	_, err := GetTableORM("tbl_access_db").Eq("id", id).Update(update).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_access_db", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_access_db").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
 }
 
 func GetAppAccessDBs(ctx context.Context, appids []uint64, db int) ([]*table.TblAccessDB, error) {
@@ -110,3 +114,33 @@ func GetAppAccessDBListByAppid(ctx context.Context, appid uint64, page, size int
 
 	return &pageRet, accessDBs, err
 }
+
+// GetAccessDBsByStatus 多个仓库指定状态的应用接入记录，如审核中的申请
+func GetAccessDBsByStatus(ctx context.Context, dbIDs []int, status int8) ([]*table.TblAccessDB, error) {
+	accessDBs := []*table.TblAccessDB{}
+
+	if len(dbIDs) == 0 {
+		return accessDBs, nil
+	}
+
+	where := horm.Where{
+		"db":     dbIDs,
+		"status": status,
+	}
+
+	_, err := GetTableORM("tbl_access_db").FindAll(where).Exec(ctx, &accessDBs)
+
+	return accessDBs, err
+}
+
+func GetAccessDBsByIds(ctx context.Context, ids []int) ([]*table.TblAccessDB, error) {
+	accessDBs := []*table.TblAccessDB{}
+
+	if len(ids) == 0 {
+		return accessDBs, nil
+	}
+
+	_, err := GetTableORM("tbl_access_db").FindAll(horm.Where{"id": ids}).Exec(ctx, &accessDBs)
+
+	return accessDBs, err
+}
@@ -53,12 +53,16 @@ func InsertAccessTable(ctx context.Context, accessTable *table.TblAccessTable) (
 		return 0, err
 	}
 
+	auditCreate(ctx, "tbl_access_table", "id", horm.Where{"id": modRet.ID.Int()})
+
 	return modRet.ID.Int(), nil
 }
 
 func UpdateAccessTableByID(ctx context.Context, id int, update horm.Map) error {
-	_, err := GetTableORM("tbl_access_table").Eq("id", id).Update(update).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_access_table", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_access_table").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
 }
 
 func GetAppAccessTableListByTableID(ctx context.Context,
@@ -112,3 +116,33 @@ func GetAppAccessTableListByAppid(ctx context.Context,
 
 	return &pageRet, accessTables, err
 }
+
+// GetAccessTablesByStatus 多个表指定状态的应用接入记录，如审核中的申请
+func GetAccessTablesByStatus(ctx context.Context, tableIDs []int, status int8) ([]*table.TblAccessTable, error) {
+	accessTables := []*table.TblAccessTable{}
+
+	if len(tableIDs) == 0 {
+		return accessTables, nil
+	}
+
+	where := horm.Where{
+		"table_id": tableIDs,
+		"status":   status,
+	}
+
+	_, err := GetTableORM("tbl_access_table").FindAll(where).Exec(ctx, &accessTables)
+
+	return accessTables, err
+}
+
+func GetAccessTablesByIds(ctx context.Context, ids []int) ([]*table.TblAccessTable, error) {
+	accessTables := []*table.TblAccessTable{}
+
+	if len(ids) == 0 {
+		return accessTables, nil
+	}
+
+	_, err := GetTableORM("tbl_access_table").FindAll(horm.Where{"id": ids}).Exec(ctx, &accessTables)
+
+	return accessTables, err
+}
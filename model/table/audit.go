@@ -0,0 +1,353 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/horm-database/common/codec"
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/json"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/common/proto"
+	"github.com/horm-database/common/types"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/srv/transport/web/head"
+)
+
+// 管理数据（product、db、table、app、权限、插件、空间成员等）的写操作都通过 auditWrite/auditCreate 记录审计日志，
+// 用户个人数据（账号、会话、收藏）及系统内部数据（检索信息、异步任务、序列号）不记录。
+
+// AuditLogFilter 审计日志查询条件，零值表示不过滤
+type AuditLogFilter struct {
+	WorkspaceID int
+	UserID      uint64
+	Api         string
+	EntityType  string
+	EntityID    string
+	StartTime   int64 // 开始时间（秒）
+	EndTime     int64 // 结束时间（秒）
+}
+
+// auditIgnoreFields 不计入变更内容的字段
+var auditIgnoreFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// auditMaskWords 字段名包含这些词时变更内容脱敏
+var auditMaskWords = []string{"secret", "password", "token"}
+
+func GetAuditLogs(ctx context.Context, filter *AuditLogFilter, page, size int) (*proto.Detail, []*TblAuditLog, error) {
+	pageRet := proto.Detail{}
+
+	logs := []*TblAuditLog{}
+
+	where := horm.Where{}
+	if filter.WorkspaceID > 0 {
+		where["workspace_id"] = filter.WorkspaceID
+	}
+
+	if filter.UserID > 0 {
+		where["userid"] = filter.UserID
+	}
+
+	if filter.Api != "" {
+		where["api"] = filter.Api
+	}
+
+	if filter.EntityType != "" {
+		where["entity_type"] = filter.EntityType
+	}
+
+	if filter.EntityID != "" {
+		where["entity_id"] = filter.EntityID
+	}
+
+	if filter.StartTime > 0 {
+		where["created_at >="] = time.Unix(filter.StartTime, 0)
+	}
+
+	if filter.EndTime > 0 {
+		where["created_at <="] = time.Unix(filter.EndTime, 0)
+	}
+
+	_, err := GetTableORM("tbl_audit_log").
+		FindAll(where).
+		Order("-id").
+		Page(page, size).
+		Exec(ctx, &pageRet, &logs)
+
+	return &pageRet, logs, err
+}
+
+// auditWrite 执行写操作，并按写操作前后 where 命中的记录生成审计日志，key 为对象id列，多列以逗号分隔
+func auditWrite(ctx context.Context, tbl, key string, where horm.Where, write func() error) error {
+	before, err := findAuditRows(ctx, tbl, where)
+	if err != nil {
+		return err
+	}
+
+	err = write()
+	if err != nil {
+		return err
+	}
+
+	after, err := findAuditRows(ctx, tbl, where)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "audit [%s] read rows after write error: %v", tbl, err)
+		return nil
+	}
+
+	addAuditLogs(ctx, tbl, key, before, after)
+
+	return nil
+}
+
+// auditCreate 新增记录之后调用，生成 where 命中记录的新增审计日志
+func auditCreate(ctx context.Context, tbl, key string, where horm.Where) {
+	after, err := findAuditRows(ctx, tbl, where)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "audit [%s] read created row error: %v", tbl, err)
+		return
+	}
+
+	addAuditLogs(ctx, tbl, key, nil, after)
+}
+
+func findAuditRows(ctx context.Context, tbl string, where horm.Where) ([]map[string]interface{}, error) {
+	rows := []map[string]interface{}{}
+
+	_, err := GetTableORM(tbl).FindAll(where).Exec(ctx, &rows)
+
+	return rows, err
+}
+
+// addAuditLogs 对比写操作前后的记录生成审计日志，审计日志写入失败只记录错误，不影响业务
+func addAuditLogs(ctx context.Context, tbl, key string, before, after []map[string]interface{}) {
+	beforeMap := map[string]map[string]interface{}{}
+	for _, row := range before {
+		beforeMap[auditEntityID(row, key)] = row
+	}
+
+	afterMap := map[string]map[string]interface{}{}
+	for _, row := range after {
+		afterMap[auditEntityID(row, key)] = row
+	}
+
+	ids := []string{}
+	for _, row := range before {
+		ids = append(ids, auditEntityID(row, key))
+	}
+	for _, row := range after {
+		if id := auditEntityID(row, key); beforeMap[id] == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	actor := &head.WebReqHeader{}
+	msg := codec.Message(ctx)
+	if h, ok := msg.ServerReqHead().(*head.WebReqHeader); ok && h != nil {
+		actor = h
+	}
+
+	logs := []*TblAuditLog{}
+	for _, id := range ids {
+		var action int8
+		switch {
+		case beforeMap[id] == nil:
+			action = consts.AuditActionCreate
+		case afterMap[id] == nil:
+			action = consts.AuditActionDelete
+		default:
+			action = consts.AuditActionUpdate
+		}
+
+		diff := auditDiff(beforeMap[id], afterMap[id])
+		if len(diff) == 0 {
+			continue
+		}
+
+		diffBuf, err := json.Api.Marshal(diff)
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "audit [%s] marshal diff error: %v", tbl, err)
+			continue
+		}
+
+		workspaceID := int(actor.WorkspaceId)
+		if workspaceID == 0 { // 定时任务等没有请求头的写操作，按记录所属的空间记录
+			row := afterMap[id]
+			if row == nil {
+				row = beforeMap[id]
+			}
+
+			workspaceID = auditWorkspaceID(ctx, tbl, row)
+		}
+
+		logs = append(logs, &TblAuditLog{
+			WorkspaceID: workspaceID,
+			UserID:      actor.Userid,
+			IP:          actor.Ip,
+			RequestID:   actor.RequestId,
+			Api:         msg.CallRPCName(),
+			EntityType:  strings.TrimPrefix(tbl, "tbl_"),
+			EntityID:    id,
+			Action:      action,
+			Diff:        string(diffBuf),
+			CreatedAt:   time.Now(),
+		})
+	}
+
+	if len(logs) == 0 {
+		return
+	}
+
+	_, err := GetTableORM("tbl_audit_log").Insert(logs).Exec(ctx)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "audit [%s] insert audit log error: %v", tbl, err)
+	}
+}
+
+// auditDiff 对比记录前后有变化的字段
+func auditDiff(before, after map[string]interface{}) map[string]*pb.AuditChange {
+	diff := map[string]*pb.AuditChange{}
+
+	for field, v := range after {
+		if auditIgnoreFields[field] {
+			continue
+		}
+
+		a := auditValue(v)
+
+		var b interface{}
+		if before != nil {
+			b = auditValue(before[field])
+			if fmt.Sprint(a) == fmt.Sprint(b) {
+				continue
+			}
+		}
+
+		diff[field] = &pb.AuditChange{Before: b, After: a}
+	}
+
+	if after == nil {
+		for field, v := range before {
+			if !auditIgnoreFields[field] {
+				diff[field] = &pb.AuditChange{Before: auditValue(v)}
+			}
+		}
+	}
+
+	for field, change := range diff {
+		if isAuditMaskField(field) {
+			if change.Before != nil {
+				change.Before = "******"
+			}
+			if change.After != nil {
+				change.After = "******"
+			}
+		}
+	}
+
+	return diff
+}
+
+// auditEntityID 对象id，多列时以冒号连接
+// auditWorkspaceID 记录所属的空间，依次按记录的空间、产品、仓库、表、应用、插件查找，找不到时返回 0
+func auditWorkspaceID(ctx context.Context, tbl string, row map[string]interface{}) int {
+	name := "tbl_product"
+
+	var where horm.Where
+
+	switch {
+	case row["workspace_id"] != nil:
+		workspaceID, _ := types.InterfaceToInt(auditValue(row["workspace_id"]))
+		return workspaceID
+	case tbl == "tbl_workspace":
+		workspaceID, _ := types.InterfaceToInt(auditValue(row["id"]))
+		return workspaceID
+	case row["product_id"] != nil:
+		where = horm.Where{"id": auditValue(row["product_id"])}
+	case row["db"] != nil:
+		where = horm.Where{
+			"~`id` = (SELECT `product_id` FROM `tbl_db` WHERE `id` = ?)": auditValue(row["db"]),
+		}
+	case row["table_id"] != nil:
+		where = horm.Where{
+			"~`id` = (SELECT d.`product_id` FROM `tbl_table` t JOIN `tbl_db` d ON t.`db` = d.`id` " +
+				"WHERE t.`id` = ?)": auditValue(row["table_id"]),
+		}
+	case row["appid"] != nil:
+		name = "tbl_workspace_resource"
+		where = horm.Where{"res_type": consts.WorkspaceResourceApp, "res_id": auditValue(row["appid"])}
+	case tbl == "tbl_plugin" || row["plugin_id"] != nil:
+		pluginID := row["plugin_id"]
+		if tbl == "tbl_plugin" {
+			pluginID = row["id"]
+		}
+
+		name = "tbl_workspace_resource"
+		where = horm.Where{"res_type": consts.WorkspaceResourcePlugin, "res_id": auditValue(pluginID)}
+	default:
+		return 0
+	}
+
+	ret := map[string]interface{}{}
+
+	_, err := GetTableORM(name).Find(where).Column("workspace_id").Exec(ctx, &ret)
+	if err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "audit [%s] find workspace of row error: %v", tbl, err)
+		return 0
+	}
+
+	workspaceID, _ := types.InterfaceToInt(auditValue(ret["workspace_id"]))
+	return workspaceID
+}
+
+func auditEntityID(row map[string]interface{}, key string) string {
+	cols := strings.Split(key, ",")
+
+	values := make([]string, len(cols))
+	for k, col := range cols {
+		values[k] = fmt.Sprint(auditValue(row[col]))
+	}
+
+	return strings.Join(values, ":")
+}
+
+func auditValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case time.Time:
+		return val.Format("2006-01-02 15:04:05")
+	default:
+		return val
+	}
+}
+
+func isAuditMaskField(field string) bool {
+	for _, word := range auditMaskWords {
+		if strings.Contains(field, word) {
+			return true
+		}
+	}
+	return false
+}
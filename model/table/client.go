@@ -0,0 +1,256 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package table
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/horm-database/common/consts"
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/common/proto/filter"
+	"github.com/horm-database/common/types"
+	"github.com/horm-database/common/util"
+	"github.com/horm-database/go-horm/horm"
+	cc "github.com/horm-database/manage/consts"
+	"github.com/horm-database/orm"
+	"github.com/horm-database/orm/database"
+	"github.com/horm-database/orm/obj"
+)
+
+// 管理库的查询都由 localClient 直接访问数据库，执行逻辑与 orm.ORM 一致，
+// ctx 由 Transaction 生成时查询在同一个 sql 事务中执行。
+
+var (
+	localOnce sync.Once
+	local     *localClient
+)
+
+// localClient 本地查询执行客户端，实现 horm.Client
+type localClient struct {
+	db      *obj.TblDB
+	initErr error
+}
+
+// transaction 事务信息，存放在 Transaction 生成的 ctx 中
+type transaction struct {
+	info     *obj.TransInfo
+	onCommit []func()
+}
+
+type transactionKey struct{}
+
+// Transaction 开启事务执行 fn，fn 中使用参数 ctx 执行的查询都在同一个事务中，fn 返回 error 时回滚，否则提交。
+// ctx 已在事务中时 fn 加入外层事务，由外层事务提交或回滚。
+func Transaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if getTransaction(ctx) != nil {
+		return fn(ctx)
+	}
+
+	trans := &transaction{info: &obj.TransInfo{}}
+
+	defer func() {
+		if e := recover(); e != nil {
+			err = errs.Newf(errs.RetPanic, "transaction panic: %v", e)
+			log.Errorf(ctx, errs.RetPanic, "transaction panic: %v", e)
+		}
+
+		for _, c := range trans.info.TxClients {
+			if e := c.FinishTx(err); e != nil && err == nil {
+				err = e
+			}
+		}
+
+		if err == nil {
+			for _, f := range trans.onCommit {
+				f()
+			}
+		}
+	}()
+
+	return fn(context.WithValue(ctx, transactionKey{}, trans))
+}
+
+// OnCommit ctx 在事务中时，f 在事务提交成功后执行，回滚时不执行；否则立即执行
+func OnCommit(ctx context.Context, f func()) {
+	trans := getTransaction(ctx)
+	if trans == nil {
+		f()
+		return
+	}
+
+	trans.onCommit = append(trans.onCommit, f)
+}
+
+// Exec 单执行单元 result 接收结果的指针
+func (c *localClient) Exec(ctx context.Context, q *horm.Query, retReceiver ...interface{}) (isNil bool, err error) {
+	if c.initErr != nil {
+		return false, c.initErr
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			err = errs.New(errs.RetPanic, fmt.Sprintf("%v", e))
+		}
+		q.Reset()
+	}()
+
+	if q.Unit.Size < 0 {
+		q.Unit.Size = 0
+	}
+
+	tree := newTree(q, c.db)
+	if trans := getTransaction(ctx); trans != nil {
+		tree.TransInfo = trans.info
+	}
+
+	tree.Result, tree.Detail, tree.IsNil, tree.Error = database.QueryResult(ctx,
+		newRequest(tree), tree, c.db.Addr, tree.TransInfo)
+
+	var ret interface{}
+	isNil, ret, err = orm.ParseResult(tree)
+	if err != nil {
+		return isNil, err
+	}
+
+	err = q.GetCoder().Decode(int(q.ResultType), ret, retReceiver)
+	if err != nil {
+		return false, errs.Newf(errs.RetClientDecodeFail,
+			"[request_id=%d] %v, result=[%s]", q.RequestID, err, types.InterfaceToString(ret))
+	}
+
+	return isNil, nil
+}
+
+// PExec 不支持并行查询
+func (c *localClient) PExec(ctx context.Context, q *horm.Query) error {
+	return errs.Newf(errs.ErrSystem, "local client not support parallel query")
+}
+
+// CompExec 不支持复合查询
+func (c *localClient) CompExec(ctx context.Context, q *horm.Query, retReceiver interface{}) error {
+	return errs.Newf(errs.ErrSystem, "local client not support composite query")
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+func getLocalClient() *localClient {
+	localOnce.Do(func() {
+		local = newLocalClient(cc.DBConfigName)
+	})
+
+	return local
+}
+
+// newLocalClient 根据 db 配置生成客户端，同 orm.NewORM
+func newLocalClient(dbName string) *localClient {
+	c := localClient{db: &obj.TblDB{}}
+
+	dbConf, err := horm.GetDBConfig(dbName)
+	if err != nil {
+		c.initErr = err
+		return &c
+	}
+
+	c.db.Name = dbConf.Name
+
+	dbType, ok := consts.DBTypeMap[dbConf.Type]
+	if !ok {
+		c.initErr = errs.Newf(errs.RetDBConfigTypeInvalid, "db config type invalid: %s", dbConf.Type)
+		return &c
+	}
+
+	if dbConf.Network == "" {
+		dbConf.Network = "TCP"
+	}
+
+	if dbConf.WarnTimeout == 0 {
+		dbConf.WarnTimeout = 200
+	}
+
+	c.db.Addr = &util.DBAddress{
+		Type:    dbType,
+		Version: dbConf.Version,
+		Network: dbConf.Network,
+		Address: dbConf.Address,
+
+		WriteTimeout: dbConf.WriteTimeout,
+		ReadTimeout:  dbConf.ReadTimeout,
+		WarnTimeout:  dbConf.WarnTimeout,
+		OmitError:    dbConf.OmitError,
+		Debug:        dbConf.Debug,
+	}
+
+	err = util.ParseConnFromAddress(c.db.Addr)
+	if err != nil {
+		c.initErr = errs.Newf(errs.RetDBAddressParseError, "db address [%s] parse error: %v", dbConf.Address, err)
+	}
+
+	return &c
+}
+
+func getTransaction(ctx context.Context) *transaction {
+	trans, _ := ctx.Value(transactionKey{}).(*transaction)
+	return trans
+}
+
+// newTree 查询单元的执行节点，同 orm 的 initTree
+func newTree(q *horm.Query, db *obj.TblDB) *obj.Tree {
+	unit := q.Unit
+
+	property := obj.Property{}
+	property.Op = strings.ToLower(unit.Op)
+	property.Name, property.Alias = util.Alias(unit.Name)
+	property.Path = unit.Name
+	property.DB = db
+
+	if unit.Name != "" {
+		property.Tables = []string{unit.Name}
+	} else if len(unit.Shard) > 0 {
+		property.Tables = unit.Shard
+	}
+
+	return &obj.Tree{Name: unit.Name, Unit: unit, Property: &property}
+}
+
+// newRequest 执行节点的查询请求，同 orm 的 query
+func newRequest(node *obj.Tree) *filter.Request {
+	unit := node.GetUnit()
+
+	return &filter.Request{
+		Op:     node.GetOp(),
+		Tables: node.Tables(),
+		Where:  unit.Where,
+		Column: unit.Column,
+		Group:  unit.Group,
+		Having: unit.Having,
+		Order:  unit.Order,
+		Page:   unit.Page,
+		Size:   unit.Size,
+		From:   unit.From,
+		Data:   unit.Data,
+		Datas:  unit.Datas,
+		Type:   unit.Type,
+		Scroll: unit.Scroll,
+		Prefix: unit.Prefix,
+		Key:    unit.Key,
+		Args:   unit.Args,
+		Bytes:  unit.Bytes,
+		Params: unit.Params,
+		Query:  unit.Query,
+	}
+}
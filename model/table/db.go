@@ -16,6 +16,7 @@ package table
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/horm-database/common/proto"
 	"github.com/horm-database/go-horm/horm"
@@ -29,18 +30,27 @@ func AddDB(ctx context.Context, db *obj.TblDB) (int, error) {
 		return 0, err
 	}
 
+	auditCreate(ctx, "tbl_db", "id", horm.Where{"id": modRet.ID.Int()})
+
 	return modRet.ID.Int(), nil
 }
 
 func UpdateDBByID(ctx context.Context, id int, update horm.Map) error {
-	_, err := GetTableORM("tbl_db").Eq("id", id).Update(update).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_db", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_db").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
 }
 
 func GetDBByID(ctx context.Context, id int) (bool, *obj.TblDB, error) {
 	db := obj.TblDB{}
 
-	isNil, err := GetTableORM("tbl_db").FindBy("id", id).Exec(ctx, &db)
+	where, err := scopeDBs(ctx, horm.Where{"id": id})
+	if err != nil {
+		return true, &db, err
+	}
+
+	isNil, err := GetTableORM("tbl_db").Find(where).Exec(ctx, &db)
 
 	return isNil, &db, err
 }
@@ -48,7 +58,12 @@ func GetDBByID(ctx context.Context, id int) (bool, *obj.TblDB, error) {
 func GetDBByIds(ctx context.Context, ids []int) ([]*obj.TblDB, error) {
 	dbs := []*obj.TblDB{}
 
-	_, err := GetTableORM("tbl_db").FindAllBy("id", ids).Exec(ctx, &dbs)
+	where, err := scopeDBs(ctx, horm.Where{"id": ids})
+	if err != nil {
+		return dbs, err
+	}
+
+	_, err = GetTableORM("tbl_db").FindAll(where).Exec(ctx, &dbs)
 
 	return dbs, err
 }
@@ -56,7 +71,63 @@ func GetDBByIds(ctx context.Context, ids []int) ([]*obj.TblDB, error) {
 func GetProductDBs(ctx context.Context, productID int) ([]*obj.TblDB, error) {
 	dbs := []*obj.TblDB{}
 
-	_, err := GetTableORM("tbl_db").FindAllBy("product_id", productID).Order("-id").Exec(ctx, &dbs)
+	where, err := scopeDBs(ctx, horm.Where{"product_id": productID})
+	if err != nil {
+		return dbs, err
+	}
+
+	_, err = GetTableORM("tbl_db").FindAll(where).Order("-id").Exec(ctx, &dbs)
+
+	return dbs, err
+}
+
+func GetProductsDBs(ctx context.Context, productIDs []int) ([]*obj.TblDB, error) {
+	dbs := []*obj.TblDB{}
+
+	if len(productIDs) == 0 {
+		return dbs, nil
+	}
+
+	where, err := scopeDBs(ctx, horm.Where{"product_id": productIDs})
+	if err != nil {
+		return dbs, err
+	}
+
+	_, err = GetTableORM("tbl_db").FindAll(where).Exec(ctx, &dbs)
+
+	return dbs, err
+}
+
+// GetDBsByManager 管理员包含该用户的仓库，模糊匹配，调用方需要再精确判断
+func GetDBsByManager(ctx context.Context, userid uint64) ([]*obj.TblDB, error) {
+	dbs := []*obj.TblDB{}
+
+	where, err := scopeDBs(ctx, horm.Where{
+		"manager ~": "%" + fmt.Sprint(userid) + "%",
+	})
+	if err != nil {
+		return dbs, err
+	}
+
+	_, err = GetTableORM("tbl_db").FindAll(where).Exec(ctx, &dbs)
+
+	return dbs, err
+}
+
+// GetDBsAfterID 按 id 顺序遍历
+func GetDBsAfterID(ctx context.Context, lastID, limit int) ([]*obj.TblDB, error) {
+	dbs := []*obj.TblDB{}
+
+	where, err := scopeDBs(ctx, horm.Where{"id >": lastID})
+	if err != nil {
+		return dbs, err
+	}
+
+	_, err = GetTableORM("tbl_db").
+		FindAll(where).
+		Order("id").
+		Limit(limit).
+		Exec(ctx, &dbs)
 
 	return dbs, err
 }
@@ -0,0 +1,102 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+	"time"
+
+	"github.com/horm-database/common/proto"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/consts"
+)
+
+func InsertNotification(ctx context.Context, notification *TblNotification) (int, error) {
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_notification").Insert(notification).Exec(ctx, &modRet)
+	if err != nil {
+		return 0, err
+	}
+
+	return modRet.ID.Int(), nil
+}
+
+func UpdateNotificationByID(ctx context.Context, id int, update horm.Map) error {
+	_, err := GetTableORM("tbl_notification").Eq("id", id).Update(update).Exec(ctx)
+	return err
+}
+
+// GetNotifications 用户的通知列表，status、event 为零值时不过滤
+func GetNotifications(ctx context.Context, userid uint64, status int8,
+	event string, page, size int) (*proto.Detail, []*TblNotification, error) {
+	pageRet := proto.Detail{}
+
+	notifications := []*TblNotification{}
+
+	where := horm.Where{"userid": userid}
+	if status != 0 {
+		where["status"] = status
+	}
+
+	if event != "" {
+		where["event"] = event
+	}
+
+	_, err := GetTableORM("tbl_notification").
+		FindAll(where).
+		Order("-id").
+		Page(page, size).
+		Exec(ctx, &pageRet, &notifications)
+
+	return &pageRet, notifications, err
+}
+
+// MarkNotificationsRead 将用户的通知标为已读，ids 为空时全部标为已读
+func MarkNotificationsRead(ctx context.Context, userid uint64, ids []int) error {
+	where := horm.Where{
+		"userid": userid,
+		"status": consts.NotificationStatusUnread,
+	}
+
+	if len(ids) > 0 {
+		where["id"] = ids
+	}
+
+	update := horm.Map{
+		"status":    consts.NotificationStatusRead,
+		"read_time": time.Now().Unix(),
+	}
+
+	_, err := GetTableORM("tbl_notification").Update(update, where).Exec(ctx)
+	return err
+}
+
+// CountUnreadNotifications 用户的未读通知数
+func CountUnreadNotifications(ctx context.Context, userid uint64) (uint64, error) {
+	pageRet := proto.Detail{}
+	notifications := []*TblNotification{}
+
+	where := horm.Where{
+		"userid": userid,
+		"status": consts.NotificationStatusUnread,
+	}
+
+	_, err := GetTableORM("tbl_notification").
+		FindAll(where).
+		Page(1, 1).
+		Exec(ctx, &pageRet, &notifications)
+
+	return pageRet.Total, err
+}
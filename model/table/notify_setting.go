@@ -0,0 +1,37 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+
+	"github.com/horm-database/go-horm/horm"
+)
+
+func GetNotifySettingByUser(ctx context.Context, userid uint64) (bool, *TblNotifySetting, error) {
+	setting := TblNotifySetting{}
+	isNil, err := GetTableORM("tbl_notify_setting").FindBy("userid", userid).Exec(ctx, &setting)
+	return isNil, &setting, err
+}
+
+func InsertNotifySetting(ctx context.Context, setting *TblNotifySetting) error {
+	_, err := GetTableORM("tbl_notify_setting").Insert(setting).Exec(ctx)
+	return err
+}
+
+func UpdateNotifySettingByID(ctx context.Context, id int, update horm.Map) error {
+	_, err := GetTableORM("tbl_notify_setting").Eq("id", id).Update(update).Exec(ctx)
+	return err
+}
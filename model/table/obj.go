@@ -17,15 +17,12 @@ package table
 import (
 	"time"
 
-	"github.com/horm-database/manage/consts"
-	"github.com/horm-database/orm"
+	"github.com/horm-database/go-horm/horm"
 )
 
-// GetTableORM 获取经校验后的 ORM
-func GetTableORM(table string) *orm.ORM {
-	cli := orm.NewORM(consts.DBConfigName)
-	cli.Name(table)
-	return cli
+// GetTableORM 获取表的查询语句，由本地客户端执行，见 Transaction
+func GetTableORM(table string) *horm.Query {
+	return horm.NewQuery(table).WithClient(getLocalClient())
 }
 
 type TblUser struct {
@@ -48,14 +45,29 @@ type TblUser struct {
 	UpdatedAt     time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"`                // 记录最后修改时间
 }
 
+type TblUserSession struct {
+	Id             int       `orm:"id,int,omitempty" json:"id,omitempty"`                             // 会话id
+	UserID         uint64    `orm:"userid,uint64,omitempty" json:"userid,omitempty"`                  // 用户id
+	Token          string    `orm:"token,string,omitempty" json:"token,omitempty"`                    // 会话 token，用于请求签名
+	IP             string    `orm:"ip,string,omitempty" json:"ip,omitempty"`                          // 最近访问 ip
+	UserAgent      string    `orm:"user_agent,string,omitempty" json:"user_agent,omitempty"`          // 登录设备 User-Agent
+	Status         int8      `orm:"status,int8,omitempty" json:"status,omitempty"`                    // 1-正常 2-已退出
+	LoginTime      int64     `orm:"login_time,int,omitempty" json:"login_time,omitempty"`             // 登录时间
+	LastActiveTime int64     `orm:"last_active_time,int,omitempty" json:"last_active_time,omitempty"` // 最近访问时间，超过空闲时间未访问则会话失效
+	ExpireTime     int64     `orm:"expire_time,int,omitempty" json:"expire_time,omitempty"`           // 绝对过期时间
+	CreatedAt      time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`                  // 记录创建时间
+	UpdatedAt      time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"`                  // 记录最后修改时间
+}
+
 type TblWorkspaceMember struct {
 	Id          int       `orm:"id,int,omitempty" json:"id,omitempty"`                     // member id
 	WorkspaceID int       `orm:"workspace_id,int,omitempty" json:"workspace_id,omitempty"` // workspace id
 	UserID      uint64    `orm:"userid,uint64,omitempty" json:"userid,omitempty"`          // 用户id
-	Status      int8      `orm:"status,int8,omitempty" json:"status,omitempty"`            // 1-待审批 2-续期审批 3-暂未申请 4-已加入 5-审批拒绝  6-已退出
+	Status      int8      `orm:"status,int8,omitempty" json:"status,omitempty"`            // 1-待审批 2-续期审批 3-暂未申请 4-已加入 5-审批拒绝  6-已退出 9-已过期
 	JoinTime    int64     `orm:"join_time,int,omitempty" json:"join_time,omitempty"`       // 加入时间
 	ExpireType  int8      `orm:"expire_type,int8,omitempty" json:"expire_type,omitempty"`  // 0: 永久 1: 一个月 2: 三个月 3: 半年 4: 一年
 	ExpireTime  int       `orm:"expire_time,int,omitempty" json:"expire_time,omitempty"`   // 过期时间
+	RemindTime  int64     `orm:"remind_time,int,omitempty" json:"remind_time,omitempty"`   // 过期提醒时间，0-未提醒
 	OutTime     int       `orm:"out_time,int,omitempty" json:"out_time,omitempty"`         // 退出时间
 	CreatedAt   time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`          // 记录创建时间
 	UpdatedAt   time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"`          // 记录最后修改时间
@@ -70,14 +82,15 @@ type TblCollectTable struct {
 }
 
 type TblProduct struct {
-	Id        int       `orm:"id,int,omitempty" json:"id,omitempty"`              // id
-	Name      string    `orm:"name,string,omitempty" json:"name,omitempty"`       // 产品名称
-	Intro     string    `orm:"intro,string,omitempty" json:"intro,omitempty"`     // 简介
-	Creator   uint64    `orm:"creator,uint64,omitempty" json:"creator,omitempty"` // Creator
-	Manager   string    `orm:"manager,string,omitempty" json:"manager,omitempty"` // 管理员，多个逗号分隔
-	Status    int8      `orm:"status,int8,omitempty" json:"status,omitempty"`     // 1-正常 2-下线
-	CreatedAt time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`   // 记录创建时间
-	UpdatedAt time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"`   // 记录最后修改时间
+	Id          int       `orm:"id,int,omitempty" json:"id,omitempty"`                     // id
+	WorkspaceID int       `orm:"workspace_id,int,omitempty" json:"workspace_id,omitempty"` // 所属空间
+	Name        string    `orm:"name,string,omitempty" json:"name,omitempty"`              // 产品名称
+	Intro       string    `orm:"intro,string,omitempty" json:"intro,omitempty"`            // 简介
+	Creator     uint64    `orm:"creator,uint64,omitempty" json:"creator,omitempty"`        // Creator
+	Manager     string    `orm:"manager,string,omitempty" json:"manager,omitempty"`        // 管理员，多个逗号分隔
+	Status      int8      `orm:"status,int8,omitempty" json:"status,omitempty"`            // 1-正常 2-下线
+	CreatedAt   time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`          // 记录创建时间
+	UpdatedAt   time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"`          // 记录最后修改时间
 }
 
 type TblProductMember struct {
@@ -85,24 +98,133 @@ type TblProductMember struct {
 	ProductID  int       `orm:"product_id,int,omitempty" json:"product_id,omitempty"`    // product id
 	UserID     uint64    `orm:"userid,uint64,omitempty" json:"userid,omitempty"`         // 用户id
 	Role       int8      `orm:"role,int8,omitempty" json:"role,omitempty"`               // 1-管理员 2-开发者 3-运营者
-	Status     int8      `orm:"status,int8,omitempty" json:"status,omitempty"`           // 1-待审批 2-续期审批 3-角色变更审批 4-已加入 5-审批拒绝  6-已退出
+	Status     int8      `orm:"status,int8,omitempty" json:"status,omitempty"`           // 1-待审批 2-续期审批 3-角色变更审批 4-已加入 5-审批拒绝  6-已退出 7-已过期
 	JoinTime   int64     `orm:"join_time,int,omitempty" json:"join_time,omitempty"`      // 加入时间
 	ExpireType int8      `orm:"expire_type,int8,omitempty" json:"expire_type,omitempty"` // 0: 永久 1: 一个月 2: 三个月 3: 半年 4: 一年
 	ExpireTime int       `orm:"expire_time,int,omitempty" json:"expire_time,omitempty"`  // 过期时间
+	RemindTime int64     `orm:"remind_time,int,omitempty" json:"remind_time,omitempty"`  // 过期提醒时间，0-未提醒
 	OutTime    int       `orm:"out_time,int,omitempty" json:"out_time,omitempty"`        // 退出时间
 	ChangeRole int8      `orm:"change_role,int8,omitempty" json:"change_role,omitempty"` // 变更为目标角色 0-无 2-开发者 3-运营者
 	CreatedAt  time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`         // 记录创建时间
 	UpdatedAt  time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"`         // 记录最后修改时间
 }
 
+type TblAuditLog struct {
+	Id          int       `orm:"id,int,omitempty" json:"id,omitempty"`                      // id
+	WorkspaceID int       `orm:"workspace_id,int" json:"workspace_id"`                      // 操作所在空间，0 为系统任务
+	UserID      uint64    `orm:"userid,uint64" json:"userid"`                               // 操作人，0 为系统任务
+	IP          string    `orm:"ip,string,omitempty" json:"ip,omitempty"`                   // 操作人 ip
+	RequestID   uint64    `orm:"request_id,uint64" json:"request_id"`                       // 请求id
+	Api         string    `orm:"api,string,omitempty" json:"api,omitempty"`                 // 接口名
+	EntityType  string    `orm:"entity_type,string,omitempty" json:"entity_type,omitempty"` // 对象类型，即表名去掉 tbl_ 前缀，如 db、access_table
+	EntityID    string    `orm:"entity_id,string,omitempty" json:"entity_id,omitempty"`     // 对象id
+	Action      int8      `orm:"action,int8,omitempty" json:"action,omitempty"`             // 1-新增 2-修改 3-删除
+	Diff        string    `orm:"diff,string,omitempty" json:"diff,omitempty"`               // 变更内容 json {"字段":{"before":修改前,"after":修改后}}
+	CreatedAt   time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`           // 记录创建时间
+}
+
+type TblNotification struct {
+	Id        int       `orm:"id,int,omitempty" json:"id,omitempty"`            // id
+	UserID    uint64    `orm:"userid,uint64" json:"userid"`                     // 接收人
+	Event     string    `orm:"event,string" json:"event"`                       // 事件 apply-新的申请 withdraw-申请已撤销 result-审批结果 expiring-权限即将过期 expired-权限已过期
+	Kind      string    `orm:"kind,string" json:"kind"`                         // 申请类型，如 workspace_join、access_db
+	Title     string    `orm:"title,string" json:"title"`                       // 标题，按接收人语言生成
+	Detail    string    `orm:"detail,string" json:"detail"`                     // 通知内容 json，包括申请人、审批人、申请记录id 等
+	Status    int8      `orm:"status,int8,omitempty" json:"status,omitempty"`   // 1-未读 2-已读
+	ReadTime  int64     `orm:"read_time,int" json:"read_time"`                  // 阅读时间
+	CreatedAt time.Time `orm:"created_at,datetime,omitempty" json:"created_at"` // 记录创建时间
+	UpdatedAt time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"` // 记录最后修改时间
+}
+
+type TblNotifySetting struct {
+	Id         int       `orm:"id,int,omitempty" json:"id,omitempty"`            // id
+	UserID     uint64    `orm:"userid,uint64" json:"userid"`                     // 用户id
+	Email      int8      `orm:"email,int8,omitempty" json:"email,omitempty"`     // 邮件通知 1-开启 2-关闭
+	WebhookURL string    `orm:"webhook_url,string" json:"webhook_url"`           // 通知推送的 webhook 地址，为空不推送
+	Locale     string    `orm:"locale,string" json:"locale"`                     // 通知语言，为空使用默认语言
+	MuteEvents string    `orm:"mute_events,string" json:"mute_events"`           // 不接收通知的事件，多个逗号分隔
+	CreatedAt  time.Time `orm:"created_at,datetime,omitempty" json:"created_at"` // 记录创建时间
+	UpdatedAt  time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"` // 记录最后修改时间
+}
+
+type TblAccessExpire struct {
+	Id              int       `orm:"id,int,omitempty" json:"id,omitempty"`            // id
+	AccessType      int8      `orm:"access_type,int8" json:"access_type"`             // 1-应用接入仓库 2-应用接入表数据
+	AccessID        int       `orm:"access_id,int" json:"access_id"`                  // tbl_access_db/tbl_access_table 的权限记录id
+	ExpireType      int8      `orm:"expire_type,int8" json:"expire_type"`             // 权限有效期 0-永久 1-一个月 2-三个月 3-半年 4-一年
+	ExpireTime      int64     `orm:"expire_time,int" json:"expire_time"`              // 过期时间，0 为永久或未审批通过
+	RemindTime      int64     `orm:"remind_time,int" json:"remind_time"`              // 到期提醒时间，0 为未提醒
+	OfflineTime     int64     `orm:"offline_time,int" json:"offline_time"`            // 到期下线时间，0 为未下线
+	RenewStatus     int8      `orm:"renew_status,int8" json:"renew_status"`           // 续期状态 0-无 1-续期审批中
+	RenewExpireType int8      `orm:"renew_expire_type,int8" json:"renew_expire_type"` // 续期申请的有效期
+	RenewUser       uint64    `orm:"renew_user,uint64" json:"renew_user"`             // 续期申请人
+	RenewReason     string    `orm:"renew_reason,string" json:"renew_reason"`         // 续期理由
+	CreatedAt       time.Time `orm:"created_at,datetime,omitempty" json:"created_at"` // 记录创建时间
+	UpdatedAt       time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"` // 记录最后修改时间
+}
+
+type TblOutbox struct {
+	Id          int       `orm:"id,int,omitempty" json:"id,omitempty"`                    // id
+	Topic       string    `orm:"topic,string,omitempty" json:"topic,omitempty"`           // 任务主题，决定由哪个 handler 处理
+	Payload     string    `orm:"payload,string,omitempty" json:"payload,omitempty"`       // 任务参数 json
+	Status      int8      `orm:"status,int8,omitempty" json:"status,omitempty"`           // 1-待处理 2-处理中 3-失败（超过最大重试次数）
+	RetryTimes  int       `orm:"retry_times,int" json:"retry_times"`                      // 已失败次数
+	NextRunTime int64     `orm:"next_run_time,int" json:"next_run_time"`                  // 下次执行时间（毫秒），处理中时为处理超时时间
+	LastError   string    `orm:"last_error,string,omitempty" json:"last_error,omitempty"` // 最近一次失败原因
+	CreatedAt   time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`         // 记录创建时间
+	UpdatedAt   time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"`         // 记录最后修改时间
+}
+
 type TblSearchKeyword struct {
-	Id        int       `orm:"id,int,omitempty" json:"id,omitempty"`                // id
-	Type      int8      `orm:"type,int8,omitempty" json:"type,omitempty"`           // 1-product 2-db 3-table
-	Sid       int       `orm:"sid,int,omitempty" json:"sid,omitempty"`              // 检索id
-	SName     string    `orm:"sname,string,omitempty" json:"sname,omitempty"`       // 检索名
-	Field     string    `orm:"field,string,omitempty" json:"field,omitempty"`       // 字段
-	SKey      string    `orm:"skey,string,omitempty" json:"skey,omitempty"`         // 检索key
-	SContent  string    `orm:"scontent,string,omitempty" json:"scontent,omitempty"` // 检索内容
-	CreatedAt time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`     // 记录创建时间
-	UpdatedAt time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"`     // 记录最后修改时间
+	Id          int       `orm:"id,int,omitempty" json:"id,omitempty"`                     // id
+	WorkspaceID int       `orm:"workspace_id,int,omitempty" json:"workspace_id,omitempty"` // 所属空间
+	Type        int8      `orm:"type,int8,omitempty" json:"type,omitempty"`                // 1-product 2-db 3-table
+	Sid         int       `orm:"sid,int,omitempty" json:"sid,omitempty"`                   // 检索id
+	SName       string    `orm:"sname,string,omitempty" json:"sname,omitempty"`            // 检索名
+	Field       string    `orm:"field,string,omitempty" json:"field,omitempty"`            // 字段
+	SKey        string    `orm:"skey,string,omitempty" json:"skey,omitempty"`              // 检索key
+	SContent    string    `orm:"scontent,string,omitempty" json:"scontent,omitempty"`      // 检索内容
+	CreatedAt   time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`          // 记录创建时间
+	UpdatedAt   time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"`          // 记录最后修改时间
+}
+
+type TblWorkspaceResource struct {
+	Id          int       `orm:"id,int,omitempty" json:"id,omitempty"`                     // id
+	WorkspaceID int       `orm:"workspace_id,int,omitempty" json:"workspace_id,omitempty"` // 空间id
+	ResType     int8      `orm:"res_type,int8,omitempty" json:"res_type,omitempty"`        // 1-应用 2-插件
+	ResID       uint64    `orm:"res_id,uint64,omitempty" json:"res_id,omitempty"`          // 应用 appid 或插件 id
+	CreatedAt   time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`          // 记录创建时间
+}
+
+type TblWorkspaceSetting struct {
+	Id                int       `orm:"id,int,omitempty" json:"id,omitempty"`                // id
+	WorkspaceID       int       `orm:"workspace_id,int" json:"workspace_id"`                // 空间id
+	DefaultExpireType int8      `orm:"default_expire_type,int8" json:"default_expire_type"` // 成员默认有效期 0: 永久 1: 一个月 2: 三个月 3: 半年 4: 一年
+	AutoApprove       int8      `orm:"auto_approve,int8" json:"auto_approve"`               // 加入申请自动通过 1-开启 2-关闭
+	EmailDomains      string    `orm:"email_domains,string" json:"email_domains"`           // 允许注册/加入的邮箱域名，多个逗号分隔，为空不限制
+	DefaultPlugins    string    `orm:"default_plugins,string" json:"default_plugins"`       // 新建表默认插件链，是一个 json
+	CreatedAt         time.Time `orm:"created_at,datetime,omitempty" json:"created_at"`     // 记录创建时间
+	UpdatedAt         time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"`     // 记录最后修改时间
+}
+
+type TblTablePluginChain struct {
+	Id        int       `orm:"id,int,omitempty" json:"id,omitempty"`            // id
+	TableID   int       `orm:"table_id,int" json:"table_id"`                    // 表id
+	Version   int       `orm:"version,int" json:"version"`                      // 插件链版本，插件链每次修改加 1
+	CreatedAt time.Time `orm:"created_at,datetime,omitempty" json:"created_at"` // 记录创建时间
+	UpdatedAt time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"` // 记录最后修改时间
+}
+
+type TblPluginVersion struct {
+	Id              int       `orm:"id,int,omitempty" json:"id,omitempty"`            // id
+	PluginID        int       `orm:"plugin_id,int" json:"plugin_id"`                  // 插件id
+	Version         int       `orm:"version,int" json:"version"`                      // 插件版本
+	Changelog       string    `orm:"changelog,string" json:"changelog"`               // 版本变更说明
+	Status          int8      `orm:"status,int8" json:"status"`                       // 状态 1-正常 2-已废弃
+	DeprecateReason string    `orm:"deprecate_reason,string" json:"deprecate_reason"` // 废弃原因
+	Publisher       uint64    `orm:"publisher,uint64" json:"publisher"`               // 发布人
+	DeprecatedBy    uint64    `orm:"deprecated_by,uint64" json:"deprecated_by"`       // 废弃人
+	DeprecatedAt    int64     `orm:"deprecated_at,int64" json:"deprecated_at"`        // 废弃时间，unix 时间戳
+	CreatedAt       time.Time `orm:"created_at,datetime,omitempty" json:"created_at"` // 发布时间
+	UpdatedAt       time.Time `orm:"updated_at,datetime,omitempty" json:"updated_at"` // 记录最后修改时间
 }
@@ -0,0 +1,114 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+
+	"github.com/horm-database/common/proto"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/consts"
+)
+
+func InsertOutbox(ctx context.Context, job *TblOutbox) (int, error) {
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_outbox").Insert(job).Exec(ctx, &modRet)
+	if err != nil {
+		return 0, err
+	}
+
+	return modRet.ID.Int(), nil
+}
+
+func DelOutboxByID(ctx context.Context, id int) error {
+	_, err := GetTableORM("tbl_outbox").DeleteBy("id", id).Exec(ctx)
+	return err
+}
+
+func UpdateOutboxByID(ctx context.Context, id int, update horm.Map) error {
+	_, err := GetTableORM("tbl_outbox").Update(update).Eq("id", id).Exec(ctx)
+	return err
+}
+
+// GetDueOutbox 获取到期的任务，包括处理超时的任务（处理中的实例可能已退出）
+func GetDueOutbox(ctx context.Context, now int64, limit int) ([]*TblOutbox, error) {
+	jobs := []*TblOutbox{}
+
+	where := horm.Where{
+		"status":           []int8{consts.OutboxStatusPending, consts.OutboxStatusProcessing},
+		"next_run_time <=": now,
+	}
+
+	_, err := GetTableORM("tbl_outbox").FindAll(where).Order("next_run_time").Limit(limit).Exec(ctx, &jobs)
+
+	return jobs, err
+}
+
+// ClaimOutbox 抢占任务，任务在读取之后未被其他实例修改才能抢占成功
+func ClaimOutbox(ctx context.Context, job *TblOutbox, leaseUntil int64) (bool, error) {
+	where := horm.Where{
+		"id":            job.Id,
+		"status":        job.Status,
+		"next_run_time": job.NextRunTime,
+	}
+
+	update := horm.Map{
+		"status":        consts.OutboxStatusProcessing,
+		"next_run_time": leaseUntil,
+	}
+
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_outbox").Update(update, where).Exec(ctx, &modRet)
+	if err != nil {
+		return false, err
+	}
+
+	return modRet.RowAffected > 0, nil
+}
+
+// RequeueFailedOutbox 将失败的任务重置为待处理，topic 为空时重置全部主题，返回重置的任务数
+func RequeueFailedOutbox(ctx context.Context, topic string, now int64) (int64, error) {
+	where := horm.Where{"status": consts.OutboxStatusFailed}
+	if topic != "" {
+		where["topic"] = topic
+	}
+
+	update := horm.Map{
+		"status":        consts.OutboxStatusPending,
+		"retry_times":   0,
+		"next_run_time": now,
+	}
+
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_outbox").Update(update, where).Exec(ctx, &modRet)
+	if err != nil {
+		return 0, err
+	}
+
+	return modRet.RowAffected, nil
+}
+
+// CountOutboxByStatus 各状态的任务数
+func CountOutboxByStatus(ctx context.Context, status int8) (uint64, error) {
+	pageRet := proto.Detail{}
+	jobs := []*TblOutbox{}
+
+	_, err := GetTableORM("tbl_outbox").
+		FindAll(horm.Where{"status": status}).
+		Page(1, 1).
+		Exec(ctx, &pageRet, &jobs)
+
+	return pageRet.Total, err
+}
@@ -29,12 +29,29 @@ func AddPlugin(ctx context.Context, plugin *table.TblPlugin) (int, error) {
 		return 0, err
 	}
 
+	auditCreate(ctx, "tbl_plugin", "id", horm.Where{"id": modRet.ID.Int()})
+
 	return modRet.ID.Int(), nil
 }
 
 func UpdatePluginByID(ctx context.Context, id int, update horm.Map) error {
-	_, err := GetTableORM("tbl_plugin").Eq("id", id).Update(update).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_plugin", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_plugin").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
+}
+
+// UpdatePluginSupportVersion 插件支持的版本仍为 oldVersion 时修改为 newVersion，已被并发修改时返回 false
+func UpdatePluginSupportVersion(ctx context.Context, id int, oldVersion, newVersion string) (bool, error) {
+	where := horm.Where{"id": id, "version": oldVersion}
+
+	modRet := proto.ModRet{}
+	err := auditWrite(ctx, "tbl_plugin", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_plugin").Update(horm.Map{"version": newVersion}, where).Exec(ctx, &modRet)
+		return err
+	})
+
+	return modRet.RowAffected > 0, err
 }
 
 func GetPluginList(ctx context.Context, page, size int) (*proto.Detail, []*table.TblPlugin, error) {
@@ -42,8 +59,13 @@ func GetPluginList(ctx context.Context, page, size int) (*proto.Detail, []*table
 
 	plugins := []*table.TblPlugin{}
 
-	_, err := GetTableORM("tbl_plugin").
-		FindAll().
+	where, err := scopePlugins(ctx, horm.Where{})
+	if err != nil {
+		return &pageRet, plugins, err
+	}
+
+	_, err = GetTableORM("tbl_plugin").
+		FindAll(where).
 		Order("-id").
 		Page(page, size).
 		Exec(ctx, &pageRet, &plugins)
@@ -54,8 +76,13 @@ func GetPluginList(ctx context.Context, page, size int) (*proto.Detail, []*table
 func GetPluginByIDs(ctx context.Context, pluginIDs []int) ([]*table.TblPlugin, error) {
 	plugins := []*table.TblPlugin{}
 
-	_, err := GetTableORM("tbl_plugin").
-		FindAllBy("id", pluginIDs).
+	where, err := scopePlugins(ctx, horm.Where{"id": pluginIDs})
+	if err != nil {
+		return plugins, err
+	}
+
+	_, err = GetTableORM("tbl_plugin").
+		FindAll(where).
 		Exec(ctx, &plugins)
 
 	return plugins, err
@@ -64,7 +91,12 @@ func GetPluginByIDs(ctx context.Context, pluginIDs []int) ([]*table.TblPlugin, e
 func GetPluginByID(ctx context.Context, pluginID int) (bool, *table.TblPlugin, error) {
 	plugin := table.TblPlugin{}
 
-	isNil, err := GetTableORM("tbl_plugin").FindBy("id", pluginID).Exec(ctx, &plugin)
+	where, err := scopePlugins(ctx, horm.Where{"id": pluginID})
+	if err != nil {
+		return true, &plugin, err
+	}
+
+	isNil, err := GetTableORM("tbl_plugin").Find(where).Exec(ctx, &plugin)
 
 	return isNil, &plugin, err
 }
@@ -22,9 +22,20 @@ import (
 	"github.com/horm-database/server/model/table"
 )
 
+// pluginConfigAuditKey replace 时 id 会变化，以唯一键作为审计对象id
+const pluginConfigAuditKey = "plugin_id,plugin_version,key"
+
 func ReplacePluginConfig(ctx context.Context, pluginConfig *table.TblPluginConfig) error {
-	_, err := GetTableORM("tbl_plugin_config").Replace(pluginConfig).Exec(ctx)
-	return err
+	where := horm.Where{
+		"plugin_id":      pluginConfig.PluginID,
+		"plugin_version": pluginConfig.PluginVersion,
+		"key":            pluginConfig.Key,
+	}
+
+	return auditWrite(ctx, "tbl_plugin_config", pluginConfigAuditKey, where, func() error {
+		_, err := GetTableORM("tbl_plugin_config").Replace(pluginConfig).Exec(ctx)
+		return err
+	})
 }
 
 func DelPluginConfigByKey(ctx context.Context, pluginID, version int, key string) error {
@@ -34,8 +45,22 @@ func DelPluginConfigByKey(ctx context.Context, pluginID, version int, key string
 		"key":            key,
 	}
 
-	_, err := GetTableORM("tbl_plugin_config").Delete(where).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_plugin_config", pluginConfigAuditKey, where, func() error {
+		_, err := GetTableORM("tbl_plugin_config").Delete(where).Exec(ctx)
+		return err
+	})
+}
+
+func DelPluginConfigs(ctx context.Context, pluginID, version int) error {
+	where := horm.Where{
+		"plugin_id":      pluginID,
+		"plugin_version": version,
+	}
+
+	return auditWrite(ctx, "tbl_plugin_config", pluginConfigAuditKey, where, func() error {
+		_, err := GetTableORM("tbl_plugin_config").Delete(where).Exec(ctx)
+		return err
+	})
 }
 
 func GetPluginConfigs(ctx context.Context, pluginID, version int) ([]*table.TblPluginConfig, error) {
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+
+	"github.com/horm-database/common/proto"
+	"github.com/horm-database/go-horm/horm"
+)
+
+func AddPluginVersion(ctx context.Context, pluginVersion *TblPluginVersion) (int, error) {
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_plugin_version").Insert(pluginVersion).Exec(ctx, &modRet)
+	if err != nil {
+		return 0, err
+	}
+
+	auditCreate(ctx, "tbl_plugin_version", "id", horm.Where{"id": modRet.ID.Int()})
+
+	return modRet.ID.Int(), nil
+}
+
+func UpdatePluginVersion(ctx context.Context, pluginID, version int, update horm.Map) error {
+	where := horm.Where{"plugin_id": pluginID, "version": version}
+
+	return auditWrite(ctx, "tbl_plugin_version", "id", where, func() error {
+		_, err := GetTableORM("tbl_plugin_version").Update(update, where).Exec(ctx)
+		return err
+	})
+}
+
+func DelPluginVersion(ctx context.Context, pluginID, version int) error {
+	where := horm.Where{"plugin_id": pluginID, "version": version}
+
+	return auditWrite(ctx, "tbl_plugin_version", "id", where, func() error {
+		_, err := GetTableORM("tbl_plugin_version").Delete(where).Exec(ctx)
+		return err
+	})
+}
+
+func GetPluginVersion(ctx context.Context, pluginID, version int) (bool, *TblPluginVersion, error) {
+	pluginVersion := TblPluginVersion{}
+
+	where := horm.Where{"plugin_id": pluginID, "version": version}
+
+	isNil, err := GetTableORM("tbl_plugin_version").Find(where).Exec(ctx, &pluginVersion)
+
+	return isNil, &pluginVersion, err
+}
+
+func GetPluginVersions(ctx context.Context, pluginID int) ([]*TblPluginVersion, error) {
+	pluginVersions := []*TblPluginVersion{}
+
+	_, err := GetTableORM("tbl_plugin_version").
+		FindAll(horm.Where{"plugin_id": pluginID}).
+		Order("-version").
+		Exec(ctx, &pluginVersions)
+
+	return pluginVersions, err
+}
+
+// GetPluginVersionsByStatus 获取多个插件指定状态的版本
+func GetPluginVersionsByStatus(ctx context.Context, pluginIDs []int, status int8) ([]*TblPluginVersion, error) {
+	pluginVersions := []*TblPluginVersion{}
+
+	if len(pluginIDs) == 0 {
+		return pluginVersions, nil
+	}
+
+	where := horm.Where{"plugin_id": pluginIDs, "status": status}
+
+	_, err := GetTableORM("tbl_plugin_version").FindAll(where).Exec(ctx, &pluginVersions)
+
+	return pluginVersions, err
+}
@@ -28,18 +28,22 @@ func AddProduct(ctx context.Context, product *TblProduct) (int, error) {
 		return 0, err
 	}
 
+	auditCreate(ctx, "tbl_product", "id", horm.Where{"id": modRet.ID.Int()})
+
 	return modRet.ID.Int(), nil
 }
 
 func UpdateProductByID(ctx context.Context, id int, update horm.Map) error {
-	_, err := GetTableORM("tbl_product").Eq("id", id).Update(update).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_product", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_product").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
 }
 
 func GetProductByID(ctx context.Context, id int) (bool, *TblProduct, error) {
 	product := TblProduct{}
 
-	isNil, err := GetTableORM("tbl_product").FindBy("id", id).Exec(ctx, &product)
+	isNil, err := GetTableORM("tbl_product").Find(scopeProducts(ctx, horm.Where{"id": id})).Exec(ctx, &product)
 
 	return isNil, &product, err
 }
@@ -55,10 +59,31 @@ func GetProductList(ctx context.Context, status int8, page, size int) (*proto.De
 	}
 
 	_, err := GetTableORM("tbl_product").
-		FindAll(where).
+		FindAll(scopeProducts(ctx, where)).
 		Order("-id").
 		Page(page, size).
 		Exec(ctx, &pageRet, &products)
 
 	return &pageRet, products, err
 }
+
+func GetProductByIds(ctx context.Context, ids []int) ([]*TblProduct, error) {
+	products := []*TblProduct{}
+
+	_, err := GetTableORM("tbl_product").FindAll(scopeProducts(ctx, horm.Where{"id": ids})).Exec(ctx, &products)
+
+	return products, err
+}
+
+// GetProductsAfterID 按 id 顺序遍历
+func GetProductsAfterID(ctx context.Context, lastID, limit int) ([]*TblProduct, error) {
+	products := []*TblProduct{}
+
+	_, err := GetTableORM("tbl_product").
+		FindAll(scopeProducts(ctx, horm.Where{"id >": lastID})).
+		Order("id").
+		Limit(limit).
+		Exec(ctx, &products)
+
+	return products, err
+}
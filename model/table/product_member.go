@@ -30,17 +30,28 @@ func InsertProductMember(ctx context.Context, member *TblProductMember) (int, er
 		return 0, err
 	}
 
+	auditCreate(ctx, "tbl_product_member", "id", horm.Where{"id": modRet.ID.Int()})
+
 	return modRet.ID.Int(), nil
 }
 
 func ReplaceProductMember(ctx context.Context, member horm.Map) error {
-	_, err := GetTableORM("tbl_product_member").Replace(member).Exec(ctx)
-	return err
+	where := horm.Where{
+		"product_id": member["product_id"],
+		"userid":     member["userid"],
+	}
+
+	return auditWrite(ctx, "tbl_product_member", "id", where, func() error {
+		_, err := GetTableORM("tbl_product_member").Replace(member).Exec(ctx)
+		return err
+	})
 }
 
 func UpdateProductMemberByID(ctx context.Context, id int, update horm.Map) error {
-	_, err := GetTableORM("tbl_product_member").Update(update).Eq("id", id).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_product_member", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_product_member").Update(update).Eq("id", id).Exec(ctx)
+		return err
+	})
 }
 
 func GetProductMemberByUser(ctx context.Context, productID int, userid uint64) (bool, *TblProductMember, error) {
@@ -109,3 +120,97 @@ func GetProductMembersJoined(ctx context.Context,
 
 	return &pageRet, members, err
 }
+
+// GetProductMembersByUser 用户在所有产品的成员记录
+func GetProductMembersByUser(ctx context.Context, userid uint64) ([]*TblProductMember, error) {
+	members := []*TblProductMember{}
+
+	_, err := GetTableORM("tbl_product_member").FindAllBy("userid", userid).Exec(ctx, &members)
+
+	return members, err
+}
+
+// GetProductsMembersByStatus 多个产品指定状态的成员，如待审批成员
+func GetProductsMembersByStatus(ctx context.Context,
+	productIDs []int, status []int8) ([]*TblProductMember, error) {
+	members := []*TblProductMember{}
+
+	if len(productIDs) == 0 {
+		return members, nil
+	}
+
+	where := horm.Where{
+		"product_id": productIDs,
+		"status":     status,
+	}
+
+	_, err := GetTableORM("tbl_product_member").FindAll(where).Exec(ctx, &members)
+
+	return members, err
+}
+
+// GetExpiredProductMembers 已过期但仍为已加入状态（含续期、角色变更审批中）的产品成员
+func GetExpiredProductMembers(ctx context.Context, now int64, limit int) ([]*TblProductMember, error) {
+	members := []*TblProductMember{}
+
+	where := horm.Where{
+		"status":         []int8{consts.ProductMemberStatusJoined, consts.ProductMemberStatusRenewal, consts.ProductMemberStatusChangeRole},
+		"expire_time >":  0,
+		"expire_time <=": now,
+	}
+
+	_, err := GetTableORM("tbl_product_member").FindAll(where).Order("expire_time").Limit(limit).Exec(ctx, &members)
+
+	return members, err
+}
+
+// GetExpiringProductMembers 在 (now, before] 之间过期且尚未提醒的产品成员
+func GetExpiringProductMembers(ctx context.Context, now, before int64, limit int) ([]*TblProductMember, error) {
+	members := []*TblProductMember{}
+
+	where := horm.Where{
+		"status":         []int8{consts.ProductMemberStatusJoined, consts.ProductMemberStatusRenewal, consts.ProductMemberStatusChangeRole},
+		"expire_time >":  now,
+		"expire_time <=": before,
+		"remind_time":    0,
+	}
+
+	_, err := GetTableORM("tbl_product_member").FindAll(where).Order("expire_time").Limit(limit).Exec(ctx, &members)
+
+	return members, err
+}
+
+// ExpireProductMember 将过期成员置为已过期，读取之后状态、过期时间被修改（如续期审批通过）的成员不会被修改，返回是否修改成功
+func ExpireProductMember(ctx context.Context, member *TblProductMember) (bool, error) {
+	where := horm.Where{
+		"id":          member.Id,
+		"status":      member.Status,
+		"expire_time": member.ExpireTime,
+	}
+
+	modRet := proto.ModRet{}
+
+	err := auditWrite(ctx, "tbl_product_member", "id", horm.Where{"id": member.Id}, func() error {
+		_, err := GetTableORM("tbl_product_member").Update(horm.Map{"status": consts.ProductMemberStatusExpired}, where).Exec(ctx, &modRet)
+		return err
+	})
+
+	return modRet.RowAffected > 0, err
+}
+
+// ClaimProductMemberRemind 抢占过期提醒，同一过期时间只会提醒一次
+func ClaimProductMemberRemind(ctx context.Context, member *TblProductMember, now int64) (bool, error) {
+	where := horm.Where{
+		"id":          member.Id,
+		"expire_time": member.ExpireTime,
+		"remind_time": 0,
+	}
+
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_product_member").Update(horm.Map{"remind_time": now}, where).Exec(ctx, &modRet)
+	if err != nil {
+		return false, err
+	}
+
+	return modRet.RowAffected > 0, nil
+}
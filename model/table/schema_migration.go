@@ -0,0 +1,52 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+	"time"
+
+	"github.com/horm-database/common/consts"
+)
+
+type TblSchemaMigration struct {
+	Version   int       `orm:"version,int" json:"version"`            // 迁移版本号
+	Name      string    `orm:"name,string" json:"name"`               // 迁移名
+	Checksum  string    `orm:"checksum,string" json:"checksum"`       // 迁移 sql 的 sha256
+	AppliedAt time.Time `orm:"applied_at,datetime" json:"applied_at"` // 执行时间
+}
+
+// ExecSQL 直接执行 sql 语句，用于建表等 DDL，一次只能执行一条语句
+func ExecSQL(ctx context.Context, sql string, args ...interface{}) error {
+	_, err := GetTableORM("schema_migrations").Op(consts.OpUpdate).Source(sql, args...).Exec(ctx)
+	return err
+}
+
+// GetSchemaMigrations 获取已执行的迁移，按版本号升序
+func GetSchemaMigrations(ctx context.Context) ([]*TblSchemaMigration, error) {
+	migrations := []*TblSchemaMigration{}
+	_, err := GetTableORM("schema_migrations").FindAll().Order("version").Exec(ctx, &migrations)
+	return migrations, err
+}
+
+func InsertSchemaMigration(ctx context.Context, migration *TblSchemaMigration) error {
+	_, err := GetTableORM("schema_migrations").Insert(migration).Exec(ctx)
+	return err
+}
+
+func DelSchemaMigration(ctx context.Context, version int) error {
+	_, err := GetTableORM("schema_migrations").DeleteBy("version", version).Exec(ctx)
+	return err
+}
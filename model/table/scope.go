@@ -0,0 +1,166 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+	"time"
+
+	"github.com/horm-database/common/codec"
+	"github.com/horm-database/common/proto"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/consts"
+	"github.com/horm-database/manage/srv/transport/web/head"
+)
+
+// 一个部署可服务多个空间。产品属于空间（tbl_product.workspace_id），仓库、表通过所属产品归属空间，
+// 应用、插件的归属记录在 tbl_workspace_resource，官方插件所有空间可见。
+// 查询按当前请求指定的空间限定范围，后台任务等非请求上下文不限定空间。
+
+// ReqWorkspaceID 当前请求指定的空间，非请求上下文返回 0
+func ReqWorkspaceID(ctx context.Context) int {
+	if h, ok := codec.Message(ctx).ServerReqHead().(*head.WebReqHeader); ok && h != nil {
+		return int(h.WorkspaceId)
+	}
+
+	return 0
+}
+
+// AddWorkspaceResource 记录应用、插件所属的空间
+func AddWorkspaceResource(ctx context.Context, workspaceID int, resType int8, resID uint64) error {
+	res := TblWorkspaceResource{
+		WorkspaceID: workspaceID,
+		ResType:     resType,
+		ResID:       resID,
+		CreatedAt:   time.Now(),
+	}
+
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_workspace_resource").Insert(&res).Exec(ctx, &modRet)
+	if err != nil {
+		return err
+	}
+
+	auditCreate(ctx, "tbl_workspace_resource", "id", horm.Where{"id": modRet.ID.Int()})
+
+	return nil
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// scopeProducts 产品限定在当前空间
+func scopeProducts(ctx context.Context, where horm.Where) horm.Where {
+	if workspaceID := ReqWorkspaceID(ctx); workspaceID != 0 {
+		where["workspace_id"] = workspaceID
+	}
+
+	return where
+}
+
+// scopeDBs 仓库限定在当前空间的产品下
+func scopeDBs(ctx context.Context, where horm.Where) (horm.Where, error) {
+	workspaceID := ReqWorkspaceID(ctx)
+	if workspaceID == 0 {
+		return where, nil
+	}
+
+	// 以子查询过滤，避免每次查询先取出空间下的全部产品
+	where["AND"] = horm.Where{
+		"~`product_id` IN (SELECT `id` FROM `tbl_product` WHERE `workspace_id` = ?)": workspaceID,
+	}
+
+	return where, nil
+}
+
+// scopeTables 表限定在当前空间的仓库下
+func scopeTables(ctx context.Context, where horm.Where) (horm.Where, error) {
+	workspaceID := ReqWorkspaceID(ctx)
+	if workspaceID == 0 {
+		return where, nil
+	}
+
+	// 以子查询过滤，避免每次查询先取出空间下的全部产品与仓库
+	where["AND"] = horm.Where{
+		"~`db` IN (SELECT d.`id` FROM `tbl_db` d JOIN `tbl_product` p ON d.`product_id` = p.`id` " +
+			"WHERE p.`workspace_id` = ?)": workspaceID,
+	}
+
+	return where, nil
+}
+
+// scopeApps 应用限定在当前空间，返回 false 表示当前空间没有应用，无需再查询
+func scopeApps(ctx context.Context, where horm.Where) (horm.Where, bool, error) {
+	workspaceID := ReqWorkspaceID(ctx)
+	if workspaceID == 0 {
+		return where, true, nil
+	}
+
+	appids, err := getWorkspaceResourceIDs(ctx, workspaceID, consts.WorkspaceResourceApp)
+	if err != nil || len(appids) == 0 {
+		return where, false, err
+	}
+
+	where["AND"] = horm.Where{"appid": appids}
+
+	return where, true, nil
+}
+
+// scopePlugins 插件限定为官方插件及当前空间的插件
+func scopePlugins(ctx context.Context, where horm.Where) (horm.Where, error) {
+	workspaceID := ReqWorkspaceID(ctx)
+	if workspaceID == 0 {
+		return where, nil
+	}
+
+	pluginIDs, err := getWorkspaceResourceIDs(ctx, workspaceID, consts.WorkspaceResourcePlugin)
+	if err != nil {
+		return where, err
+	}
+
+	if len(pluginIDs) == 0 {
+		where["AND"] = horm.Where{"source": consts.PluginSourceOfficial}
+	} else {
+		where["AND"] = horm.Where{
+			"OR": horm.Where{
+				"source": consts.PluginSourceOfficial,
+				"id":     pluginIDs,
+			},
+		}
+	}
+
+	return where, nil
+}
+
+// getWorkspaceResourceIDs 空间下指定类型的应用、插件id
+func getWorkspaceResourceIDs(ctx context.Context, workspaceID int, resType int8) ([]uint64, error) {
+	resources := []*TblWorkspaceResource{}
+
+	where := horm.Where{
+		"workspace_id": workspaceID,
+		"res_type":     resType,
+	}
+
+	_, err := GetTableORM("tbl_workspace_resource").FindAll(where).Column("res_id").Exec(ctx, &resources)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, 0, len(resources))
+	for _, res := range resources {
+		ids = append(ids, res.ResID)
+	}
+
+	return ids, nil
+}
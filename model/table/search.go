@@ -16,57 +16,81 @@ package table
 
 import (
 	"context"
-	"time"
+	"strings"
 
-	"github.com/horm-database/common/codec"
 	"github.com/horm-database/go-horm/horm"
 )
 
-func AddSearchKeyword(ctx context.Context, sk *TblSearchKeyword) {
-	go func(ctx context.Context) {
-		_, err := GetTableORM("tbl_search_keyword").Replace(sk).Exec(ctx)
-		if err != nil { //重试
-			time.Sleep(time.Second)
-			_, err = GetTableORM("tbl_search_keyword").Replace(sk).Exec(ctx)
-			if err != nil {
-				time.Sleep(time.Second)
-				_, _ = GetTableORM("tbl_search_keyword").Replace(sk).Exec(ctx)
-			}
-		}
-	}(codec.CloneContext(ctx))
+// ReplaceSearchKeywords 写入检索信息，(type, sid, field, skey) 相同的记录会被覆盖
+func ReplaceSearchKeywords(ctx context.Context, sks []*TblSearchKeyword) error {
+	if len(sks) == 0 {
+		return nil
+	}
+
+	_, err := GetTableORM("tbl_search_keyword").Replace(sks).Exec(ctx)
+	return err
+}
+
+func DelSearchKeywordsByIds(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := GetTableORM("tbl_search_keyword").Delete(horm.Where{"id": ids}).Exec(ctx)
+	return err
+}
+
+func GetSearchKeywordsBySid(ctx context.Context, typ int8, sid int) ([]*TblSearchKeyword, error) {
+	sks := []*TblSearchKeyword{}
+
+	where := horm.Where{
+		"type": typ,
+		"sid":  sid,
+	}
+
+	_, err := GetTableORM("tbl_search_keyword").FindAll(where).Exec(ctx, &sks)
+
+	return sks, err
 }
 
-func AddSearchKeywords(ctx context.Context, sks []*TblSearchKeyword) {
-	go func(ctx context.Context) {
-		_, err := GetTableORM("tbl_search_keyword").Replace(sks).Exec(ctx)
-		if err != nil { //重试
-			time.Sleep(time.Second)
-			_, err = GetTableORM("tbl_search_keyword").Replace(sks).Exec(ctx)
-			if err != nil { //重试
-				time.Sleep(time.Second)
-				_, _ = GetTableORM("tbl_search_keyword").Replace(sks).Exec(ctx)
-			}
-		}
-	}(codec.CloneContext(ctx))
+// GetSearchKeywordsAfterID 按 id 顺序遍历检索信息
+func GetSearchKeywordsAfterID(ctx context.Context, lastID, limit int) ([]*TblSearchKeyword, error) {
+	sks := []*TblSearchKeyword{}
+
+	where := horm.Where{"id >": lastID}
+	if workspaceID := ReqWorkspaceID(ctx); workspaceID != 0 {
+		where["workspace_id"] = workspaceID
+	}
+
+	_, err := GetTableORM("tbl_search_keyword").
+		FindAll(where).
+		Order("id").
+		Limit(limit).
+		Exec(ctx, &sks)
+
+	return sks, err
 }
 
-func DelSearchKeywords(ctx context.Context, typ int8, sid int, field, skey string) {
+// SearchKeywords 检索内容包含关键词的记录，typ 为 0 时检索全部类型
+func SearchKeywords(ctx context.Context, keyword string, typ int8, limit int) ([]*TblSearchKeyword, error) {
+	sks := []*TblSearchKeyword{}
+
 	where := horm.Where{
-		"type":  typ,
-		"sid":   sid,
-		"field": field,
-		"skey":  skey,
+		"scontent ~": "%" + likeEscaper.Replace(keyword) + "%",
 	}
 
-	go func(ctx context.Context) {
-		_, err := GetTableORM("tbl_search_keyword").Delete(where).Exec(ctx)
-		if err != nil { //重试
-			time.Sleep(time.Second)
-			_, err = GetTableORM("tbl_search_keyword").Delete(where).Exec(ctx)
-			if err != nil { //重试
-				time.Sleep(time.Second)
-				_, err = GetTableORM("tbl_search_keyword").Delete(where).Exec(ctx)
-			}
-		}
-	}(codec.CloneContext(ctx))
+	if typ > 0 {
+		where["type"] = typ
+	}
+
+	if workspaceID := ReqWorkspaceID(ctx); workspaceID != 0 {
+		where["workspace_id"] = workspaceID
+	}
+
+	_, err := GetTableORM("tbl_search_keyword").FindAll(where).Order("-id").Limit(limit).Exec(ctx, &sks)
+
+	return sks, err
 }
+
+// likeEscaper 转义 like 通配符，关键词按字面匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
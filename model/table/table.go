@@ -30,12 +30,16 @@ func AddTable(ctx context.Context, tb *obj.TblTable) (int, error) {
 		return 0, err
 	}
 
+	auditCreate(ctx, "tbl_table", "id", horm.Where{"id": modRet.ID.Int()})
+
 	return modRet.ID.Int(), nil
 }
 
 func UpdateTableByID(ctx context.Context, id int, update horm.Map) error {
-	_, err := GetTableORM("tbl_table").Eq("id", id).Update(update).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_table", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_table").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
 }
 
 func GetIndexTable(ctx context.Context, page, size int) (*proto.Detail, []*obj.TblTable, error) {
@@ -43,10 +47,12 @@ func GetIndexTable(ctx context.Context, page, size int) (*proto.Detail, []*obj.T
 
 	tables := []*obj.TblTable{}
 
-	where := horm.Where{}
-	where["status"] = consts.StatusOnline
+	where, err := scopeTables(ctx, horm.Where{"status": consts.StatusOnline})
+	if err != nil {
+		return &pageRet, tables, err
+	}
 
-	_, err := GetTableORM("tbl_table").
+	_, err = GetTableORM("tbl_table").
 		FindAll(where).
 		Order("-id").
 		Page(page, size).
@@ -57,7 +63,13 @@ func GetIndexTable(ctx context.Context, page, size int) (*proto.Detail, []*obj.T
 
 func GetTableByID(ctx context.Context, id int) (bool, *obj.TblTable, error) {
 	table := obj.TblTable{}
-	isNil, err := GetTableORM("tbl_table").FindBy("id", id).Exec(ctx, &table)
+
+	where, err := scopeTables(ctx, horm.Where{"id": id})
+	if err != nil {
+		return true, &table, err
+	}
+
+	isNil, err := GetTableORM("tbl_table").Find(where).Exec(ctx, &table)
 	return isNil, &table, err
 }
 
@@ -68,10 +80,12 @@ func GetTableByIds(ctx context.Context, ids []int) ([]*obj.TblTable, error) {
 		return tables, nil
 	}
 
-	where := horm.Where{}
-	where["id"] = ids
+	where, err := scopeTables(ctx, horm.Where{"id": ids})
+	if err != nil {
+		return tables, err
+	}
 
-	_, err := GetTableORM("tbl_table").
+	_, err = GetTableORM("tbl_table").
 		FindAll(where).
 		Order("-id").
 		Exec(ctx, &tables)
@@ -82,14 +96,36 @@ func GetTableByIds(ctx context.Context, ids []int) ([]*obj.TblTable, error) {
 func GetDBTables(ctx context.Context, dbID int) ([]*obj.TblTable, error) {
 	tables := []*obj.TblTable{}
 
-	_, err := GetTableORM("tbl_table").
-		FindAllBy("db", dbID).
+	where, err := scopeTables(ctx, horm.Where{"db": dbID})
+	if err != nil {
+		return tables, err
+	}
+
+	_, err = GetTableORM("tbl_table").
+		FindAll(where).
 		Order("-id").
 		Exec(ctx, &tables)
 
 	return tables, err
 }
 
+func GetDBsTables(ctx context.Context, dbIDs []int) ([]*obj.TblTable, error) {
+	tables := []*obj.TblTable{}
+
+	if len(dbIDs) == 0 {
+		return tables, nil
+	}
+
+	where, err := scopeTables(ctx, horm.Where{"db": dbIDs})
+	if err != nil {
+		return tables, err
+	}
+
+	_, err = GetTableORM("tbl_table").FindAll(where).Exec(ctx, &tables)
+
+	return tables, err
+}
+
 ///////////////////////////////// function /////////////////////////////////////////
 
 func TablesToMap(tables []*obj.TblTable) map[int]*obj.TblTable {
@@ -101,3 +137,21 @@ func TablesToMap(tables []*obj.TblTable) map[int]*obj.TblTable {
 
 	return ret
 }
+
+// GetTablesAfterID 按 id 顺序遍历
+func GetTablesAfterID(ctx context.Context, lastID, limit int) ([]*obj.TblTable, error) {
+	tables := []*obj.TblTable{}
+
+	where, err := scopeTables(ctx, horm.Where{"id >": lastID})
+	if err != nil {
+		return tables, err
+	}
+
+	_, err = GetTableORM("tbl_table").
+		FindAll(where).
+		Order("id").
+		Limit(limit).
+		Exec(ctx, &tables)
+
+	return tables, err
+}
@@ -16,12 +16,26 @@ package table
 
 import (
 	"context"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/horm-database/common/consts"
+	"github.com/horm-database/common/errs"
 	"github.com/horm-database/common/proto"
 	"github.com/horm-database/go-horm/horm"
 	"github.com/horm-database/server/model/table"
+	"github.com/samber/lo"
 )
 
+// TablePluginLink 插件在插件链中的位置
+type TablePluginLink struct {
+	ID     int
+	Type   int8
+	Front  int
+	Update horm.Map // 随插件链一起修改的其他字段
+}
+
 func InsertTablePlugin(ctx context.Context, tablePlugin *table.TblTablePlugin) (int, error) {
 	modRet := proto.ModRet{}
 	_, err := GetTableORM("tbl_table_plugin").Insert(tablePlugin).Exec(ctx, &modRet)
@@ -29,22 +43,30 @@ func InsertTablePlugin(ctx context.Context, tablePlugin *table.TblTablePlugin) (
 		return 0, err
 	}
 
+	auditCreate(ctx, "tbl_table_plugin", "id", horm.Where{"id": modRet.ID.Int()})
+
 	return modRet.ID.Int(), nil
 }
 
 func UpdateTablePluginByID(ctx context.Context, id int, update horm.Map) error {
-	_, err := GetTableORM("tbl_table_plugin").Eq("id", id).Update(update).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_table_plugin", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_table_plugin").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
 }
 
 func UpdateTablePluginByIDs(ctx context.Context, id []int, update horm.Map) error {
-	_, err := GetTableORM("tbl_table_plugin").Eq("id", id).Update(update).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_table_plugin", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_table_plugin").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
 }
 
 func DelTablePlugin(ctx context.Context, id int) error {
-	_, err := GetTableORM("tbl_table_plugin").DeleteBy("id", id).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_table_plugin", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_table_plugin").DeleteBy("id", id).Exec(ctx)
+		return err
+	})
 }
 
 func GetTablePluginByID(ctx context.Context, id int) (bool, *table.TblTablePlugin, error) {
@@ -72,6 +94,34 @@ func GetTablePlugins(ctx context.Context, tableID int, typ ...int8) ([]*table.Tb
 	return tablePlugins, err
 }
 
+// GetPluginTablePlugins 获取挂载了插件的所有表插件
+func GetPluginTablePlugins(ctx context.Context, pluginID int, typ ...int8) ([]*table.TblTablePlugin, error) {
+	tablePlugins := []*table.TblTablePlugin{}
+
+	where := horm.Where{"plugin_id": pluginID}
+	if len(typ) > 0 {
+		where["type"] = typ
+	}
+
+	_, err := GetTableORM("tbl_table_plugin").FindAll(where).Order("table_id", "id").Exec(ctx, &tablePlugins)
+
+	return tablePlugins, err
+}
+
+// UpgradeTablePluginVersion 表插件版本仍为 fromVersion 时才更新，返回 false 表示版本已被修改
+func UpgradeTablePluginVersion(ctx context.Context, id, fromVersion int, update horm.Map) (bool, error) {
+	modRet := proto.ModRet{}
+
+	err := auditWrite(ctx, "tbl_table_plugin", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_table_plugin").
+			Update(update, horm.Where{"id": id, "plugin_version": fromVersion}).
+			Exec(ctx, &modRet)
+		return err
+	})
+
+	return modRet.RowAffected > 0, err
+}
+
 func GetTableBackPlugin(ctx context.Context, tableID int, typ int8, frontID int) ([]*table.TblTablePlugin, error) {
 	tablePlugins := []*table.TblTablePlugin{}
 
@@ -85,3 +135,90 @@ func GetTableBackPlugin(ctx context.Context, tableID int, typ int8, frontID int)
 
 	return tablePlugins, err
 }
+
+// GetTablePluginChainVersion 获取表插件链版本，首次获取时初始化为 0
+func GetTablePluginChainVersion(ctx context.Context, tableID int) (int, error) {
+	chain := TblTablePluginChain{}
+
+	isNil, err := GetTableORM("tbl_table_plugin_chain").FindBy("table_id", tableID).Exec(ctx, &chain)
+	if err != nil || !isNil {
+		return chain.Version, err
+	}
+
+	chain = TblTablePluginChain{
+		TableID:   tableID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	_, err = GetTableORM("tbl_table_plugin_chain").Insert(&chain).Exec(ctx)
+	if errs.Code(err) == 1062 { // 并发初始化
+		_, err = GetTableORM("tbl_table_plugin_chain").FindBy("table_id", tableID).Exec(ctx, &chain)
+		return chain.Version, err
+	}
+
+	return 0, err
+}
+
+// UpdateTablePluginChain 以一条语句原子地更新插件的类型、front 与 link.Update 中的其他字段，同时插件链版本加 1。
+// 插件链版本与 version 不一致（已被其他请求修改）时不做任何修改，返回 false
+func UpdateTablePluginChain(ctx context.Context, tableID, version int, links []*TablePluginLink) (bool, error) {
+	if len(links) == 0 {
+		return true, nil
+	}
+
+	ids := []int{}
+	typeArgs := []interface{}{}
+	frontArgs := []interface{}{}
+	fields := []string{}
+
+	for _, link := range links {
+		ids = append(ids, link.ID)
+		typeArgs = append(typeArgs, link.ID, link.Type)
+		frontArgs = append(frontArgs, link.ID, link.Front)
+
+		for field := range link.Update {
+			if lo.IndexOf(fields, field) == -1 {
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+
+	cases := strings.Repeat(" WHEN ? THEN ?", len(links))
+
+	sql := "UPDATE `tbl_table_plugin` p, `tbl_table_plugin_chain` c SET " +
+		"p.`type` = CASE p.`id`" + cases + " END, " +
+		"p.`front` = CASE p.`id`" + cases + " END, "
+
+	args := append(typeArgs, frontArgs...)
+
+	// 其他字段只修改指定了该字段的插件
+	for _, field := range fields {
+		sql += "p.`" + field + "` = CASE p.`id`"
+		for _, link := range links {
+			if value, ok := link.Update[field]; ok {
+				sql += " WHEN ? THEN ?"
+				args = append(args, link.ID, value)
+			}
+		}
+		sql += " ELSE p.`" + field + "` END, "
+	}
+
+	sql += "c.`version` = c.`version` + 1 " +
+		"WHERE c.`table_id` = ? AND c.`version` = ? AND p.`table_id` = ? " +
+		"AND p.`id` IN (?" + strings.Repeat(",?", len(ids)-1) + ")"
+
+	args = append(args, tableID, version, tableID)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	modRet := proto.ModRet{}
+	err := auditWrite(ctx, "tbl_table_plugin", "id", horm.Where{"id": ids}, func() error {
+		_, err := GetTableORM("tbl_table_plugin").Op(consts.OpUpdate).Source(sql, args...).Exec(ctx, &modRet)
+		return err
+	})
+
+	return modRet.RowAffected > 0, err
+}
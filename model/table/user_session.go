@@ -0,0 +1,74 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+	"time"
+
+	"github.com/horm-database/common/proto"
+	"github.com/horm-database/go-horm/horm"
+	"github.com/horm-database/manage/consts"
+)
+
+func InsertUserSession(ctx context.Context, session *TblUserSession) (int, error) {
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_user_session").Insert(session).Exec(ctx, &modRet)
+	if err != nil {
+		return 0, err
+	}
+
+	return modRet.ID.Int(), nil
+}
+
+func UpdateUserSessionByID(ctx context.Context, id int, update horm.Map) error {
+	_, err := GetTableORM("tbl_user_session").Update(update).Eq("id", id).Exec(ctx)
+	return err
+}
+
+// GetUserOnlineSessions 获取用户未退出且未过期的会话，按最近访问时间倒序
+func GetUserOnlineSessions(ctx context.Context, userid uint64) ([]*TblUserSession, error) {
+	sessions := []*TblUserSession{}
+
+	where := horm.Where{
+		"userid":        userid,
+		"status":        consts.SessionStatusOnline,
+		"expire_time >": time.Now().Unix(),
+	}
+
+	_, err := GetTableORM("tbl_user_session").
+		FindAll(where).
+		Order("-last_active_time").
+		Exec(ctx, &sessions)
+
+	return sessions, err
+}
+
+// LogoutUserSessions 退出用户会话，ids 为空时退出该用户所有会话
+func LogoutUserSessions(ctx context.Context, userid uint64, ids ...int) error {
+	where := horm.Where{
+		"userid": userid,
+		"status": consts.SessionStatusOnline,
+	}
+
+	if len(ids) > 0 {
+		where["id"] = ids
+	}
+
+	update := horm.Map{"status": consts.SessionStatusLogout}
+
+	_, err := GetTableORM("tbl_user_session").Update(update, where).Exec(ctx)
+	return err
+}
@@ -17,19 +17,21 @@ package table
 import (
 	"context"
 
+	"github.com/horm-database/common/proto"
 	"github.com/horm-database/go-horm/horm"
 	"github.com/horm-database/server/model/table"
 )
 
-var CurrentWorkspace *table.TblWorkspace
-
-func GetCurrentWorkspace(ctx context.Context) (*table.TblWorkspace, error) {
-	if CurrentWorkspace == nil {
-		_, err := GetTableORM("tbl_workspace").Find().Exec(ctx, &CurrentWorkspace)
-		return CurrentWorkspace, err
+func InsertWorkspace(ctx context.Context, workspace *table.TblWorkspace) (int, error) {
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_workspace").Insert(workspace).Exec(ctx, &modRet)
+	if err != nil {
+		return 0, err
 	}
 
-	return CurrentWorkspace, nil
+	auditCreate(ctx, "tbl_workspace", "id", horm.Where{"id": modRet.ID.Int()})
+
+	return modRet.ID.Int(), nil
 }
 
 func GetWorkspace(ctx context.Context, workspace string) (*table.TblWorkspace, error) {
@@ -43,10 +45,6 @@ func GetWorkspace(ctx context.Context, workspace string) (*table.TblWorkspace, e
 }
 
 func GetWorkspaceByID(ctx context.Context, id int) (*table.TblWorkspace, error) {
-	if CurrentWorkspace != nil && CurrentWorkspace.Id == id {
-		return CurrentWorkspace, nil
-	}
-
 	workspaceInfo := table.TblWorkspace{}
 
 	_, err := GetTableORM("tbl_workspace").FindBy("id", id).Exec(ctx, &workspaceInfo)
@@ -55,6 +53,20 @@ func GetWorkspaceByID(ctx context.Context, id int) (*table.TblWorkspace, error)
 }
 
 func UpdateWorkspaceByID(ctx context.Context, id int, update horm.Map) error {
-	_, err := GetTableORM("tbl_workspace").Eq("id", id).Update(update).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_workspace", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_workspace").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
+}
+
+func GetWorkspaceByIds(ctx context.Context, ids []int) ([]*table.TblWorkspace, error) {
+	workspaces := []*table.TblWorkspace{}
+
+	if len(ids) == 0 {
+		return workspaces, nil
+	}
+
+	_, err := GetTableORM("tbl_workspace").FindAllBy("id", ids).Order("id").Exec(ctx, &workspaces)
+
+	return workspaces, err
 }
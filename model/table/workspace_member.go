@@ -23,19 +23,35 @@ import (
 	"github.com/horm-database/manage/consts"
 )
 
-func InsertWorkspaceMember(ctx context.Context, member *TblWorkspaceMember) error {
-	_, err := GetTableORM("tbl_workspace_member").Insert(member).Exec(ctx)
-	return err
+func InsertWorkspaceMember(ctx context.Context, member *TblWorkspaceMember) (int, error) {
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_workspace_member").Insert(member).Exec(ctx, &modRet)
+	if err != nil {
+		return 0, err
+	}
+
+	auditCreate(ctx, "tbl_workspace_member", "id", horm.Where{"id": modRet.ID.Int()})
+
+	return modRet.ID.Int(), nil
 }
 
 func ReplaceWorkspaceMember(ctx context.Context, member horm.Map) error {
-	_, err := GetTableORM("tbl_workspace_member").Replace(member).Exec(ctx)
-	return err
+	where := horm.Where{
+		"workspace_id": member["workspace_id"],
+		"userid":       member["userid"],
+	}
+
+	return auditWrite(ctx, "tbl_workspace_member", "id", where, func() error {
+		_, err := GetTableORM("tbl_workspace_member").Replace(member).Exec(ctx)
+		return err
+	})
 }
 
 func UpdateWorkspaceMemberByID(ctx context.Context, id int, update horm.Map) error {
-	_, err := GetTableORM("tbl_workspace_member").Update(update).Eq("id", id).Exec(ctx)
-	return err
+	return auditWrite(ctx, "tbl_workspace_member", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_workspace_member").Update(update).Eq("id", id).Exec(ctx)
+		return err
+	})
 }
 
 func GetWorkspaceMemberByUser(ctx context.Context, workspaceID int, userid uint64) (bool, *TblWorkspaceMember, error) {
@@ -64,6 +80,15 @@ func GetWorkspaceMemberByUsers(ctx context.Context, workspaceID int, userIds []u
 	return members, err
 }
 
+// GetWorkspaceMembersByUser 用户在所有空间的成员记录
+func GetWorkspaceMembersByUser(ctx context.Context, userid uint64) ([]*TblWorkspaceMember, error) {
+	members := []*TblWorkspaceMember{}
+
+	_, err := GetTableORM("tbl_workspace_member").FindAllBy("userid", userid).Exec(ctx, &members)
+
+	return members, err
+}
+
 func GetWorkspaceMembersAll(ctx context.Context,
 	workspaceID, page, size int) (*proto.Detail, []*TblWorkspaceMember, error) {
 	pageRet := proto.Detail{}
@@ -104,3 +129,84 @@ func GetWorkspaceMembersJoined(ctx context.Context,
 
 	return &pageRet, members, err
 }
+
+// GetWorkspaceMembersByStatus 空间指定状态的成员，如待审批成员
+func GetWorkspaceMembersByStatus(ctx context.Context,
+	workspaceID int, status []int8) ([]*TblWorkspaceMember, error) {
+	members := []*TblWorkspaceMember{}
+
+	where := horm.Where{
+		"workspace_id": workspaceID,
+		"status":       status,
+	}
+
+	_, err := GetTableORM("tbl_workspace_member").FindAll(where).Exec(ctx, &members)
+
+	return members, err
+}
+
+// GetExpiredWorkspaceMembers 已过期但仍为已加入状态（含续期、角色变更审批中）的空间成员
+func GetExpiredWorkspaceMembers(ctx context.Context, now int64, limit int) ([]*TblWorkspaceMember, error) {
+	members := []*TblWorkspaceMember{}
+
+	where := horm.Where{
+		"status":         []int8{consts.WorkspaceMemberStatusJoined, consts.WorkspaceMemberStatusRenewal},
+		"expire_time >":  0,
+		"expire_time <=": now,
+	}
+
+	_, err := GetTableORM("tbl_workspace_member").FindAll(where).Order("expire_time").Limit(limit).Exec(ctx, &members)
+
+	return members, err
+}
+
+// GetExpiringWorkspaceMembers 在 (now, before] 之间过期且尚未提醒的空间成员
+func GetExpiringWorkspaceMembers(ctx context.Context, now, before int64, limit int) ([]*TblWorkspaceMember, error) {
+	members := []*TblWorkspaceMember{}
+
+	where := horm.Where{
+		"status":         []int8{consts.WorkspaceMemberStatusJoined, consts.WorkspaceMemberStatusRenewal},
+		"expire_time >":  now,
+		"expire_time <=": before,
+		"remind_time":    0,
+	}
+
+	_, err := GetTableORM("tbl_workspace_member").FindAll(where).Order("expire_time").Limit(limit).Exec(ctx, &members)
+
+	return members, err
+}
+
+// ExpireWorkspaceMember 将过期成员置为已过期，读取之后状态、过期时间被修改（如续期审批通过）的成员不会被修改，返回是否修改成功
+func ExpireWorkspaceMember(ctx context.Context, member *TblWorkspaceMember) (bool, error) {
+	where := horm.Where{
+		"id":          member.Id,
+		"status":      member.Status,
+		"expire_time": member.ExpireTime,
+	}
+
+	modRet := proto.ModRet{}
+
+	err := auditWrite(ctx, "tbl_workspace_member", "id", horm.Where{"id": member.Id}, func() error {
+		_, err := GetTableORM("tbl_workspace_member").Update(horm.Map{"status": consts.WorkspaceMemberStatusExpired}, where).Exec(ctx, &modRet)
+		return err
+	})
+
+	return modRet.RowAffected > 0, err
+}
+
+// ClaimWorkspaceMemberRemind 抢占过期提醒，同一过期时间只会提醒一次
+func ClaimWorkspaceMemberRemind(ctx context.Context, member *TblWorkspaceMember, now int64) (bool, error) {
+	where := horm.Where{
+		"id":          member.Id,
+		"expire_time": member.ExpireTime,
+		"remind_time": 0,
+	}
+
+	modRet := proto.ModRet{}
+	_, err := GetTableORM("tbl_workspace_member").Update(horm.Map{"remind_time": now}, where).Exec(ctx, &modRet)
+	if err != nil {
+		return false, err
+	}
+
+	return modRet.RowAffected > 0, nil
+}
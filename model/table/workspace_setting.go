@@ -0,0 +1,45 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package table
+
+import (
+	"context"
+
+	"github.com/horm-database/go-horm/horm"
+)
+
+func GetWorkspaceSetting(ctx context.Context, workspaceID int) (bool, *TblWorkspaceSetting, error) {
+	setting := TblWorkspaceSetting{}
+	isNil, err := GetTableORM("tbl_workspace_setting").FindBy("workspace_id", workspaceID).Exec(ctx, &setting)
+	return isNil, &setting, err
+}
+
+func InsertWorkspaceSetting(ctx context.Context, setting *TblWorkspaceSetting) error {
+	_, err := GetTableORM("tbl_workspace_setting").Insert(setting).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	auditCreate(ctx, "tbl_workspace_setting", "id", horm.Where{"workspace_id": setting.WorkspaceID})
+
+	return nil
+}
+
+func UpdateWorkspaceSettingByID(ctx context.Context, id int, update horm.Map) error {
+	return auditWrite(ctx, "tbl_workspace_setting", "id", horm.Where{"id": id}, func() error {
+		_, err := GetTableORM("tbl_workspace_setting").Eq("id", id).Update(update).Exec(ctx)
+		return err
+	})
+}
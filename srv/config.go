@@ -29,6 +29,25 @@ const (
 	confFile           = "./server.yaml"
 	defaultIdleTimeout = 60000 // 单位 ms
 	maxCloseWaitTime   = 10 * time.Second
+	defaultSignSkew    = 300000 // 单位 ms
+
+	defaultSessionIdleTimeout = 7 * 24 * 3600  // 单位 s
+	defaultSessionMaxAge      = 30 * 24 * 3600 // 单位 s
+	defaultSessionMaxNum      = 10
+
+	defaultOutboxWorkers      = 4
+	defaultOutboxPollInterval = 1000 // 单位 ms
+	defaultOutboxBatchSize    = 100
+	defaultOutboxMaxRetry     = 10
+	defaultOutboxBackoffBase  = 1000   // 单位 ms
+	defaultOutboxBackoffMax   = 600000 // 单位 ms
+	defaultOutboxLease        = 60000  // 单位 ms
+	defaultOutboxDrainTimeout = 10000  // 单位 ms
+
+	defaultAccessExpireRemindDays = 7
+	defaultAccessExpireBatchSize  = 100
+
+	defaultScheduleBatchSize = 100
 )
 
 // config 配置
@@ -53,6 +72,66 @@ type config struct {
 		CACert           string `yaml:"ca_cert"`             // ca cert
 	}
 
+	Auth struct {
+		SignSkew           int `yaml:"sign_skew"`            // 请求时间戳与服务器时间允许的最大误差，超过则签名失效（单位 ms），默认 5 分钟
+		SessionIdleTimeout int `yaml:"session_idle_timeout"` // 登录会话最大空闲时间，超过未访问则需重新登录（单位 s），默认 7 天
+		SessionMaxAge      int `yaml:"session_max_age"`      // 登录会话最长有效期，无论是否活跃（单位 s），默认 30 天
+		SessionMaxNum      int `yaml:"session_max_num"`      // 每个用户同时在线的最大设备数，超过则最早的会话被挤下线，默认 10
+	}
+
+	Workspace struct {
+		AllowCreate bool     `yaml:"allow_create"` // 是否允许所有登录用户创建空间，默认否
+		Creators    []uint64 `yaml:"creators"`     // 允许创建空间的用户 id，allow_create 为 false 时生效
+	}
+
+	Outbox struct {
+		Workers      int `yaml:"workers"`       // 并发处理任务的 worker 数，默认 4
+		PollInterval int `yaml:"poll_interval"` // 轮询到期任务的间隔（单位 ms），默认 1s
+		BatchSize    int `yaml:"batch_size"`    // 每次轮询读取的最大任务数，默认 100
+		MaxRetry     int `yaml:"max_retry"`     // 最大重试次数，超过则任务置为失败，默认 10
+		BackoffBase  int `yaml:"backoff_base"`  // 首次重试间隔，之后每次翻倍（单位 ms），默认 1s
+		BackoffMax   int `yaml:"backoff_max"`   // 最大重试间隔（单位 ms），默认 10 分钟
+		Lease        int `yaml:"lease"`         // 单个任务最长处理时间，超时后可被其他实例重新处理（单位 ms），默认 1 分钟
+		DrainTimeout int `yaml:"drain_timeout"` // 服务关闭时处理剩余任务的最长等待时间（单位 ms），默认 10s
+	}
+
+	Schedule struct {
+		Jobs      map[string]string `yaml:"jobs"`       // 覆盖任务的默认执行计划，key 为任务名，值为 cron 表达式或 @every 间隔，off 表示不执行
+		BatchSize int               `yaml:"batch_size"` // 成员过期等任务每次执行处理的最大记录数，默认 100
+	}
+
+	AccessExpire struct {
+		RemindDays int `yaml:"remind_days"` // 过期前多少天提醒应用管理员，默认 7 天
+		BatchSize  int `yaml:"batch_size"`  // 每次检查处理的最大权限记录数，默认 100
+	} `yaml:"access_expire"`
+
+	Mail struct {
+		Provider      string `yaml:"provider"`       // 邮件发送方式：smtp、file（写入本地目录）、webhook（POST 到 http 接口）
+		FromName      string `yaml:"from_name"`      // 发件人名称
+		FromAddr      string `yaml:"from_addr"`      // 发件人邮箱
+		TemplateDir   string `yaml:"template_dir"`   // 邮件模板目录，模板文件为 {模板名}.{语言}.html，不存在时使用内置模板
+		DefaultLocale string `yaml:"default_locale"` // 默认语言，默认 zh-CN
+
+		SMTP struct {
+			Host     string `yaml:"host"`     // smtp 服务器地址
+			Port     int    `yaml:"port"`     // smtp 服务器端口
+			TLS      string `yaml:"tls"`      // 加密方式：none、starttls、tls，默认 starttls
+			User     string `yaml:"user"`     // 认证账号，为空则不认证
+			Password string `yaml:"password"` // 认证密码，支持 ${ENV} 形式读取环境变量
+			Timeout  int    `yaml:"timeout"`  // 超时时间（单位 ms），默认 10s
+		} `yaml:"smtp"`
+
+		File struct {
+			Dir string `yaml:"dir"` // 邮件写入目录，默认 ./mail
+		} `yaml:"file"`
+
+		Webhook struct {
+			URL     string            `yaml:"url"`     // 接口地址
+			Headers map[string]string `yaml:"headers"` // 请求头，值支持 ${ENV} 形式读取环境变量
+			Timeout int               `yaml:"timeout"` // 超时时间（单位 ms），默认 10s
+		} `yaml:"webhook"`
+	}
+
 	Log []*logger.Config `yaml:"log"`
 
 	// Register 北极星服务治理
@@ -75,11 +154,75 @@ func loadConfig(configPath string) (*config, error) {
 
 	cfg.Server.IdleTime = defaultIdleTimeout
 
+	if cfg.Auth.SignSkew <= 0 {
+		cfg.Auth.SignSkew = defaultSignSkew
+	}
+
+	if cfg.Auth.SessionIdleTimeout <= 0 {
+		cfg.Auth.SessionIdleTimeout = defaultSessionIdleTimeout
+	}
+
+	if cfg.Auth.SessionMaxAge <= 0 {
+		cfg.Auth.SessionMaxAge = defaultSessionMaxAge
+	}
+
+	if cfg.Auth.SessionMaxNum <= 0 {
+		cfg.Auth.SessionMaxNum = defaultSessionMaxNum
+	}
+
+	setOutboxDefault(cfg)
+
+	if cfg.Schedule.BatchSize <= 0 {
+		cfg.Schedule.BatchSize = defaultScheduleBatchSize
+	}
+
+	if cfg.AccessExpire.RemindDays <= 0 {
+		cfg.AccessExpire.RemindDays = defaultAccessExpireRemindDays
+	}
+
+	if cfg.AccessExpire.BatchSize <= 0 {
+		cfg.AccessExpire.BatchSize = defaultAccessExpireBatchSize
+	}
+
 	globalConfig.Store(cfg)
 
 	return cfg, nil
 }
 
+func setOutboxDefault(cfg *config) {
+	if cfg.Outbox.Workers <= 0 {
+		cfg.Outbox.Workers = defaultOutboxWorkers
+	}
+
+	if cfg.Outbox.PollInterval <= 0 {
+		cfg.Outbox.PollInterval = defaultOutboxPollInterval
+	}
+
+	if cfg.Outbox.BatchSize <= 0 {
+		cfg.Outbox.BatchSize = defaultOutboxBatchSize
+	}
+
+	if cfg.Outbox.MaxRetry <= 0 {
+		cfg.Outbox.MaxRetry = defaultOutboxMaxRetry
+	}
+
+	if cfg.Outbox.BackoffBase <= 0 {
+		cfg.Outbox.BackoffBase = defaultOutboxBackoffBase
+	}
+
+	if cfg.Outbox.BackoffMax <= 0 {
+		cfg.Outbox.BackoffMax = defaultOutboxBackoffMax
+	}
+
+	if cfg.Outbox.Lease <= 0 {
+		cfg.Outbox.Lease = defaultOutboxLease
+	}
+
+	if cfg.Outbox.DrainTimeout <= 0 {
+		cfg.Outbox.DrainTimeout = defaultOutboxDrainTimeout
+	}
+}
+
 func parseConfigFile(configPath string) (*config, error) {
 	buf, err := ioutil.ReadFile(configPath)
 	if err != nil {
@@ -0,0 +1,175 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schedule 后台定时任务。任务按执行计划在每个实例上定时触发，每次触发前通过分布式锁选出唯一执行的实例，
+// 多实例部署时同一任务的同一次触发只会执行一次。服务关闭时停止触发，并等待正在执行的任务结束。
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/horm-database/common/errs"
+	"github.com/horm-database/common/log"
+	"github.com/horm-database/manage/srv"
+)
+
+// SpecOff 配置为 off 的任务不执行
+const SpecOff = "off"
+
+// lockPrefix 任务分布式锁 key 前缀，key 为 {前缀}{任务名}_{触发时间}
+const lockPrefix = "PreScheduleLock_"
+
+// Func 任务执行函数，返回的 error 只记录日志，不会重试，下次触发时再执行
+type Func func(ctx context.Context) error
+
+// Locker 分布式锁，key 不存在时加锁成功，expire（单位 s）到期后自动释放，签名同 cache.SetNXCacheByKey
+type Locker func(ctx context.Context, key string, value interface{}, expire int) (bool, error)
+
+type job struct {
+	name string
+	spec string
+	run  Func
+}
+
+var (
+	jobsLock = new(sync.Mutex)
+	jobs     []*job
+
+	stopCh  = make(chan struct{})
+	wg      sync.WaitGroup
+	started int32
+)
+
+// Register 注册任务，spec 为默认执行计划，可被配置 schedule.jobs 中的同名任务覆盖，需在 Start 之前调用
+func Register(name, spec string, run Func) {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+
+	jobs = append(jobs, &job{name: name, spec: spec, run: run})
+}
+
+// Start 启动所有已注册的任务，执行计划有误时返回错误，不启动任何任务
+func Start(ctx context.Context, locker Locker) error {
+	jobsLock.Lock()
+	defer jobsLock.Unlock()
+
+	overrides := srv.Config().Schedule.Jobs
+
+	specs := make([]Spec, len(jobs))
+	for i, j := range jobs {
+		if v, ok := overrides[j.name]; ok {
+			j.spec = v
+		}
+
+		if strings.TrimSpace(j.spec) == SpecOff {
+			continue
+		}
+
+		spec, err := ParseSpec(j.spec)
+		if err != nil {
+			return errs.Newf(errs.ErrSystem, "schedule job [%s] %v", j.name, err)
+		}
+
+		specs[i] = spec
+	}
+
+	if !atomic.CompareAndSwapInt32(&started, 0, 1) {
+		return nil
+	}
+
+	owner := fmt.Sprintf("%s:%d", srv.Config().LocalIP, os.Getpid())
+
+	for i, j := range jobs {
+		if specs[i] == nil {
+			log.Infof(ctx, "schedule job [%s] is off", j.name)
+			continue
+		}
+
+		wg.Add(1)
+		go j.loop(ctx, specs[i], locker, owner)
+	}
+
+	return nil
+}
+
+// Stop 停止触发任务，并等待正在执行的任务结束
+func Stop() {
+	if !atomic.CompareAndSwapInt32(&started, 1, 2) {
+		return
+	}
+
+	close(stopCh)
+	wg.Wait()
+}
+
+// loop 按执行计划触发任务，任务执行超过下次触发时间的，跳过错过的触发
+func (j *job) loop(ctx context.Context, spec Spec, locker Locker, owner string) {
+	defer wg.Done()
+
+	next := spec.Next(time.Now())
+
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// 锁的有效期持续到下次触发，避免时钟稍慢的实例在锁释放后重复执行本次触发
+		expire := int(spec.Next(next).Sub(next) / time.Second)
+		if expire < 1 {
+			expire = 1
+		}
+
+		key := fmt.Sprintf("%s%s_%d", lockPrefix, j.name, next.Unix())
+
+		ok, err := locker(ctx, key, owner, expire)
+		if err != nil {
+			log.Errorf(ctx, errs.ErrSystem, "schedule job [%s] lock error: %v", j.name, err)
+		} else if ok {
+			j.execute(ctx)
+		}
+
+		next = spec.Next(time.Now())
+	}
+
+	log.Errorf(ctx, errs.ErrSystem, "schedule job [%s] spec [%s] will never run again", j.name, j.spec)
+}
+
+// execute 执行任务，panic 视为执行失败
+func (j *job) execute(ctx context.Context) {
+	start := time.Now()
+
+	defer func() {
+		if e := recover(); e != nil {
+			log.Errorf(ctx, errs.ErrSystem, "schedule job [%s] panic: %v", j.name, e)
+		}
+	}()
+
+	if err := j.run(ctx); err != nil {
+		log.Errorf(ctx, errs.ErrSystem, "schedule job [%s] error: %v, cost %s", j.name, err, time.Since(start))
+		return
+	}
+
+	log.Infof(ctx, "schedule job [%s] done, cost %s", j.name, time.Since(start))
+}
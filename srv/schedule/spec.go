@@ -0,0 +1,199 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec 任务执行计划
+type Spec interface {
+	// Next 返回 t 之后的下一次执行时间
+	Next(t time.Time) time.Time
+}
+
+// 常用执行计划的简写
+var specAlias = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseSpec 解析执行计划，支持 @every <间隔>（如 @every 5m）、@hourly 等简写，
+// 及 5 段 cron 表达式：分 时 日 月 周，每段支持 *、*/n、a-b、a-b/n 及逗号分隔的列表。
+func ParseSpec(spec string) (Spec, error) {
+	spec = strings.TrimSpace(spec)
+
+	if alias, ok := specAlias[spec]; ok {
+		spec = alias
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec [%s]: %v", spec, err)
+		}
+
+		if d < time.Second {
+			return nil, fmt.Errorf("invalid spec [%s]: interval must be at least 1s", spec)
+		}
+
+		return every(d), nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid spec [%s]: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	var c cron
+	var err error
+
+	if c.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid spec [%s] minute: %v", spec, err)
+	}
+
+	if c.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid spec [%s] hour: %v", spec, err)
+	}
+
+	if c.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid spec [%s] day of month: %v", spec, err)
+	}
+
+	if c.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid spec [%s] month: %v", spec, err)
+	}
+
+	if c.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("invalid spec [%s] day of week: %v", spec, err)
+	}
+
+	if c.dow&(1<<7) != 0 { // 7 与 0 都表示周日
+		c.dow |= 1
+	}
+
+	c.domStar = fields[2] == "*"
+	c.dowStar = fields[4] == "*"
+
+	return &c, nil
+}
+
+// every 固定间隔执行，执行时间按间隔对齐（如 @every 5m 在每个整 5 分钟执行），多实例计算出的执行时间一致
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	d := time.Duration(e)
+	return t.Truncate(d).Add(d)
+}
+
+// cron 按位记录每段允许的取值
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// maxSearchSteps 查找次数上限，避免 2 月 30 日之类永远不会执行的计划死循环
+const maxSearchSteps = 5 * 366 * 24 * 60
+
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for i := 0; i < maxSearchSteps; i++ {
+		if c.match(t) {
+			return t
+		}
+
+		if c.month&(1<<uint(t.Month())) == 0 || !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		} else if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		} else {
+			t = t.Add(time.Minute)
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c *cron) match(t time.Time) bool {
+	return c.minute&(1<<uint(t.Minute())) != 0 &&
+		c.hour&(1<<uint(t.Hour())) != 0 &&
+		c.month&(1<<uint(t.Month())) != 0 &&
+		c.matchDay(t)
+}
+
+// matchDay 与 crontab 一致，日、周都有限定时满足其一即可
+func (c *cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// parseField 解析 cron 表达式的一段
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step [%s]", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		start, end := min, max
+
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value [%s]", part)
+			}
+
+			end = start
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value [%s]", part)
+				}
+			} else if step > 1 { // a/n 表示从 a 开始到最大值
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("value [%s] out of range [%d-%d]", part, min, max)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
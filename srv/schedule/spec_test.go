@@ -0,0 +1,169 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package schedule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		field    string
+		min, max int
+		want     []int
+		wantErr  bool
+	}{
+		{field: "*", min: 0, max: 5, want: []int{0, 1, 2, 3, 4, 5}},
+		{field: "*/2", min: 0, max: 5, want: []int{0, 2, 4}},
+		{field: "*/15", min: 0, max: 59, want: []int{0, 15, 30, 45}},
+		{field: "1-10/3", min: 0, max: 59, want: []int{1, 4, 7, 10}},
+		{field: "50/5", min: 0, max: 59, want: []int{50, 55}},
+		{field: "5/1", min: 0, max: 59, want: []int{5}},
+		{field: "3", min: 1, max: 31, want: []int{3}},
+		{field: "1,5,9-10", min: 0, max: 23, want: []int{1, 5, 9, 10}},
+		{field: "*/0", min: 0, max: 59, wantErr: true},
+		{field: "*/x", min: 0, max: 59, wantErr: true},
+		{field: "60", min: 0, max: 59, wantErr: true},
+		{field: "0", min: 1, max: 31, wantErr: true},
+		{field: "10-5", min: 0, max: 59, wantErr: true},
+		{field: "a-b", min: 0, max: 59, wantErr: true},
+		{field: "", min: 0, max: 59, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			bits, err := parseField(tt.field, tt.min, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseField() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			var got []int
+			for v := tt.min; v <= tt.max; v++ {
+				if bits&(1<<uint(v)) != 0 {
+					got = append(got, v)
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseField() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSpecError(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@every",
+		"@every 5x",
+		"@every 500ms",
+		"@yearly",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := ParseSpec(spec); err == nil {
+				t.Errorf("ParseSpec(%q) error = nil, want error", spec)
+			}
+		})
+	}
+}
+
+func TestSpecNext(t *testing.T) {
+	loc := time.UTC
+	date := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, loc)
+	}
+
+	// 2024-01-01 为周一
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{name: "every minute", spec: "* * * * *",
+			from: date(2024, 1, 1, 10, 0, 30), want: date(2024, 1, 1, 10, 1, 0)},
+		{name: "on the minute is exclusive", spec: "* * * * *",
+			from: date(2024, 1, 1, 10, 0, 0), want: date(2024, 1, 1, 10, 1, 0)},
+		{name: "minute step", spec: "*/15 * * * *",
+			from: date(2024, 1, 1, 10, 16, 0), want: date(2024, 1, 1, 10, 30, 0)},
+		{name: "minute step wraps hour", spec: "*/15 * * * *",
+			from: date(2024, 1, 1, 10, 45, 0), want: date(2024, 1, 1, 11, 0, 0)},
+		{name: "range step", spec: "0 9-17/4 * * *",
+			from: date(2024, 1, 1, 13, 0, 0), want: date(2024, 1, 1, 17, 0, 0)},
+		{name: "range step wraps day", spec: "0 9-17/4 * * *",
+			from: date(2024, 1, 1, 17, 0, 0), want: date(2024, 1, 2, 9, 0, 0)},
+		{name: "start step", spec: "50/5 * * * *",
+			from: date(2024, 1, 1, 10, 56, 0), want: date(2024, 1, 1, 11, 50, 0)},
+		{name: "hourly", spec: "@hourly",
+			from: date(2024, 1, 1, 10, 59, 59), want: date(2024, 1, 1, 11, 0, 0)},
+		{name: "daily wraps year", spec: "@daily",
+			from: date(2024, 12, 31, 0, 0, 0), want: date(2025, 1, 1, 0, 0, 0)},
+		{name: "weekly", spec: "@weekly",
+			from: date(2024, 1, 1, 0, 0, 0), want: date(2024, 1, 7, 0, 0, 0)},
+		{name: "monthly", spec: "@monthly",
+			from: date(2024, 1, 31, 23, 59, 0), want: date(2024, 2, 1, 0, 0, 0)},
+		{name: "dow 7 is sunday", spec: "0 0 * * 7",
+			from: date(2024, 1, 1, 0, 0, 0), want: date(2024, 1, 7, 0, 0, 0)},
+		{name: "dow 0 is sunday", spec: "0 0 * * 0",
+			from: date(2024, 1, 1, 0, 0, 0), want: date(2024, 1, 7, 0, 0, 0)},
+		{name: "dow range with 7", spec: "0 0 * * 6-7",
+			from: date(2024, 1, 6, 12, 0, 0), want: date(2024, 1, 7, 0, 0, 0)},
+		{name: "dom and dow either matches by dow", spec: "0 0 15 * 5",
+			from: date(2024, 1, 1, 0, 0, 0), want: date(2024, 1, 5, 0, 0, 0)},
+		{name: "dom and dow either matches by dom", spec: "0 0 15 * 5",
+			from: date(2024, 1, 12, 0, 0, 0), want: date(2024, 1, 15, 0, 0, 0)},
+		{name: "dom with dow star", spec: "0 0 15 * *",
+			from: date(2024, 1, 1, 0, 0, 0), want: date(2024, 1, 15, 0, 0, 0)},
+		{name: "dow with dom star", spec: "0 0 * * 5",
+			from: date(2024, 1, 12, 0, 0, 0), want: date(2024, 1, 19, 0, 0, 0)},
+		{name: "leap day", spec: "0 0 29 2 *",
+			from: date(2024, 3, 1, 0, 0, 0), want: date(2028, 2, 29, 0, 0, 0)},
+		{name: "never matches", spec: "0 0 30 2 *",
+			from: date(2024, 1, 1, 0, 0, 0), want: time.Time{}},
+		{name: "every aligns to interval", spec: "@every 5m",
+			from: date(2024, 1, 1, 10, 7, 12), want: date(2024, 1, 1, 10, 10, 0)},
+		{name: "every on boundary is exclusive", spec: "@every 5m",
+			from: date(2024, 1, 1, 10, 10, 0), want: date(2024, 1, 1, 10, 15, 0)},
+		{name: "every hours aligns to zero time", spec: "@every 7h",
+			from: date(2024, 1, 1, 0, 0, 0), want: date(2024, 1, 1, 7, 0, 0)},
+		{name: "every hours same slot", spec: "@every 7h",
+			from: date(2024, 1, 1, 6, 59, 59), want: date(2024, 1, 1, 7, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := ParseSpec(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSpec(%q) error = %v", tt.spec, err)
+			}
+
+			if got := spec.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
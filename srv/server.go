@@ -46,6 +46,7 @@ type Server struct {
 	failedServices sync.Map
 	signalCh       chan os.Signal
 	closeOnce      sync.Once
+	closeHooks     []func()
 }
 
 // NewServer 新建服务
@@ -106,9 +107,19 @@ func (s *Server) Close() {
 
 		// wait all service close
 		wg.Wait()
+
+		// 所有请求处理完成之后再执行，比如消费完剩余的异步任务
+		for _, hook := range s.closeHooks {
+			hook()
+		}
 	})
 }
 
+// AddCloseHook 注册服务关闭时的回调，在所有 service 关闭之后按注册顺序执行
+func (s *Server) AddCloseHook(hook func()) {
+	s.closeHooks = append(s.closeHooks, hook)
+}
+
 // addService adds a service to server.
 func (s *Server) addService(serviceName string, service Service) {
 	if s.services == nil {
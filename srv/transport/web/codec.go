@@ -152,6 +152,7 @@ func (sc *ServerCodec) setReqHeader(fc *frameCodec, msg *cc.Msg) error {
 	}
 
 	reqHeader.Ip = util.GetIpFromAddr(msg.RemoteAddr())
+	reqHeader.UserAgent = fc.Request.Header.Get("User-Agent")
 
 	respHeader := head.WebRespHeader{
 		Version:   reqHeader.Version,
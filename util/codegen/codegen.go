@@ -0,0 +1,167 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codegen 根据表字段生成各语言的结构体代码
+package codegen
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/model/schema"
+)
+
+// 支持的语言
+const (
+	LangGo         = "go"
+	LangJava       = "java"
+	LangTypeScript = "typescript"
+	LangPython     = "python"
+	LangCPP        = "c++"
+	LangPHP        = "php"
+)
+
+// Languages 所有支持的语言
+var Languages = []string{LangGo, LangJava, LangTypeScript, LangPython, LangCPP, LangPHP}
+
+// Generate 根据表名和字段生成所有语言的结构体
+func Generate(tableName string, fields []*pb.TableField) map[string]*pb.LangStruct {
+	ret := make(map[string]*pb.LangStruct, len(Languages))
+
+	if len(fields) == 0 {
+		return ret
+	}
+
+	for _, lang := range Languages {
+		ret[lang] = &pb.LangStruct{
+			Language: lang,
+			Struct:   GenerateLang(lang, tableName, fields),
+		}
+	}
+
+	return ret
+}
+
+// GenerateLang 生成指定语言的结构体，不支持的语言返回空
+func GenerateLang(lang, tableName string, fields []*pb.TableField) string {
+	structName := StructName(tableName)
+
+	switch lang {
+	case LangGo:
+		return genGo(structName, fields)
+	case LangJava:
+		return genJava(structName, fields)
+	case LangTypeScript:
+		return genTypeScript(structName, fields)
+	case LangPython:
+		return genPython(structName, fields)
+	case LangCPP:
+		return genCPP(structName, fields)
+	case LangPHP:
+		return genPHP(structName, fields)
+	default:
+		return ""
+	}
+}
+
+// StructName 表名转结构体名，例如 tbl_user 转为 TblUser，分表通配符会被去掉
+func StructName(tableName string) string {
+	tableName = strings.TrimRight(tableName, "*_")
+	if i := strings.LastIndex(tableName, "."); i != -1 { // schema.table
+		tableName = tableName[i+1:]
+	}
+
+	name := UpperCamel(tableName)
+	if name == "" {
+		return "Table"
+	}
+	return name
+}
+
+// UpperCamel 转大驼峰，例如 last_login_time 转为 LastLoginTime
+func UpperCamel(s string) string {
+	var b strings.Builder
+
+	for _, word := range splitWords(s) {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	name := b.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "F" + name
+	}
+
+	return name
+}
+
+// LowerCamel 转小驼峰，例如 last_login_time 转为 lastLoginTime
+func LowerCamel(s string) string {
+	name := UpperCamel(s)
+	if name == "" {
+		return name
+	}
+
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// SnakeName 转为合法的下划线标识符，例如 es 子字段 user.name 转为 user_name
+func SnakeName(s string) string {
+	name := strings.Join(splitWords(s), "_")
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "f_" + name
+	}
+	return name
+}
+
+///////////////////////////////// function /////////////////////////////////////////
+
+// splitWords 按非字母数字字符切分单词
+func splitWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// comment 注释只保留一行
+func comment(field *pb.TableField) string {
+	return strings.Join(strings.Fields(field.Comment), " ")
+}
+
+// isTime 是否日期时间类型
+func isTime(typ int8) bool {
+	return typ == schema.FieldTypeDate || typ == schema.FieldTypeDatetime
+}
+
+// alignComments 将同一段代码中的行尾注释对齐
+func alignComments(lines []string, sep string) []string {
+	width := 0
+	for _, line := range lines {
+		if i := strings.Index(line, sep); i > width {
+			width = i
+		}
+	}
+
+	for k, line := range lines {
+		if i := strings.Index(line, sep); i != -1 {
+			lines[k] = line[:i] + strings.Repeat(" ", width-i) + line[i:]
+		}
+	}
+
+	return lines
+}
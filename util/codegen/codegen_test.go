@@ -0,0 +1,122 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package codegen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/model/schema"
+)
+
+var update = flag.Bool("update", false, "更新 testdata 中的 golden 文件")
+
+// goldenFiles 语言对应的 golden 文件
+var goldenFiles = map[string]string{
+	LangGo:         "go.golden",
+	LangJava:       "java.golden",
+	LangTypeScript: "typescript.golden",
+	LangPython:     "python.golden",
+	LangCPP:        "cpp.golden",
+	LangPHP:        "php.golden",
+}
+
+// testFields 覆盖全部字段类型，以及需要转换的字段名、多行注释与未知类型
+var testFields = []*pb.TableField{
+	{Field: "id", Type: schema.FieldTypeUint64, IsPrimary: true, Comment: "主键"},
+	{Field: "is_deleted", Type: schema.FieldTypeBool, Comment: "是否删除"},
+	{Field: "user_name", Type: schema.FieldTypeString, Comment: "用户名\n  多行注释"},
+	{Field: "age", Type: schema.FieldTypeInt},
+	{Field: "level", Type: schema.FieldTypeInt8},
+	{Field: "score", Type: schema.FieldTypeInt16},
+	{Field: "balance", Type: schema.FieldTypeInt32},
+	{Field: "total", Type: schema.FieldTypeInt64},
+	{Field: "counter", Type: schema.FieldTypeUint},
+	{Field: "flag", Type: schema.FieldTypeUint8},
+	{Field: "port", Type: schema.FieldTypeUint16},
+	{Field: "ip", Type: schema.FieldTypeUint32},
+	{Field: "ratio", Type: schema.FieldTypeFloat},
+	{Field: "amount", Type: schema.FieldTypeFloat64},
+	{Field: "avatar", Type: schema.FieldTypeBytes},
+	{Field: "gender", Type: schema.FieldTypeEnum, More: "male,female"},
+	{Field: "extra", Type: schema.FieldTypeJSON},
+	{Field: "birthday", Type: schema.FieldTypeDate},
+	{Field: "last_login_time", Type: schema.FieldTypeDatetime, Comment: "最后登录时间"},
+	{Field: "profile.nick-name", Type: schema.FieldTypeString, Comment: "es 子字段"},
+	{Field: "2fa", Type: schema.FieldTypeBool, Comment: "数字开头"},
+	{Field: "unknown", Type: 99, Comment: "未知类型按字符串处理"},
+}
+
+func TestGenerateGolden(t *testing.T) {
+	structs := Generate("db.tbl_user_*", testFields)
+
+	if len(structs) != len(Languages) {
+		t.Fatalf("generate %d languages, want %d", len(structs), len(Languages))
+	}
+
+	for _, lang := range Languages {
+		t.Run(lang, func(t *testing.T) {
+			got := structs[lang].Struct
+			golden := filepath.Join("testdata", goldenFiles[lang])
+
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden file error: %v, run go test -update to create it", err)
+			}
+
+			if got != string(want) {
+				t.Errorf("%s struct mismatch, run go test -update and review the diff\ngot:\n%s\nwant:\n%s",
+					lang, got, want)
+			}
+		})
+	}
+}
+
+func TestGenerateEmpty(t *testing.T) {
+	if structs := Generate("tbl_user", nil); len(structs) != 0 {
+		t.Errorf("generate %d languages for empty fields, want 0", len(structs))
+	}
+
+	if s := GenerateLang("rust", "tbl_user", testFields); s != "" {
+		t.Errorf("generate unsupported language = %q, want empty", s)
+	}
+}
+
+func TestStructName(t *testing.T) {
+	tests := []struct {
+		table string
+		want  string
+	}{
+		{"tbl_user", "TblUser"},
+		{"tbl_user_*", "TblUser"},
+		{"public.tbl_order", "TblOrder"},
+		{"2021_log", "F2021Log"},
+		{"*", "Table"},
+	}
+
+	for _, tt := range tests {
+		if got := StructName(tt.table); got != tt.want {
+			t.Errorf("StructName(%q) = %q, want %q", tt.table, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/model/schema"
+)
+
+// cppTypes 字段类型对应的 c++ 类型及默认值，json、时间使用字符串
+var cppTypes = map[int8][2]string{
+	schema.FieldTypeBool:     {"bool", "false"},
+	schema.FieldTypeString:   {"std::string", ""},
+	schema.FieldTypeInt:      {"int32_t", "0"},
+	schema.FieldTypeInt8:     {"int8_t", "0"},
+	schema.FieldTypeInt16:    {"int16_t", "0"},
+	schema.FieldTypeInt32:    {"int32_t", "0"},
+	schema.FieldTypeInt64:    {"int64_t", "0"},
+	schema.FieldTypeUint:     {"uint32_t", "0"},
+	schema.FieldTypeUint8:    {"uint8_t", "0"},
+	schema.FieldTypeUint16:   {"uint16_t", "0"},
+	schema.FieldTypeUint32:   {"uint32_t", "0"},
+	schema.FieldTypeUint64:   {"uint64_t", "0"},
+	schema.FieldTypeFloat:    {"float", "0"},
+	schema.FieldTypeFloat64:  {"double", "0"},
+	schema.FieldTypeBytes:    {"std::vector<uint8_t>", ""},
+	schema.FieldTypeEnum:     {"std::string", ""},
+	schema.FieldTypeJSON:     {"std::string", ""},
+	schema.FieldTypeDate:     {"std::string", ""},
+	schema.FieldTypeDatetime: {"std::string", ""},
+}
+
+// genCPP 生成 c++ 结构体
+func genCPP(structName string, fields []*pb.TableField) string {
+	hasBytes := false
+
+	lines := []string{}
+	for _, field := range fields {
+		typ, ok := cppTypes[field.Type]
+		if !ok {
+			typ = cppTypes[schema.FieldTypeString]
+		}
+
+		if field.Type == schema.FieldTypeBytes {
+			hasBytes = true
+		}
+
+		line := fmt.Sprintf("    %s %s", typ[0], SnakeName(field.Field))
+		if typ[1] != "" {
+			line += " = " + typ[1]
+		}
+		line += ";"
+
+		if c := comment(field); c != "" {
+			line += " // " + c
+		}
+
+		lines = append(lines, line)
+	}
+
+	var b strings.Builder
+
+	b.WriteString("#include <cstdint>\n#include <string>\n")
+	if hasBytes {
+		b.WriteString("#include <vector>\n")
+	}
+
+	b.WriteString(fmt.Sprintf("\nstruct %s {\n", structName))
+	b.WriteString(strings.Join(alignComments(lines, " // "), "\n"))
+	b.WriteString("\n};")
+
+	return b.String()
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/model/schema"
+)
+
+// goTypes 字段类型对应的 go 类型及 orm tag 类型
+var goTypes = map[int8][2]string{
+	schema.FieldTypeBool:     {"bool", "bool"},
+	schema.FieldTypeString:   {"string", "string"},
+	schema.FieldTypeInt:      {"int", "int"},
+	schema.FieldTypeInt8:     {"int8", "int8"},
+	schema.FieldTypeInt16:    {"int16", "int16"},
+	schema.FieldTypeInt32:    {"int32", "int32"},
+	schema.FieldTypeInt64:    {"int64", "int64"},
+	schema.FieldTypeUint:     {"uint", "uint"},
+	schema.FieldTypeUint8:    {"uint8", "uint8"},
+	schema.FieldTypeUint16:   {"uint16", "uint16"},
+	schema.FieldTypeUint32:   {"uint32", "uint32"},
+	schema.FieldTypeUint64:   {"uint64", "uint64"},
+	schema.FieldTypeFloat:    {"float32", "float"},
+	schema.FieldTypeFloat64:  {"float64", "float64"},
+	schema.FieldTypeBytes:    {"[]byte", "bytes"},
+	schema.FieldTypeEnum:     {"string", "enum"},
+	schema.FieldTypeJSON:     {"map[string]interface{}", "json"},
+	schema.FieldTypeDate:     {"time.Time", "date"},
+	schema.FieldTypeDatetime: {"time.Time", "datetime"},
+}
+
+// genGo 生成 go 结构体，tag 规则同 model/table/obj.go
+func genGo(structName string, fields []*pb.TableField) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("type %s struct {\n", structName))
+
+	for _, field := range fields {
+		typ, ok := goTypes[field.Type]
+		if !ok {
+			typ = goTypes[schema.FieldTypeString]
+		}
+
+		jsonTag := field.Field + ",omitempty"
+		if isTime(field.Type) { // 时间类型 omitempty 不生效
+			jsonTag = field.Field
+		}
+
+		b.WriteString(fmt.Sprintf("\t%s %s `orm:\"%s,%s,omitempty\" json:\"%s\"`",
+			UpperCamel(field.Field), typ[0], field.Field, typ[1], jsonTag))
+
+		if c := comment(field); c != "" {
+			b.WriteString(" // " + c)
+		}
+
+		b.WriteString("\n")
+	}
+
+	b.WriteString("}")
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return b.String()
+	}
+
+	return string(src)
+}
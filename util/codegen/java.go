@@ -0,0 +1,80 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/model/schema"
+)
+
+// javaTypes 字段类型对应的 java 类型，统一使用包装类型以支持 null
+var javaTypes = map[int8]string{
+	schema.FieldTypeBool:     "Boolean",
+	schema.FieldTypeString:   "String",
+	schema.FieldTypeInt:      "Integer",
+	schema.FieldTypeInt8:     "Byte",
+	schema.FieldTypeInt16:    "Short",
+	schema.FieldTypeInt32:    "Integer",
+	schema.FieldTypeInt64:    "Long",
+	schema.FieldTypeUint:     "Long",
+	schema.FieldTypeUint8:    "Short",
+	schema.FieldTypeUint16:   "Integer",
+	schema.FieldTypeUint32:   "Long",
+	schema.FieldTypeUint64:   "java.math.BigInteger",
+	schema.FieldTypeFloat:    "Float",
+	schema.FieldTypeFloat64:  "Double",
+	schema.FieldTypeBytes:    "byte[]",
+	schema.FieldTypeEnum:     "String",
+	schema.FieldTypeJSON:     "java.util.Map<String, Object>",
+	schema.FieldTypeDate:     "java.time.LocalDate",
+	schema.FieldTypeDatetime: "java.time.LocalDateTime",
+}
+
+// genJava 生成 java 类，包含 getter/setter
+func genJava(structName string, fields []*pb.TableField) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("public class %s {\n", structName))
+
+	for _, field := range fields {
+		if c := comment(field); c != "" {
+			b.WriteString(fmt.Sprintf("    /** %s */\n", c))
+		}
+		b.WriteString(fmt.Sprintf("    private %s %s;\n", javaType(field.Type), LowerCamel(field.Field)))
+	}
+
+	for _, field := range fields {
+		typ, name := javaType(field.Type), LowerCamel(field.Field)
+		upper := UpperCamel(field.Field)
+
+		b.WriteString(fmt.Sprintf("\n    public %s get%s() {\n        return %s;\n    }\n", typ, upper, name))
+		b.WriteString(fmt.Sprintf("\n    public void set%s(%s %s) {\n        this.%s = %s;\n    }\n",
+			upper, typ, name, name, name))
+	}
+
+	b.WriteString("}")
+
+	return b.String()
+}
+
+func javaType(typ int8) string {
+	if t, ok := javaTypes[typ]; ok {
+		return t
+	}
+	return javaTypes[schema.FieldTypeString]
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/model/schema"
+)
+
+// phpTypes 字段类型对应的 php 类型（php 7.4+ typed properties）及默认值
+var phpTypes = map[int8][2]string{
+	schema.FieldTypeBool:     {"bool", "false"},
+	schema.FieldTypeString:   {"string", "''"},
+	schema.FieldTypeInt:      {"int", "0"},
+	schema.FieldTypeInt8:     {"int", "0"},
+	schema.FieldTypeInt16:    {"int", "0"},
+	schema.FieldTypeInt32:    {"int", "0"},
+	schema.FieldTypeInt64:    {"int", "0"},
+	schema.FieldTypeUint:     {"int", "0"},
+	schema.FieldTypeUint8:    {"int", "0"},
+	schema.FieldTypeUint16:   {"int", "0"},
+	schema.FieldTypeUint32:   {"int", "0"},
+	schema.FieldTypeUint64:   {"int", "0"},
+	schema.FieldTypeFloat:    {"float", "0.0"},
+	schema.FieldTypeFloat64:  {"float", "0.0"},
+	schema.FieldTypeBytes:    {"string", "''"},
+	schema.FieldTypeEnum:     {"string", "''"},
+	schema.FieldTypeJSON:     {"array", "[]"},
+	schema.FieldTypeDate:     {"string", "''"},
+	schema.FieldTypeDatetime: {"string", "''"},
+}
+
+// genPHP 生成 php 类
+func genPHP(structName string, fields []*pb.TableField) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("<?php\n\nclass %s\n{\n", structName))
+
+	for k, field := range fields {
+		typ, ok := phpTypes[field.Type]
+		if !ok {
+			typ = phpTypes[schema.FieldTypeString]
+		}
+
+		if field.Empty {
+			typ = [2]string{"?" + typ[0], "null"}
+		}
+
+		if k > 0 {
+			b.WriteString("\n")
+		}
+
+		if c := comment(field); c != "" {
+			b.WriteString(fmt.Sprintf("    /** @var %s %s */\n", typ[0], c))
+		}
+		b.WriteString(fmt.Sprintf("    public %s $%s = %s;\n", typ[0], SnakeName(field.Field), typ[1]))
+	}
+
+	b.WriteString("}")
+
+	return b.String()
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/model/schema"
+)
+
+// pyTypes 字段类型对应的 python 类型及默认值
+var pyTypes = map[int8][2]string{
+	schema.FieldTypeBool:     {"bool", "False"},
+	schema.FieldTypeString:   {"str", `""`},
+	schema.FieldTypeInt:      {"int", "0"},
+	schema.FieldTypeInt8:     {"int", "0"},
+	schema.FieldTypeInt16:    {"int", "0"},
+	schema.FieldTypeInt32:    {"int", "0"},
+	schema.FieldTypeInt64:    {"int", "0"},
+	schema.FieldTypeUint:     {"int", "0"},
+	schema.FieldTypeUint8:    {"int", "0"},
+	schema.FieldTypeUint16:   {"int", "0"},
+	schema.FieldTypeUint32:   {"int", "0"},
+	schema.FieldTypeUint64:   {"int", "0"},
+	schema.FieldTypeFloat:    {"float", "0.0"},
+	schema.FieldTypeFloat64:  {"float", "0.0"},
+	schema.FieldTypeBytes:    {"bytes", `b""`},
+	schema.FieldTypeEnum:     {"str", `""`},
+	schema.FieldTypeJSON:     {"Dict[str, Any]", "field(default_factory=dict)"},
+	schema.FieldTypeDate:     {"Optional[datetime.date]", "None"},
+	schema.FieldTypeDatetime: {"Optional[datetime.datetime]", "None"},
+}
+
+// genPython 生成 python dataclass
+func genPython(structName string, fields []*pb.TableField) string {
+	hasTime, hasJSON, hasOptional := false, false, false
+
+	lines := []string{}
+	for _, field := range fields {
+		typ, ok := pyTypes[field.Type]
+		if !ok {
+			typ = pyTypes[schema.FieldTypeString]
+		}
+
+		switch {
+		case isTime(field.Type):
+			hasTime, hasOptional = true, true
+		case field.Type == schema.FieldTypeJSON:
+			hasJSON = true
+		case field.Empty:
+			typ = [2]string{"Optional[" + typ[0] + "]", "None"}
+			hasOptional = true
+		}
+
+		line := fmt.Sprintf("    %s: %s = %s", SnakeName(field.Field), typ[0], typ[1])
+		if c := comment(field); c != "" {
+			line += "  # " + c
+		}
+
+		lines = append(lines, line)
+	}
+
+	var b strings.Builder
+
+	if hasTime {
+		b.WriteString("import datetime\n")
+	}
+
+	if hasJSON {
+		b.WriteString("from dataclasses import dataclass, field\n")
+	} else {
+		b.WriteString("from dataclasses import dataclass\n")
+	}
+
+	typing := []string{}
+	if hasJSON {
+		typing = append(typing, "Any", "Dict")
+	}
+	if hasOptional {
+		typing = append(typing, "Optional")
+	}
+	if len(typing) > 0 {
+		b.WriteString("from typing import " + strings.Join(typing, ", ") + "\n")
+	}
+
+	b.WriteString(fmt.Sprintf("\n\n@dataclass\nclass %s:\n", structName))
+	b.WriteString(strings.Join(alignComments(lines, "  # "), "\n"))
+
+	return b.String()
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/horm-database/manage/api/pb"
+	"github.com/horm-database/manage/model/schema"
+)
+
+// tsTypes 字段类型对应的 typescript 类型，bytes 为 base64 字符串，时间为字符串
+var tsTypes = map[int8]string{
+	schema.FieldTypeBool:     "boolean",
+	schema.FieldTypeString:   "string",
+	schema.FieldTypeInt:      "number",
+	schema.FieldTypeInt8:     "number",
+	schema.FieldTypeInt16:    "number",
+	schema.FieldTypeInt32:    "number",
+	schema.FieldTypeInt64:    "number",
+	schema.FieldTypeUint:     "number",
+	schema.FieldTypeUint8:    "number",
+	schema.FieldTypeUint16:   "number",
+	schema.FieldTypeUint32:   "number",
+	schema.FieldTypeUint64:   "number",
+	schema.FieldTypeFloat:    "number",
+	schema.FieldTypeFloat64:  "number",
+	schema.FieldTypeBytes:    "string",
+	schema.FieldTypeEnum:     "string",
+	schema.FieldTypeJSON:     "Record<string, unknown>",
+	schema.FieldTypeDate:     "string",
+	schema.FieldTypeDatetime: "string",
+}
+
+// genTypeScript 生成 typescript interface，字段名同数据库列名（即 json key）
+func genTypeScript(structName string, fields []*pb.TableField) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("export interface %s {\n", structName))
+
+	for _, field := range fields {
+		typ, ok := tsTypes[field.Type]
+		if !ok {
+			typ = tsTypes[schema.FieldTypeString]
+		}
+
+		name := field.Field
+		if SnakeName(name) != name { // 包含特殊字符时需要引号
+			name = fmt.Sprintf("'%s'", name)
+		}
+
+		optional := ""
+		if field.Empty {
+			optional = "?"
+		}
+
+		if c := comment(field); c != "" {
+			b.WriteString(fmt.Sprintf("  /** %s */\n", c))
+		}
+		b.WriteString(fmt.Sprintf("  %s%s: %s;\n", name, optional, typ))
+	}
+
+	b.WriteString("}")
+
+	return b.String()
+}
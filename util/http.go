@@ -0,0 +1,138 @@
+// Copyright (c) 2024 The horm-database Authors. All rights reserved.
+// This file Author:  CaoHao <18500482693@163.com> .
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/horm-database/common/json"
+)
+
+// PostJSON 以 json 格式 POST 请求 url，返回非 2xx 状态码视为失败
+// headers 请求头
+// timeout 超时时间
+func PostJSON(ctx context.Context, url string, body interface{},
+	headers map[string]string, timeout time.Duration) error {
+	buf, err := json.Api.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, &http.Client{Timeout: timeout}, url, buf, headers)
+}
+
+// PostPublicJSON 同 PostJSON，但只允许连接公网地址，用于请求用户填写的 url。
+// 实际建立连接时再次校验地址，防止 DNS 重绑定及跳转到内网地址。
+func PostPublicJSON(ctx context.Context, url string, body interface{},
+	headers map[string]string, timeout time.Duration) error {
+	buf, err := json.Api.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: timeout, Control: dialPublicOnly}
+
+	client := http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{ // 不走代理，保证校验的是实际连接的地址
+			DialContext:       dialer.DialContext,
+			DisableKeepAlives: true,
+		},
+	}
+
+	return postJSON(ctx, &client, url, buf, headers)
+}
+
+// CheckPublicURL 校验 url 为 http/https 地址，且主机解析出的地址均为公网地址
+func CheckPublicURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%s is not a http/https url", rawURL)
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("resolve host %s error: %v", u.Hostname(), err)
+	}
+
+	for _, ip := range ips {
+		if !IsPublicIP(ip.IP) {
+			return fmt.Errorf("host %s resolves to non-public address %s", u.Hostname(), ip.IP)
+		}
+	}
+
+	return nil
+}
+
+// IsPublicIP 是否为公网地址，环回、私有、链路本地（含云厂商元数据地址 169.254.169.254）、
+// 运营商级 NAT、未指定及组播地址都不是公网地址
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4[0] != 0 && !(ip4[0] == 100 && ip4[1]&0xc0 == 64) // 0.0.0.0/8、100.64.0.0/10
+	}
+
+	return true
+}
+
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !IsPublicIP(net.ParseIP(host)) {
+		return fmt.Errorf("connect to non-public address %s is not allowed", host)
+	}
+
+	return nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, buf []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(rsp.Body, 512))
+		return fmt.Errorf("post %s return status %d: %s", url, rsp.StatusCode, msg)
+	}
+
+	return nil
+}